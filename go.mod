@@ -2,4 +2,9 @@ module github.com/josephmojoo/pdfgen
 
 go 1.23.4
 
-require github.com/jung-kurt/gofpdf v1.16.2
+require (
+	github.com/boombuler/barcode v1.1.0
+	github.com/jung-kurt/gofpdf v1.16.2
+	golang.org/x/image v0.25.0
+	golang.org/x/net v0.38.0
+)
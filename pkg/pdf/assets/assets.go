@@ -0,0 +1,158 @@
+// Package assets resolves the references templates use for binary assets
+// such as images to their data.
+//
+// A Resolver opens a reference by name. Resolvers are provided for a
+// directory, an fs.FS such as embed.FS, an in-memory map and a plain
+// function, and Chain combines several. Data URIs carry their own data and
+// are decoded with Open before any resolver is asked.
+package assets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Resolver opens the data behind an asset reference. Resolvers return an
+// error wrapping fs.ErrNotExist for references they do not know, so that a
+// Chain can try the next one.
+type Resolver interface {
+	Open(name string) (io.ReadCloser, error)
+}
+
+// ResolverFunc adapts a function, such as a database lookup, to a Resolver
+type ResolverFunc func(name string) (io.ReadCloser, error)
+
+// Open calls f(name)
+func (f ResolverFunc) Open(name string) (io.ReadCloser, error) {
+	return f(name)
+}
+
+// Open returns the data of a reference. Data URIs are decoded, other
+// references are opened with the resolver, or as local files when the
+// resolver is nil.
+func Open(resolver Resolver, name string) (io.ReadCloser, error) {
+	if IsDataURI(name) {
+		data, _, err := DecodeDataURI(name)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	if resolver == nil {
+		return os.Open(name)
+	}
+	return resolver.Open(name)
+}
+
+// ReadAll returns the data of a reference, as Open does
+func ReadAll(resolver Resolver, name string) ([]byte, error) {
+	r, err := Open(resolver, name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// IsDataURI reports whether name is a data URI
+func IsDataURI(name string) bool {
+	return len(name) >= 5 && strings.EqualFold(name[:5], "data:")
+}
+
+// DecodeDataURI returns the data and media type of a data URI such as
+// "data:image/png;base64,iVBORw0KGgo...". Base64 data may contain
+// whitespace; other data is percent-decoded.
+func DecodeDataURI(uri string) ([]byte, string, error) {
+	if !IsDataURI(uri) {
+		return nil, "", fmt.Errorf("not a data URI")
+	}
+	header, payload, ok := strings.Cut(uri[5:], ",")
+	if !ok {
+		return nil, "", fmt.Errorf("invalid data URI: missing comma")
+	}
+
+	mediaType, encoded := header, false
+	if before, found := strings.CutSuffix(header, ";base64"); found {
+		mediaType, encoded = before, true
+	}
+	if mediaType == "" {
+		mediaType = "text/plain;charset=US-ASCII"
+	}
+
+	if !encoded {
+		data, err := url.PathUnescape(payload)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid data URI: %w", err)
+		}
+		return []byte(data), mediaType, nil
+	}
+	payload = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(" \t\r\n", r) {
+			return -1
+		}
+		return r
+	}, payload)
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		// Some encoders leave out the padding
+		if data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "=")); err != nil {
+			return nil, "", fmt.Errorf("invalid data URI: %w", err)
+		}
+	}
+	return data, mediaType, nil
+}
+
+// Dir resolves slash-separated names relative to a directory. Names cannot
+// reach outside it.
+func Dir(root string) Resolver {
+	return FS(os.DirFS(root))
+}
+
+// FS resolves names in a file system such as embed.FS. A leading slash is
+// ignored.
+func FS(fsys fs.FS) Resolver {
+	return ResolverFunc(func(name string) (io.ReadCloser, error) {
+		return fsys.Open(strings.TrimPrefix(name, "/"))
+	})
+}
+
+// Map resolves names to in-memory data, such as images loaded from a
+// database before rendering
+type Map map[string][]byte
+
+// Open returns a reader of the data stored under name
+func (m Map) Open(name string) (io.ReadCloser, error) {
+	data, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Chain tries each resolver in turn, moving on while they report that the
+// name does not exist
+func Chain(resolvers ...Resolver) Resolver {
+	return ResolverFunc(func(name string) (io.ReadCloser, error) {
+		err := error(&fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist})
+		for _, resolver := range resolvers {
+			var r io.ReadCloser
+			if r, err = resolver.Open(name); err == nil || !isNotExist(err) {
+				return r, err
+			}
+		}
+		return nil, err
+	})
+}
+
+// isNotExist reports whether err means a name is unknown, including the
+// invalid names an fs.FS rejects
+func isNotExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid)
+}
@@ -0,0 +1,105 @@
+package assets
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDecodeDataURI(t *testing.T) {
+	tests := []struct {
+		name      string
+		uri       string
+		want      string
+		wantType  string
+		wantError bool
+	}{
+		{name: "base64", uri: "data:image/png;base64,aGVsbG8=", want: "hello", wantType: "image/png"},
+		{name: "base64 without padding", uri: "data:image/png;base64,aGVsbG8", want: "hello", wantType: "image/png"},
+		{name: "base64 with line breaks", uri: "data:image/png;base64,aGVs\nbG8=", want: "hello", wantType: "image/png"},
+		{name: "percent encoded", uri: "data:,hello%20world", want: "hello world", wantType: "text/plain;charset=US-ASCII"},
+		{name: "upper case scheme", uri: "DATA:text/plain;base64,aGVsbG8=", want: "hello", wantType: "text/plain"},
+		{name: "missing comma", uri: "data:image/png;base64", wantError: true},
+		{name: "invalid base64", uri: "data:image/png;base64,!!!", wantError: true},
+		{name: "not a data URI", uri: "logo.png", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, mediaType, err := DecodeDataURI(tt.uri)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("DecodeDataURI() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeDataURI() error = %v", err)
+			}
+			if string(data) != tt.want || mediaType != tt.wantType {
+				t.Errorf("DecodeDataURI() = %q, %q, want %q, %q", data, mediaType, tt.want, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestResolvers(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), []byte("from dir"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{"images/seal.png": {Data: []byte("from fs")}}
+	memory := Map{"logo:42": []byte("from map")}
+	lookups := 0
+	database := ResolverFunc(func(name string) (io.ReadCloser, error) {
+		lookups++
+		return nil, errors.New("database unavailable")
+	})
+
+	tests := []struct {
+		name         string
+		resolver     Resolver
+		ref          string
+		want         string
+		wantNotExist bool
+		wantError    bool
+	}{
+		{name: "dir", resolver: Dir(dir), ref: "logo.png", want: "from dir"},
+		{name: "dir stays inside its root", resolver: Dir(dir), ref: "../logo.png", wantError: true},
+		{name: "fs", resolver: FS(fsys), ref: "/images/seal.png", want: "from fs"},
+		{name: "map", resolver: memory, ref: "logo:42", want: "from map"},
+		{name: "map missing", resolver: memory, ref: "logo:7", wantNotExist: true},
+		{name: "data URI", resolver: memory, ref: "data:,inline", want: "inline"},
+		{name: "nil reads local files", ref: filepath.Join(dir, "logo.png"), want: "from dir"},
+		{name: "chain falls through", resolver: Chain(memory, FS(fsys), Dir(dir)), ref: "logo.png", want: "from dir"},
+		{name: "chain of missing", resolver: Chain(memory, FS(fsys)), ref: "nothing.png", wantNotExist: true},
+		{name: "chain stops at errors", resolver: Chain(database, memory), ref: "logo:42", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ReadAll(tt.resolver, tt.ref)
+			switch {
+			case tt.wantNotExist:
+				if !errors.Is(err, fs.ErrNotExist) {
+					t.Errorf("ReadAll() error = %v, want fs.ErrNotExist", err)
+				}
+			case tt.wantError:
+				if err == nil {
+					t.Errorf("ReadAll() = %q, want error", data)
+				}
+			case err != nil:
+				t.Errorf("ReadAll() error = %v", err)
+			case string(data) != tt.want:
+				t.Errorf("ReadAll() = %q, want %q", data, tt.want)
+			}
+		})
+	}
+	if lookups != 1 {
+		t.Errorf("database resolver called %d times, want 1", lookups)
+	}
+}
@@ -0,0 +1,171 @@
+package fonts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// runeRange is an inclusive range of characters
+type runeRange struct {
+	lo, hi rune
+}
+
+// coverage is the sorted set of characters a font has glyphs for
+type coverage struct {
+	ranges []runeRange
+}
+
+func (c *coverage) has(r rune) bool {
+	if c == nil {
+		return false
+	}
+	i := sort.Search(len(c.ranges), func(i int) bool { return c.ranges[i].hi >= r })
+	return i < len(c.ranges) && c.ranges[i].lo <= r
+}
+
+// add extends the coverage with the characters from lo to hi, which must
+// not start below any range added before
+func (c *coverage) add(lo, hi rune) {
+	if n := len(c.ranges); n > 0 && c.ranges[n-1].hi+1 >= lo {
+		if hi > c.ranges[n-1].hi {
+			c.ranges[n-1].hi = hi
+		}
+		return
+	}
+	c.ranges = append(c.ranges, runeRange{lo, hi})
+}
+
+// parseCmap reads the characters mapped by the font's Unicode cmap subtable.
+// Format 12 subtables, which reach beyond the Basic Multilingual Plane, are
+// preferred over format 4.
+func parseCmap(data []byte) (*coverage, error) {
+	cmap, err := findTable(data, "cmap")
+	if err != nil {
+		return nil, err
+	}
+	if len(cmap) < 4 {
+		return nil, fmt.Errorf("cmap table is truncated")
+	}
+
+	var format4, format12 []byte
+	count := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < count; i++ {
+		record := 4 + i*8
+		if record+8 > len(cmap) {
+			return nil, fmt.Errorf("cmap table is truncated")
+		}
+		platform := binary.BigEndian.Uint16(cmap[record:])
+		encoding := binary.BigEndian.Uint16(cmap[record+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[record+4:]))
+		if offset+2 > len(cmap) {
+			continue
+		}
+
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+		switch binary.BigEndian.Uint16(cmap[offset:]) {
+		case 4:
+			format4 = cmap[offset:]
+		case 12:
+			format12 = cmap[offset:]
+		}
+	}
+
+	switch {
+	case format12 != nil:
+		return parseFormat12(format12)
+	case format4 != nil:
+		return parseFormat4(format4)
+	}
+	return nil, fmt.Errorf("font has no Unicode character map")
+}
+
+// findTable returns the contents of a table from the font's table directory
+func findTable(data []byte, tag string) ([]byte, error) {
+	count := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < count; i++ {
+		record := 12 + i*16
+		if record+16 > len(data) {
+			break
+		}
+		if string(data[record:record+4]) != tag {
+			continue
+		}
+		offset := int(binary.BigEndian.Uint32(data[record+8:]))
+		length := int(binary.BigEndian.Uint32(data[record+12:]))
+		if offset+length > len(data) {
+			return nil, fmt.Errorf("%s table is truncated", tag)
+		}
+		return data[offset : offset+length], nil
+	}
+	return nil, fmt.Errorf("font has no %s table", tag)
+}
+
+func parseFormat4(table []byte) (*coverage, error) {
+	if len(table) < 14 {
+		return nil, fmt.Errorf("cmap subtable is truncated")
+	}
+	segments := int(binary.BigEndian.Uint16(table[6:])) / 2
+	ends := 14
+	starts := ends + segments*2 + 2
+	deltas := starts + segments*2
+	rangeOffsets := deltas + segments*2
+	if rangeOffsets+segments*2 > len(table) {
+		return nil, fmt.Errorf("cmap subtable is truncated")
+	}
+
+	u16 := func(at int) int {
+		if at+2 > len(table) {
+			return 0
+		}
+		return int(binary.BigEndian.Uint16(table[at:]))
+	}
+
+	c := &coverage{}
+	for i := 0; i < segments; i++ {
+		start, end := u16(starts+i*2), u16(ends+i*2)
+		delta, rangeOffset := u16(deltas+i*2), u16(rangeOffsets+i*2)
+		for ch := start; ch <= end && ch != 0xFFFF; ch++ {
+			glyph := (ch + delta) & 0xFFFF
+			if rangeOffset != 0 {
+				// The offset is relative to its own position in the idRangeOffset array
+				glyph = u16(rangeOffsets + i*2 + rangeOffset + (ch-start)*2)
+				if glyph != 0 {
+					glyph = (glyph + delta) & 0xFFFF
+				}
+			}
+			if glyph != 0 {
+				c.add(rune(ch), rune(ch))
+			}
+		}
+	}
+	return c, nil
+}
+
+func parseFormat12(table []byte) (*coverage, error) {
+	if len(table) < 16 {
+		return nil, fmt.Errorf("cmap subtable is truncated")
+	}
+	groups := int(binary.BigEndian.Uint32(table[12:]))
+	if 16+groups*12 > len(table) {
+		return nil, fmt.Errorf("cmap subtable is truncated")
+	}
+
+	c := &coverage{}
+	for i := 0; i < groups; i++ {
+		group := table[16+i*12:]
+		start := rune(binary.BigEndian.Uint32(group))
+		end := rune(binary.BigEndian.Uint32(group[4:]))
+		if binary.BigEndian.Uint32(group[8:]) == 0 {
+			// The first character maps to the missing glyph
+			start++
+		}
+		if start <= end {
+			c.add(start, end)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,229 @@
+package fonts
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// buildFont returns a minimal TrueType file whose only table is a cmap with
+// a format 4 subtable mapping each range to glyphs
+func buildFont(ranges ...[2]uint16) []byte {
+	u16 := func(b []byte, v uint16) []byte { return binary.BigEndian.AppendUint16(b, v) }
+
+	// Segments end with the required 0xFFFF sentinel
+	ranges = append(ranges, [2]uint16{0xFFFF, 0xFFFF})
+	segments := uint16(len(ranges))
+
+	var sub []byte
+	sub = u16(sub, 4)
+	sub = u16(sub, 0) // length, patched below
+	sub = u16(sub, 0)
+	sub = u16(sub, segments*2)
+	sub = u16(sub, 0)
+	sub = u16(sub, 0)
+	sub = u16(sub, 0)
+	for _, r := range ranges {
+		sub = u16(sub, r[1])
+	}
+	sub = u16(sub, 0)
+	for _, r := range ranges {
+		sub = u16(sub, r[0])
+	}
+	glyph := uint16(1)
+	for i, r := range ranges {
+		delta := glyph - r[0]
+		if i == len(ranges)-1 {
+			delta = 1
+		}
+		sub = u16(sub, delta)
+		glyph += r[1] - r[0] + 1
+	}
+	for range ranges {
+		sub = u16(sub, 0)
+	}
+	binary.BigEndian.PutUint16(sub[2:], uint16(len(sub)))
+
+	var cmap []byte
+	cmap = u16(cmap, 0)
+	cmap = u16(cmap, 1)
+	cmap = u16(cmap, 3)
+	cmap = u16(cmap, 1)
+	cmap = binary.BigEndian.AppendUint32(cmap, 12)
+	cmap = append(cmap, sub...)
+
+	var font []byte
+	font = binary.BigEndian.AppendUint32(font, 0x00010000)
+	font = u16(font, 1)
+	font = append(font, make([]byte, 6)...)
+	font = append(font, "cmap"...)
+	font = binary.BigEndian.AppendUint32(font, 0)
+	font = binary.BigEndian.AppendUint32(font, 28)
+	font = binary.BigEndian.AppendUint32(font, uint32(len(cmap)))
+	return append(font, cmap...)
+}
+
+func TestRegistry_Add(t *testing.T) {
+	tests := []struct {
+		name    string
+		family  string
+		style   Style
+		data    []byte
+		wantErr string
+	}{
+		{name: "truetype", family: "Noto", data: buildFont([2]uint16{'A', 'Z'})},
+		{name: "missing family", data: buildFont([2]uint16{'A', 'Z'}), wantErr: "family is required"},
+		{name: "unknown style", family: "Noto", style: "X", data: buildFont([2]uint16{'A', 'Z'}), wantErr: "unknown font style"},
+		{name: "cff outlines", family: "Noto", data: append([]byte("OTTO"), make([]byte, 12)...), wantErr: "CFF outlines"},
+		{name: "collection", family: "Noto", data: append([]byte("ttcf"), make([]byte, 12)...), wantErr: "collections"},
+		{name: "not a font", family: "Noto", data: []byte("definitely not a font"), wantErr: "not a TrueType font"},
+		{name: "no cmap", family: "Noto", data: append([]byte{0, 1, 0, 0}, make([]byte, 8)...), wantErr: "no cmap table"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewRegistry().Add(tt.family, tt.style, tt.data)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Add() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Add() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFont_Has(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Add("Test", Regular, buildFont([2]uint16{'a', 'z'}, [2]uint16{0x0627, 0x064A})); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	font, ok := registry.Lookup("test", Regular)
+	if !ok {
+		t.Fatal("Lookup() found no font")
+	}
+
+	tests := []struct {
+		r    rune
+		want bool
+	}{
+		{r: 'a', want: true},
+		{r: 'z', want: true},
+		{r: 'A', want: false},
+		{r: 'ب', want: true},
+		{r: '中', want: false},
+		{r: 0xFFFF, want: false},
+	}
+	for _, tt := range tests {
+		if got := font.Has(tt.r); got != tt.want {
+			t.Errorf("Has(%q) = %v, want %v", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	fsys := fstest.MapFS{"sans.ttf": {Data: buildFont([2]uint16{'A', 'Z'})}}
+	registry := NewRegistry()
+	for _, style := range []Style{Regular, Bold} {
+		if err := registry.AddFS(fsys, "Sans", style, "sans.ttf"); err != nil {
+			t.Fatalf("AddFS() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		family    string
+		style     Style
+		wantStyle Style
+		wantOK    bool
+	}{
+		{family: "Sans", style: Regular, wantStyle: Regular, wantOK: true},
+		{family: "sans", style: Bold, wantStyle: Bold, wantOK: true},
+		{family: "Sans", style: BoldItalic, wantStyle: Bold, wantOK: true},
+		{family: "Sans", style: Italic, wantStyle: Regular, wantOK: true},
+		{family: "Serif", style: Regular},
+	}
+	for _, tt := range tests {
+		font, ok := registry.Lookup(tt.family, tt.style)
+		if ok != tt.wantOK {
+			t.Fatalf("Lookup(%q, %q) ok = %v, want %v", tt.family, tt.style, ok, tt.wantOK)
+		}
+		if ok && font.Style != tt.wantStyle {
+			t.Errorf("Lookup(%q, %q) style = %q, want %q", tt.family, tt.style, font.Style, tt.wantStyle)
+		}
+	}
+
+	if err := registry.AddFS(fsys, "Sans", Italic, "missing.ttf"); err == nil {
+		t.Error("AddFS() with a missing file succeeded")
+	}
+
+	var empty *Registry
+	if _, ok := empty.Lookup("Sans", Regular); ok {
+		t.Error("Lookup() on a nil registry found a font")
+	}
+}
+
+func TestRegistry_Fallbacks(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Add("Arabic", Regular, buildFont([2]uint16{0x0600, 0x06FF})); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	registry.SetFallbacks("Missing", "Arabic")
+
+	fallbacks := registry.Fallbacks(Bold)
+	if len(fallbacks) != 1 || fallbacks[0].Family != "Arabic" {
+		t.Errorf("Fallbacks() = %v, want the Arabic font only", fallbacks)
+	}
+}
+
+func TestShape(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "latin", in: "Hello", want: "Hello"},
+		{name: "isolated", in: "ب", want: "ﺏ"},
+		// beh initial, yeh final
+		{name: "two letters", in: "بي", want: "ﺑﻲ"},
+		// meem initial, hah medial, meem medial, dal final
+		{name: "word", in: "محمد", want: "ﻣﺤﻤﺪ"},
+		// alef does not join to the following letter
+		{name: "right joining", in: "ابا", want: "ﺍﺑﺎ"},
+		{name: "lam alef", in: "لا", want: "ﻻ"},
+		{name: "words", in: "بي بي", want: "ﺑﻲ ﺑﻲ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Shape(tt.in); got != tt.want {
+				t.Errorf("Shape(%q) = %+q, want %+q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisual(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "latin", in: "Hello world", want: "Hello world"},
+		{name: "rtl", in: "אבג", want: "גבא"},
+		{name: "rtl words", in: "אב גד", want: "דג בא"},
+		{name: "mixed", in: "Total אבג 42", want: "Total גבא 42"},
+		{name: "digits inside rtl", in: "אב 123 גד", want: "דג 123 בא"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Visual(tt.in); got != tt.want {
+				t.Errorf("Visual(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
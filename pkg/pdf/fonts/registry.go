@@ -0,0 +1,171 @@
+// Package fonts manages the TrueType fonts embedded in generated documents
+// and prepares Unicode text for them.
+//
+// Fonts are registered by family and style from files, an fs.FS such as
+// embed.FS, or raw bytes. The generator embeds each font the first time a
+// document uses it and only the glyphs the document needs are written.
+// Characters missing from a font are drawn with the first fallback family
+// that has them.
+package fonts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Style selects a variant of a font family, using gofpdf style letters
+type Style string
+
+const (
+	Regular    Style = ""
+	Bold       Style = "B"
+	Italic     Style = "I"
+	BoldItalic Style = "BI"
+)
+
+// Font is a registered TrueType font
+type Font struct {
+	Family string
+	Style  Style
+	data   []byte
+	glyphs *coverage
+}
+
+// Data returns the font file contents
+func (f *Font) Data() []byte {
+	return f.data
+}
+
+// Has reports whether the font has a glyph for r
+func (f *Font) Has(r rune) bool {
+	return f.glyphs.has(r)
+}
+
+// Registry maps font families and styles to TrueType fonts. It is safe for
+// concurrent use and can be shared by several generators.
+type Registry struct {
+	mu        sync.RWMutex
+	fonts     map[string]*Font
+	fallbacks []string
+}
+
+// NewRegistry creates an empty font registry
+func NewRegistry() *Registry {
+	return &Registry{fonts: make(map[string]*Font)}
+}
+
+// Add registers a TrueType font. OpenType fonts are accepted when they use
+// TrueType outlines; CFF-based fonts and font collections are not supported.
+func (r *Registry) Add(family string, style Style, data []byte) error {
+	if family == "" {
+		return fmt.Errorf("font family is required")
+	}
+	switch style {
+	case Regular, Bold, Italic, BoldItalic:
+	default:
+		return fmt.Errorf("unknown font style %q", style)
+	}
+	if err := checkSignature(data); err != nil {
+		return fmt.Errorf("font %s: %w", family, err)
+	}
+
+	glyphs, err := parseCmap(data)
+	if err != nil {
+		return fmt.Errorf("font %s: %w", family, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fonts[key(family, style)] = &Font{Family: family, Style: style, data: data, glyphs: glyphs}
+	return nil
+}
+
+// AddFile registers a font file from disk
+func (r *Registry) AddFile(family string, style Style, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read font: %w", err)
+	}
+	return r.Add(family, style, data)
+}
+
+// AddFS registers a font file from a file system such as embed.FS
+func (r *Registry) AddFS(fsys fs.FS, family string, style Style, name string) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fmt.Errorf("failed to read font: %w", err)
+	}
+	return r.Add(family, style, data)
+}
+
+// SetFallbacks sets the families, in order, used for characters that the
+// requested font cannot draw
+func (r *Registry) SetFallbacks(families ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallbacks = append([]string(nil), families...)
+}
+
+// Lookup returns the font registered for a family and style. A missing
+// bold or italic variant falls back to the closest registered one, ending
+// with the regular style.
+func (r *Registry) Lookup(family string, style Style) (*Font, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	candidates := []Style{style}
+	if style == BoldItalic {
+		candidates = append(candidates, Bold, Italic)
+	}
+	for _, candidate := range append(candidates, Regular) {
+		if font, ok := r.fonts[key(family, candidate)]; ok {
+			return font, true
+		}
+	}
+	return nil, false
+}
+
+// Fallbacks returns the fallback fonts in order for the given style
+func (r *Registry) Fallbacks(style Style) []*Font {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	families := r.fallbacks
+	r.mu.RUnlock()
+
+	var fonts []*Font
+	for _, family := range families {
+		if font, ok := r.Lookup(family, style); ok {
+			fonts = append(fonts, font)
+		}
+	}
+	return fonts
+}
+
+func key(family string, style Style) string {
+	return strings.ToLower(family) + "|" + string(style)
+}
+
+// checkSignature rejects font formats gofpdf cannot embed
+func checkSignature(data []byte) error {
+	if len(data) < 12 {
+		return fmt.Errorf("not a TrueType font")
+	}
+	switch binary.BigEndian.Uint32(data) {
+	case 0x00010000, 0x74727565: // 1.0 and "true"
+		return nil
+	case 0x4f54544f: // "OTTO"
+		return fmt.Errorf("OpenType fonts with CFF outlines are not supported, use a TrueType flavored font")
+	case 0x74746366: // "ttcf"
+		return fmt.Errorf("font collections are not supported, extract a single font")
+	}
+	return fmt.Errorf("not a TrueType font")
+}
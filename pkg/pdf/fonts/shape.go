@@ -0,0 +1,202 @@
+package fonts
+
+// Arabic letters are stored in their base form and must be replaced by the
+// isolated, final, initial or medial presentation form that matches their
+// neighbours before drawing, as gofpdf does not shape text. Letters outside
+// the basic Arabic alphabet, such as the Persian and Urdu additions, are
+// left unshaped.
+
+const (
+	tatweel = 0x0640
+	lam     = 0x0644
+)
+
+// arabicLetters lists the letters of the Arabic Presentation Forms-B block
+// in block order with their number of forms: 1 for hamza, 2 for letters
+// that only join the preceding letter and 4 for letters that join both sides
+var arabicLetters = []struct {
+	base  rune
+	forms int
+}{
+	{0x0621, 1}, {0x0622, 2}, {0x0623, 2}, {0x0624, 2}, {0x0625, 2}, {0x0626, 4},
+	{0x0627, 2}, {0x0628, 4}, {0x0629, 2}, {0x062A, 4}, {0x062B, 4}, {0x062C, 4},
+	{0x062D, 4}, {0x062E, 4}, {0x062F, 2}, {0x0630, 2}, {0x0631, 2}, {0x0632, 2},
+	{0x0633, 4}, {0x0634, 4}, {0x0635, 4}, {0x0636, 4}, {0x0637, 4}, {0x0638, 4},
+	{0x0639, 4}, {0x063A, 4}, {0x0641, 4}, {0x0642, 4}, {0x0643, 4}, {0x0644, 4},
+	{0x0645, 4}, {0x0646, 4}, {0x0647, 4}, {0x0648, 2}, {0x0649, 2}, {0x064A, 4},
+}
+
+// arabicForm maps a letter to its isolated presentation form, which is
+// followed by its final, initial and medial forms
+var arabicForm, arabicForms = func() (map[rune]rune, map[rune]int) {
+	form := make(map[rune]rune, len(arabicLetters))
+	forms := make(map[rune]int, len(arabicLetters))
+	next := rune(0xFE80)
+	for _, letter := range arabicLetters {
+		form[letter.base] = next
+		forms[letter.base] = letter.forms
+		next += rune(letter.forms)
+	}
+	return form, forms
+}()
+
+// lamAlef maps the alef that follows a lam to the isolated form of their ligature
+var lamAlef = map[rune]rune{0x0622: 0xFEF5, 0x0623: 0xFEF7, 0x0625: 0xFEF9, 0x0627: 0xFEFB}
+
+// Shape replaces Arabic letters with their contextual presentation forms
+// and joins lam-alef pairs into ligatures. Other text is returned unchanged.
+func Shape(s string) string {
+	runes := []rune(s)
+	arabic := false
+	for _, r := range runes {
+		if _, ok := arabicForm[r]; ok {
+			arabic = true
+			break
+		}
+	}
+	if !arabic {
+		return s
+	}
+
+	shaped := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		base, ok := arabicForm[r]
+		if !ok {
+			shaped = append(shaped, r)
+			continue
+		}
+		joinsPrev := joinsLeft(neighbour(runes, i, -1))
+
+		if r == lam {
+			if j := neighbourIndex(runes, i, 1); j >= 0 {
+				if ligature, ok := lamAlef[runes[j]]; ok {
+					if joinsPrev {
+						ligature++
+					}
+					shaped = append(shaped, ligature)
+					shaped = append(shaped, runes[i+1:j]...)
+					i = j
+					continue
+				}
+			}
+		}
+
+		forms := arabicForms[r]
+		joinsNext := forms == 4 && joinsRight(neighbour(runes, i, 1))
+		switch {
+		case joinsPrev && joinsNext:
+			shaped = append(shaped, base+3)
+		case joinsPrev && forms > 1:
+			shaped = append(shaped, base+1)
+		case joinsNext:
+			shaped = append(shaped, base+2)
+		default:
+			shaped = append(shaped, base)
+		}
+	}
+	return string(shaped)
+}
+
+// joinsLeft reports whether a letter connects to the letter after it
+func joinsLeft(r rune) bool {
+	return r == tatweel || arabicForms[r] == 4
+}
+
+// joinsRight reports whether a letter connects to the letter before it
+func joinsRight(r rune) bool {
+	return r == tatweel || arabicForms[r] > 1
+}
+
+// neighbour returns the closest letter in direction dir, skipping vowel
+// marks, or zero at the ends of the text
+func neighbour(runes []rune, i, dir int) rune {
+	if j := neighbourIndex(runes, i, dir); j >= 0 {
+		return runes[j]
+	}
+	return 0
+}
+
+func neighbourIndex(runes []rune, i, dir int) int {
+	for j := i + dir; j >= 0 && j < len(runes); j += dir {
+		if !isMark(runes[j]) {
+			return j
+		}
+	}
+	return -1
+}
+
+// isMark reports whether r is an Arabic vowel mark, which does not affect joining
+func isMark(r rune) bool {
+	return (r >= 0x064B && r <= 0x065F) || r == 0x0670
+}
+
+// isRTL reports whether r belongs to a right-to-left script
+func isRTL(r rune) bool {
+	return (r >= 0x0590 && r <= 0x08FF) || (r >= 0xFB1D && r <= 0xFDFF) || (r >= 0xFE70 && r <= 0xFEFF)
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// Visual reorders a line for left-to-right drawing: runs of right-to-left
+// text, including the spaces and punctuation between their words, are
+// reversed while numbers inside them keep their order. This covers names
+// and short phrases in a left-to-right document; it is not a full
+// implementation of the Unicode bidirectional algorithm.
+func Visual(s string) string {
+	runes := []rune(s)
+	rtl := false
+	for _, r := range runes {
+		if isRTL(r) {
+			rtl = true
+			break
+		}
+	}
+	if !rtl {
+		return s
+	}
+
+	for start := 0; start < len(runes); start++ {
+		if !isRTL(runes[start]) {
+			continue
+		}
+		// A run ends at its last right-to-left character before any
+		// left-to-right letter
+		end := start
+		for j := start + 1; j < len(runes); j++ {
+			if isRTL(runes[j]) {
+				end = j
+			} else if isLTRLetter(runes[j]) {
+				break
+			}
+		}
+
+		reverse(runes[start : end+1])
+		for i := start; i <= end; i++ {
+			if !isDigit(runes[i]) {
+				continue
+			}
+			j := i
+			for j+1 <= end && (isDigit(runes[j+1]) || runes[j+1] == '.' || runes[j+1] == ',') {
+				j++
+			}
+			reverse(runes[i : j+1])
+			i = j
+		}
+		start = end
+	}
+	return string(runes)
+}
+
+// isLTRLetter reports whether r is a letter of a left-to-right script
+func isLTRLetter(r rune) bool {
+	return !isRTL(r) && !isDigit(r) && (r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= 0xC0 && r < 0x0590)
+}
+
+func reverse(runes []rune) {
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"strings"
 
+	"github.com/josephmojoo/pdfgen/pkg/pdf/internal/color"
 	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
 	"github.com/jung-kurt/gofpdf"
 )
@@ -220,7 +221,12 @@ func (g *Generator) formatValue(value interface{}) string {
 
 // Generate creates a PDF document from the template and writes it to the provided writer
 func (g *Generator) Generate(ctx context.Context, w io.Writer, template *model.Template) error {
-	pdf := gofpdf.New("P", "mm", "A4", "")
+	size := g.pageSize(template)
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: size.Width, Ht: size.Height},
+	})
 
 	// Add page
 	pdf.AddPage()
@@ -242,6 +248,23 @@ func (g *Generator) Generate(ctx context.Context, w io.Writer, template *model.T
 	return pdf.Output(w)
 }
 
+// pageSize returns the template's first page size, sizing continuous rolls
+// to the lowest element plus the bottom margin
+func (g *Generator) pageSize(template *model.Template) model.Size {
+	size := template.PageSizeFor(1)
+	if size.Height > 0 {
+		return size
+	}
+
+	size.Height = g.margins.Top + g.margins.Bottom
+	for _, element := range template.Elements {
+		if bottom := element.Bounds.Y + element.Bounds.Height + g.margins.Bottom; bottom > size.Height {
+			size.Height = bottom
+		}
+	}
+	return size
+}
+
 func (g *Generator) renderText(pdf *gofpdf.Fpdf, element model.Element) error {
 	style := element.Style
 	if style == nil {
@@ -252,8 +275,15 @@ func (g *Generator) renderText(pdf *gofpdf.Fpdf, element model.Element) error {
 		}
 	}
 
-	pdf.SetFont(style.FontFamily, "", style.FontSize)
-	pdf.SetTextColor(0, 0, 0) // Parse style.FontColor and set RGB values
+	pdf.SetFont(style.FontFamily, fontStyle(style), style.FontSize)
+	textColor := color.Black
+	if style.FontColor != "" {
+		var err error
+		if textColor, err = color.Parse(style.FontColor); err != nil {
+			return fmt.Errorf("invalid font color: %w", err)
+		}
+	}
+	pdf.SetTextColor(textColor.R, textColor.G, textColor.B)
 
 	content, ok := element.Content.(string)
 	if !ok {
@@ -264,11 +294,33 @@ func (g *Generator) renderText(pdf *gofpdf.Fpdf, element model.Element) error {
 	y := element.Bounds.Position.Y
 	lineHeight := pdf.PointToUnitConvert(style.FontSize) * 1.2 // 1.2 for comfortable line spacing
 
+	// Core fonts expect Windows-1252 rather than UTF-8
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+
 	// Handle multiline text
 	for _, line := range strings.Split(content, "\n") {
-		pdf.Text(x, y, line)
+		pdf.Text(x, y, translate(line))
 		y += lineHeight
 	}
 
 	return nil
 }
+
+// fontStyle returns the gofpdf style letters for a style's weight, slant
+// and decoration
+func fontStyle(style *model.Style) string {
+	var letters strings.Builder
+	if style.FontWeight.Bold() {
+		letters.WriteString("B")
+	}
+	if style.FontStyle.Italic() {
+		letters.WriteString("I")
+	}
+	if style.TextDecoration.Has(model.DecorationUnderline) {
+		letters.WriteString("U")
+	}
+	if style.TextDecoration.Has(model.DecorationLineThrough) {
+		letters.WriteString("S")
+	}
+	return letters.String()
+}
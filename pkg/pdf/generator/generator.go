@@ -4,9 +4,15 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/josephmojoo/pdfgen/pkg/pdf/assets"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/fonts"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/generator/internal/acroform"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/generator/internal/binding"
 	"github.com/josephmojoo/pdfgen/pkg/pdf/generator/internal/layout"
 	"github.com/josephmojoo/pdfgen/pkg/pdf/generator/internal/render"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/hyphen"
 	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
 	"github.com/jung-kurt/gofpdf"
 )
@@ -17,6 +23,11 @@ type Generator struct {
 	layout   *layout.Manager
 	registry *render.Registry
 	margins  model.Padding
+	// now is the clock behind the {date} header and footer token
+	now        func() time.Time
+	hyphenator hyphen.Hyphenator
+	fonts      *fonts.Registry
+	assets     AssetResolver
 }
 
 // New creates a new PDF generator
@@ -30,9 +41,11 @@ func New(template *model.Template) *Generator {
 
 	return &Generator{
 		template: template,
-		layout:   layout.NewManager(template.Size, margins),
+		layout:   layout.NewManager(template.PageSizeFor, margins),
 		registry: render.NewRegistry(),
 		margins:  margins,
+		now:      time.Now,
+		fonts:    fonts.NewRegistry(),
 	}
 }
 
@@ -46,38 +59,52 @@ func (g *Generator) Generate(ctx context.Context, data interface{}) (*bytes.Buff
 		return nil, fmt.Errorf("invalid data: %w", err)
 	}
 
-	// Calculate layout
-	if err := g.layout.CalculateLayout(g.template.Elements); err != nil {
+	// Resolve data bindings against a copy of the template elements
+	scope, err := binding.NewScope(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data: %w", err)
+	}
+	elements, err := binding.ResolveElements(g.template.Elements, scope)
+	if err != nil {
+		return nil, fmt.Errorf("data binding failed: %w", err)
+	}
+
+	// Calculate layout, measuring content on a scratch document
+	measureCtx := &render.Context{PDF: newPDF(g.template.PageSizeFor(1)), Margins: g.margins, Hyphenator: g.hyphenator, Fonts: g.fonts}
+	g.layout.SetMeasure(func(element model.Element) (float64, error) {
+		return g.registry.Measure(measureCtx, element)
+	})
+	g.layout.SetSplit(func(element model.Element, height, pageHeight float64) ([]model.Element, error) {
+		return g.registry.Split(measureCtx, element, height, pageHeight)
+	})
+	g.layout.SetRegions(g.template.Header, g.template.Footer)
+	if err := g.layout.CalculateLayout(elements); err != nil {
 		return nil, fmt.Errorf("layout calculation failed: %w", err)
 	}
 
-	// Create PDF document
-	pdf := gofpdf.New("P", "mm", "A4", "")
+	// Create PDF document sized to the first page
+	pdf := newPDF(g.layout.PageSize(1))
 	renderCtx := &render.Context{
-		PDF: pdf,
-		PageSize: model.Size{
-			Width:  g.template.Size.Width,
-			Height: g.template.Size.Height,
-		},
-		Margins: g.margins,
+		PDF:        pdf,
+		Margins:    g.margins,
+		Form:       acroform.NewForm(),
+		Hyphenator: g.hyphenator,
+		Fonts:      g.fonts,
+		Assets:     g.assets,
 	}
 
 	// Render each page
 	totalPages := g.layout.TotalPages()
 	for page := 1; page <= totalPages; page++ {
-		pdf.AddPage()
+		renderCtx.PageSize = g.layout.PageSize(page)
+		addPage(pdf, renderCtx.PageSize)
 
 		// Render elements for current page
-		elements := g.layout.GetPageElements(page)
-		for _, element := range elements {
-			renderer, err := g.registry.GetRenderer(element.Type)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get renderer: %w", err)
-			}
-
-			if err := renderer.Render(renderCtx, element); err != nil {
-				return nil, fmt.Errorf("failed to render element: %w", err)
-			}
+		if err := g.renderElements(renderCtx, g.layout.GetPageElements(page)); err != nil {
+			return nil, err
+		}
+		if err := g.renderRegions(renderCtx, scope, page, totalPages); err != nil {
+			return nil, err
 		}
 	}
 
@@ -87,9 +114,51 @@ func (g *Generator) Generate(ctx context.Context, data interface{}) (*bytes.Buff
 		return nil, fmt.Errorf("failed to write PDF: %w", err)
 	}
 
+	// Append interactive form fields, which gofpdf cannot write itself
+	if !renderCtx.Form.Empty() {
+		withForm, err := renderCtx.Form.Apply(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("failed to add form fields: %w", err)
+		}
+		return bytes.NewBuffer(withForm), nil
+	}
+
 	return &buf, nil
 }
 
+// renderElements draws positioned elements on the current page
+func (g *Generator) renderElements(ctx *render.Context, elements []model.Element) error {
+	for _, element := range elements {
+		renderer, err := g.registry.GetRenderer(element.Type)
+		if err != nil {
+			return fmt.Errorf("failed to get renderer: %w", err)
+		}
+
+		if err := renderer.Render(ctx, element); err != nil {
+			return fmt.Errorf("failed to render element: %w", err)
+		}
+	}
+	return nil
+}
+
+// newPDF creates a document in millimetres with the given default page size.
+// Automatic page breaks are disabled because the layout manager paginates.
+func newPDF(size model.Size) *gofpdf.Fpdf {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: size.Width, Ht: size.Height},
+	})
+	pdf.SetAutoPageBreak(false, 0)
+	return pdf
+}
+
+// addPage starts a page of the given size. Sizes are already oriented, so
+// landscape pages are simply wider than they are tall.
+func addPage(pdf *gofpdf.Fpdf, size model.Size) {
+	pdf.AddPageFormat("P", gofpdf.SizeType{Wd: size.Width, Ht: size.Height})
+}
+
 // RegisterRenderer registers a custom renderer for an element type
 func (g *Generator) RegisterRenderer(elementType model.ElementType, renderer render.ElementRenderer) {
 	g.registry.RegisterRenderer(elementType, renderer)
@@ -98,5 +167,44 @@ func (g *Generator) RegisterRenderer(elementType model.ElementType, renderer ren
 // SetMargins sets the page margins
 func (g *Generator) SetMargins(margins model.Padding) {
 	g.margins = margins
-	g.layout = layout.NewManager(g.template.Size, margins)
+	g.layout = layout.NewManager(g.template.PageSizeFor, margins)
+}
+
+// SetHyphenator sets the hyphenator used to break words in text styled with
+// hyphenate, such as hyphen.Patterns loaded from TeX pattern files
+func (g *Generator) SetHyphenator(hyphenator hyphen.Hyphenator) {
+	g.hyphenator = hyphenator
+}
+
+// Fonts returns the registry of TrueType fonts available to the template.
+// Families registered here are embedded in the document when a style names
+// them; other families use the standard PDF fonts.
+func (g *Generator) Fonts() *fonts.Registry {
+	return g.fonts
+}
+
+// SetFonts replaces the font registry, for sharing one registry between
+// several generators
+func (g *Generator) SetFonts(registry *fonts.Registry) {
+	g.fonts = registry
+}
+
+// AssetResolver opens the images named in templates. The assets package
+// provides resolvers for a directory, an fs.FS such as embed.FS, in-memory
+// data and functions.
+type AssetResolver = assets.Resolver
+
+// SetAssetResolver sets where image sources are read from. Data URIs are
+// always decoded in place; without a resolver other sources are local file
+// paths.
+func (g *Generator) SetAssetResolver(resolver AssetResolver) {
+	g.assets = resolver
+}
+
+// FilterFunc transforms a bound value inside a {{ value | filter args }} expression
+type FilterFunc = binding.FilterFunc
+
+// RegisterFilter makes a custom filter available to all template bindings
+func RegisterFilter(name string, filter FilterFunc) {
+	binding.RegisterFilter(name, filter)
 }
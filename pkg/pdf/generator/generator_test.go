@@ -0,0 +1,604 @@
+package generator
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/assets"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/fonts"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/generator/internal/render"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+func textElement(id, content string) model.Element {
+	return model.Element{
+		ID:      id,
+		Type:    model.ElementTypeText,
+		Bounds:  model.Bounds{Size: model.Size{Width: 190, Height: 10}},
+		Content: content,
+		Style:   &model.Style{FontFamily: "Arial", FontSize: 12},
+	}
+}
+
+func testData() map[string]interface{} {
+	return map[string]interface{}{
+		"customer": map[string]interface{}{"name": "John Doe"},
+		"order": map[string]interface{}{
+			"id":     "ORD-12345",
+			"status": "Completed",
+			"items": []interface{}{
+				map[string]interface{}{"name": "Product A", "sku": "4006381333931", "quantity": 2},
+				map[string]interface{}{"name": "Product B", "sku": "9780201379624", "quantity": 5},
+			},
+		},
+	}
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	template := &model.Template{
+		Name: "invoice",
+		Size: model.Size{Width: 210, Height: 297},
+		Elements: []model.Element{
+			textElement("title", "Invoice {{ order.id }} for {{ customer.name | upper }}"),
+			{
+				ID:      "items",
+				Type:    model.ElementTypeTable,
+				Bounds:  model.Bounds{Size: model.Size{Width: 190, Height: 30}},
+				Content: []interface{}{[]interface{}{"Item", "SKU"}, map[string]interface{}{"repeat": "order.items", "cells": []interface{}{"{{ item.name }}", "{{ item.sku }}"}}},
+				Style:   &model.Style{FontFamily: "Arial", FontSize: 10},
+			},
+			{
+				ID:       "lines",
+				Type:     model.ElementTypeTable,
+				Bounds:   model.Bounds{Size: model.Size{Width: 190, Height: 30}},
+				Content:  "{{ order.items }}",
+				Metadata: json.RawMessage(`{"columns": [{"key": "name", "header": "Item"}, {"key": "sku", "header": "SKU"}]}`),
+			},
+			{
+				ID:       "quantities",
+				Type:     model.ElementTypeChart,
+				Bounds:   model.Bounds{Size: model.Size{Width: 120, Height: 60}},
+				Content:  "{{ order.items }}",
+				Metadata: json.RawMessage(`{"chart": "bar", "categoryKey": "name", "series": [{"name": "Quantity", "key": "quantity"}], "showValues": true}`),
+			},
+			{
+				ID:      "seal",
+				Type:    model.ElementTypeSVG,
+				Bounds:  model.Bounds{Size: model.Size{Width: 30, Height: 30}},
+				Content: `<svg viewBox="0 0 24 24"><circle cx="12" cy="12" r="10" fill="none" stroke="#1a5276"/><text x="12" y="16" text-anchor="middle">{{ order.status }}</text></svg>`,
+			},
+			{
+				ID:       "sku",
+				Type:     model.ElementTypeBarcode,
+				Repeat:   "order.items",
+				Bounds:   model.Bounds{Size: model.Size{Width: 60, Height: 20}},
+				Content:  "{{ item.sku }}",
+				Metadata: json.RawMessage(`{"symbology": "ean13", "showText": true}`),
+			},
+			{
+				ID:       "tracking",
+				Type:     model.ElementTypeBarcode,
+				Bounds:   model.Bounds{Size: model.Size{Width: 80, Height: 15}},
+				Content:  "{{ order.id }}",
+				Metadata: json.RawMessage(`{"symbology": "code128"}`),
+			},
+			{
+				ID:       "verify",
+				Type:     model.ElementTypeBarcode,
+				Bounds:   model.Bounds{Size: model.Size{Width: 40, Height: 40}},
+				Content:  "https://example.com/verify/{{ order.id }}",
+				Metadata: json.RawMessage(`{"symbology": "qr", "errorCorrection": "H"}`),
+			},
+			{
+				ID:       "matrix",
+				Type:     model.ElementTypeBarcode,
+				Bounds:   model.Bounds{Size: model.Size{Width: 20, Height: 20}},
+				Content:  "{{ order.id }}",
+				Metadata: json.RawMessage(`{"symbology": "datamatrix"}`),
+			},
+			{
+				ID:       "payment",
+				Type:     model.ElementTypeBarcode,
+				Bounds:   model.Bounds{Size: model.Size{Width: 80, Height: 25}},
+				Content:  "{{ order.id }}|{{ customer.name }}",
+				Metadata: json.RawMessage(`{"symbology": "pdf417", "securityLevel": 3}`),
+			},
+		},
+	}
+
+	gen := New(template)
+	// Generating twice checks that no layout state leaks between documents
+	for i := 0; i < 2; i++ {
+		buf, err := gen.Generate(context.Background(), testData())
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+			t.Fatalf("Generate() did not produce a PDF")
+		}
+	}
+}
+
+func TestGenerator_GenerateInvalidBarcode(t *testing.T) {
+	template := &model.Template{
+		Name: "label",
+		Size: model.Size{Width: 100, Height: 50},
+		Elements: []model.Element{{
+			ID:       "ean",
+			Type:     model.ElementTypeBarcode,
+			Bounds:   model.Bounds{Size: model.Size{Width: 60, Height: 20}},
+			Content:  "not-digits",
+			Metadata: json.RawMessage(`{"symbology": "ean13"}`),
+		}},
+	}
+
+	if _, err := New(template).Generate(context.Background(), nil); err == nil {
+		t.Error("Generate() error = nil, want error for invalid EAN-13 content")
+	}
+}
+
+func TestGenerator_GenerateForm(t *testing.T) {
+	field := func(id string, content interface{}, metadata string) model.Element {
+		return model.Element{
+			ID:       id,
+			Type:     model.ElementTypeForm,
+			Bounds:   model.Bounds{Size: model.Size{Width: 100, Height: 10}},
+			Content:  content,
+			Style:    &model.Style{FontFamily: "Arial", FontSize: 10, Border: &model.Border{Width: 0.2}},
+			Metadata: json.RawMessage(metadata),
+		}
+	}
+
+	template := &model.Template{
+		Name: "onboarding",
+		Size: model.Size{Width: 210, Height: 297},
+		Elements: []model.Element{
+			field("name", "{{ customer.name }}", `{"field": "text", "required": true}`),
+			field("status", "{{ order.status == 'Completed' }}", `{"field": "checkbox", "label": "Order completed"}`),
+			field("plan", "Pro", `{"field": "radio", "options": ["Basic", "Pro"]}`),
+			field("country", "", `{"field": "dropdown", "options": ["Malawi", "Zambia"]}`),
+			field("signature", nil, `{"field": "signature", "name": "customer_signature"}`),
+		},
+	}
+
+	buf, err := New(template).Generate(context.Background(), testData())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"/AcroForm", "/T (name)", "/V (John Doe)", "/T (status)", "/V /Yes", "/T (plan)", "/T (customer_signature)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("generated PDF is missing %q", want)
+		}
+	}
+}
+
+func TestGenerator_GeneratePageSizes(t *testing.T) {
+	block := func(id string) model.Element {
+		element := textElement(id, id)
+		element.Bounds.Height = 150
+		return element
+	}
+	line := func(id string) model.Element {
+		element := textElement(id, id)
+		element.Bounds.Width = 60
+		return element
+	}
+
+	tests := []struct {
+		name     string
+		template *model.Template
+		want     []string
+	}{
+		{
+			name: "landscape override on second page",
+			template: &model.Template{
+				Name:     "report",
+				PageSize: "A4",
+				Pages:    []model.PageSetup{{Page: 2, Orientation: model.OrientationLandscape}},
+				Elements: []model.Element{block("summary"), block("chart")},
+			},
+			want: []string{"/MediaBox [0 0 595.28 841.89]", "/MediaBox [0 0 841.89 595.28]"},
+		},
+		{
+			name: "receipt roll sized to content",
+			template: &model.Template{
+				Name:     "receipt",
+				PageSize: "receipt80",
+				Elements: []model.Element{line("shop"), line("total"), line("thanks")},
+			},
+			want: []string{"/MediaBox [0 0 226.77 141.73]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := New(tt.template).Generate(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			out := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("generated PDF is missing %q", want)
+				}
+			}
+		})
+	}
+}
+
+// recordingRenderer captures the elements it is asked to draw
+type recordingRenderer struct {
+	pages    []int
+	elements []model.Element
+}
+
+func (r *recordingRenderer) Render(ctx *render.Context, element model.Element) error {
+	r.pages = append(r.pages, ctx.PDF.PageNo())
+	r.elements = append(r.elements, element)
+	return nil
+}
+
+func TestGenerator_GenerateHeaderFooter(t *testing.T) {
+	block := func(id string) model.Element {
+		element := textElement(id, id)
+		element.Bounds.Height = 200
+		return element
+	}
+
+	template := &model.Template{
+		Name:     "report",
+		PageSize: "A4",
+		Elements: []model.Element{block("one"), block("two"), block("three")},
+		Header: &model.Region{
+			Height:   20,
+			Elements: []model.Element{textElement("letterhead", "Report {{ order.id }}")},
+			First:    &model.Region{Height: 40, Elements: []model.Element{textElement("cover", "Cover")}},
+			Even:     &model.Region{Height: 20, Elements: []model.Element{textElement("even", "Even")}},
+		},
+		Footer: &model.Region{
+			Height: 10,
+			Elements: []model.Element{{
+				ID:      "pagination",
+				Type:    model.ElementTypeText,
+				Bounds:  model.Bounds{Position: model.Position{X: 150}},
+				Content: "Page {page} of {pages}, {date}",
+			}},
+		},
+	}
+
+	gen := New(template)
+	gen.now = func() time.Time { return time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC) }
+	recorder := &recordingRenderer{}
+	gen.RegisterRenderer(model.ElementTypeText, recorder)
+
+	if _, err := gen.Generate(context.Background(), testData()); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	got := make(map[string]model.Element)
+	var order []string
+	for i, element := range recorder.elements {
+		key := fmt.Sprintf("%d:%v", recorder.pages[i], element.Content)
+		got[key] = element
+		order = append(order, key)
+	}
+
+	tests := []struct {
+		key    string
+		bounds model.Bounds
+	}{
+		{"1:Cover", model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 190, Height: 10}}},
+		{"1:one", model.Bounds{Position: model.Position{X: 10, Y: 50}, Size: model.Size{Width: 190, Height: 200}}},
+		{"1:Page 1 of 3, 2025-03-14", model.Bounds{Position: model.Position{X: 160, Y: 277}, Size: model.Size{Width: 40, Height: 10}}},
+		{"2:Even", model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 190, Height: 10}}},
+		{"2:two", model.Bounds{Position: model.Position{X: 10, Y: 30}, Size: model.Size{Width: 190, Height: 200}}},
+		{"3:Report ORD-12345", model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 190, Height: 10}}},
+		{"3:Page 3 of 3, 2025-03-14", model.Bounds{Position: model.Position{X: 160, Y: 277}, Size: model.Size{Width: 40, Height: 10}}},
+	}
+	for _, tt := range tests {
+		element, ok := got[tt.key]
+		if !ok {
+			t.Errorf("element %q was not rendered; rendered %v", tt.key, order)
+			continue
+		}
+		if element.Bounds != tt.bounds {
+			t.Errorf("element %q bounds = %+v, want %+v", tt.key, element.Bounds, tt.bounds)
+		}
+	}
+}
+
+func TestGenerator_GenerateTextRuns(t *testing.T) {
+	greeting := model.Element{
+		ID:     "greeting",
+		Type:   model.ElementTypeText,
+		Bounds: model.Bounds{Size: model.Size{Width: 190, Height: 10}},
+		Content: []interface{}{
+			"Dear ",
+			map[string]interface{}{"text": "{{ customer.name }}", "bold": true},
+			", order {{ order.id }}",
+		},
+		Style: &model.Style{FontFamily: "Helvetica", FontSize: 12},
+	}
+	template := &model.Template{Name: "letter", PageSize: "A4", Elements: []model.Element{greeting}}
+
+	gen := New(template)
+	recorder := &recordingRenderer{}
+	gen.RegisterRenderer(model.ElementTypeText, recorder)
+	if _, err := gen.Generate(context.Background(), testData()); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	want := []interface{}{
+		"Dear ",
+		map[string]interface{}{"text": "John Doe", "bold": true},
+		", order ORD-12345",
+	}
+	if len(recorder.elements) != 1 || !reflect.DeepEqual(recorder.elements[0].Content, want) {
+		t.Fatalf("text renderer received %#v, want %#v", recorder.elements, want)
+	}
+
+	// The text renderer draws the bound name in bold
+	buf, err := New(template).Generate(context.Background(), testData())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	out := inflateStreams(t, buf.Bytes())
+	// Rich text is drawn word by word
+	for _, want := range []string{"(John) Tj", "(Doe) Tj", "(ORD-12345) Tj", "/BaseFont /Helvetica-Bold"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("document does not contain %q", want)
+		}
+	}
+	if strings.Contains(out, "map[") {
+		t.Errorf("document draws the runs as a Go map")
+	}
+}
+
+func TestGenerator_GenerateImages(t *testing.T) {
+	var logo, signature bytes.Buffer
+	if err := png.Encode(&logo, image.NewGray(image.Rect(0, 0, 8, 4))); err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(&signature, image.NewGray(image.Rect(0, 0, 30, 10))); err != nil {
+		t.Fatal(err)
+	}
+
+	template := &model.Template{
+		Name:     "receipt",
+		PageSize: "A4",
+		Elements: []model.Element{
+			{ID: "logo", Type: model.ElementTypeImage, Bounds: model.Bounds{Size: model.Size{Width: 40, Height: 20}}, Content: "logo:{{ branch }}"},
+			{ID: "signature", Type: model.ElementTypeImage, Bounds: model.Bounds{Size: model.Size{Width: 40, Height: 20}}, Content: "data:image/png;base64,{{ signature }}"},
+		},
+	}
+	data := map[string]interface{}{"branch": "42", "signature": base64.StdEncoding.EncodeToString(signature.Bytes())}
+
+	gen := New(template)
+	gen.SetAssetResolver(assets.Map{"logo:42": logo.Bytes()})
+	out, err := gen.Generate(context.Background(), data)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got := strings.Count(out.String(), "/Subtype /Image"); got != 2 {
+		t.Errorf("document embeds %d images, want the logo and the signature", got)
+	}
+
+	gen.SetAssetResolver(assets.Map{})
+	if _, err := gen.Generate(context.Background(), data); err == nil || !strings.Contains(err.Error(), "logo:42") {
+		t.Errorf("Generate() error = %v, want the missing logo", err)
+	}
+}
+
+func TestGenerator_GenerateSplitTable(t *testing.T) {
+	items := make([]interface{}, 100)
+	for i := range items {
+		items[i] = map[string]interface{}{"name": fmt.Sprintf("Transaction %d", i+1), "amount": 10.0}
+	}
+
+	template := &model.Template{
+		Name:     "statement",
+		PageSize: "A4",
+		Elements: []model.Element{
+			textElement("title", "Statement"),
+			{
+				ID:     "transactions",
+				Type:   model.ElementTypeTable,
+				Bounds: model.Bounds{Size: model.Size{Width: 190}},
+				Content: []interface{}{
+					[]interface{}{"Description", "Amount"},
+					map[string]interface{}{"repeat": "items", "cells": []interface{}{"{{ item.name }}", "{{ item.amount }}"}},
+				},
+				Style:    &model.Style{Overflow: model.OverflowGrow, Border: &model.Border{Width: 0.2}},
+				Metadata: json.RawMessage(`{"headerRows": 1, "subtotal": ["Carried forward", "{total}"]}`),
+			},
+		},
+	}
+
+	gen := New(template)
+	if _, err := gen.Generate(context.Background(), map[string]interface{}{"items": items}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	pages := gen.layout.TotalPages()
+	if pages < 3 {
+		t.Fatalf("TotalPages() = %d, want the table to continue over several pages", pages)
+	}
+	rows := 0
+	for page := 1; page <= pages; page++ {
+		for _, element := range gen.layout.GetPageElements(page) {
+			if element.ID != "transactions" {
+				continue
+			}
+			content := element.Content.([]interface{})
+			if header := content[0].([]interface{}); header[0] != "Description" {
+				t.Errorf("page %d table starts with %v, want the header row", page, header)
+			}
+			if bottom := element.Bounds.Y + element.Bounds.Height; bottom > 287+1e-9 {
+				t.Errorf("page %d table ends at %v, below the bottom margin", page, bottom)
+			}
+			rows += len(content) - 1
+		}
+	}
+	if rows != 100 {
+		t.Errorf("tables hold %d rows, want 100", rows)
+	}
+}
+
+// inflateStreams returns the PDF with every Flate compressed stream
+// decompressed, so text and font tables can be searched
+func inflateStreams(t *testing.T, pdf []byte) string {
+	t.Helper()
+	var sb strings.Builder
+	for {
+		start := bytes.Index(pdf, []byte("stream\n"))
+		if start < 0 {
+			sb.Write(pdf)
+			return sb.String()
+		}
+		start += len("stream\n")
+		end := bytes.Index(pdf[start:], []byte("endstream"))
+		if end < 0 {
+			t.Fatalf("stream at %d has no end", start)
+		}
+		sb.Write(pdf[:start])
+		stream := pdf[start : start+end]
+		if r, err := zlib.NewReader(bytes.NewReader(stream)); err == nil {
+			inflated, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("failed to inflate stream at %d: %v", start, err)
+			}
+			stream = inflated
+		}
+		sb.Write(stream)
+		sb.WriteString("endstream")
+		pdf = pdf[start+end+len("endstream"):]
+	}
+}
+
+func TestGenerator_GenerateTrueTypeFont(t *testing.T) {
+	greek := textElement("greek", "Καλημέρα {{ customer.name }}")
+	greek.Style.FontFamily = "Go"
+	// Arial cannot draw Cyrillic, so the fallback font draws it
+	cyrillic := textElement("cyrillic", "Total: Привет")
+	cyrillic.Bounds.Y = 20
+
+	template := &model.Template{
+		Name:     "greeting",
+		PageSize: "A4",
+		Elements: []model.Element{greek, cyrillic},
+	}
+	gen := New(template)
+	if err := gen.Fonts().AddFile("Go", fonts.Regular, "testdata/fonts/GoSubset.ttf"); err != nil {
+		t.Fatalf("AddFile() error = %v", err)
+	}
+	gen.Fonts().SetFallbacks("Go")
+
+	buf, err := gen.Generate(context.Background(), testData())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	out := inflateStreams(t, buf.Bytes())
+	utf16 := func(s string) string {
+		var b []byte
+		for _, r := range s {
+			b = binary.BigEndian.AppendUint16(b, uint16(r))
+		}
+		return string(b)
+	}
+	for _, want := range []string{
+		"/Subtype /CIDFontType2",
+		"/FontFile2",
+		utf16("Καλημέρα John Doe"),
+		utf16("Привет"),
+		"(Total: ) Tj",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("generated PDF is missing %q", want)
+		}
+	}
+
+	// Only the glyphs the document uses are embedded
+	match := regexp.MustCompile(`/Length1 (\d+)`).FindStringSubmatch(out)
+	if match == nil {
+		t.Fatalf("generated PDF has no embedded font length")
+	}
+	info, err := os.Stat("testdata/fonts/GoSubset.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size, _ := strconv.Atoi(match[1]); size == 0 || int64(size) >= info.Size() {
+		t.Errorf("embedded font is %d bytes, want a subset of the %d byte file", size, info.Size())
+	}
+}
+
+func TestGenerator_GenerateSplitTableReadmeExample(t *testing.T) {
+	// The element from the "Tables Across Pages" README example, which
+	// relies on tables always taking the height of their rows
+	var element model.Element
+	if err := json.Unmarshal([]byte(`{
+		"id": "transactions", "type": "table",
+		"bounds": {"width": 190, "height": 40},
+		"metadata": {
+		  "headerRows": 1,
+		  "columns": [{}, {"alignment": "right", "format": "currency \"USD\""}],
+		  "subtotal": ["Carried forward", "{total}"],
+		  "carriedForward": ["Brought forward", "{total}"]
+		},
+		"content": [["Description", "Amount"],
+		            {"cells": ["{{ account.name }}", ""], "style": {"fontWeight": "bold"}, "keepWithNext": true},
+		            {"repeat": "account.transactions", "cells": ["{{ item.description }}", "{{ item.amount }}"]}]
+	}`), &element); err != nil {
+		t.Fatalf("failed to parse element: %v", err)
+	}
+
+	transactions := make([]interface{}, 200)
+	for i := range transactions {
+		transactions[i] = map[string]interface{}{"description": fmt.Sprintf("Payment %d", i+1), "amount": 5.0}
+	}
+	data := map[string]interface{}{"account": map[string]interface{}{"name": "Current account", "transactions": transactions}}
+
+	gen := New(&model.Template{Name: "statement", PageSize: "A4", Elements: []model.Element{element, textElement("closing", "End of statement")}})
+	if _, err := gen.Generate(context.Background(), data); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	pages := gen.layout.TotalPages()
+	if pages < 3 {
+		t.Fatalf("TotalPages() = %d, want the table to continue over several pages", pages)
+	}
+	rows := 0
+	for page := 1; page <= pages; page++ {
+		for _, element := range gen.layout.GetPageElements(page) {
+			if bottom := element.Bounds.Y + element.Bounds.Height; bottom > 287+1e-9 {
+				t.Errorf("page %d element %s ends at %v, below the bottom margin", page, element.ID, bottom)
+			}
+			if element.ID == "transactions" {
+				rows += len(element.Content.([]interface{})) - 1
+			}
+		}
+	}
+	// The account row and every transaction, each printed once
+	if rows != 201 {
+		t.Errorf("tables hold %d rows, want 201", rows)
+	}
+	if last := gen.layout.GetPageElements(pages); last[len(last)-1].ID != "closing" {
+		t.Errorf("last element is %s, want the text after the table", last[len(last)-1].ID)
+	}
+}
@@ -0,0 +1,372 @@
+// Package acroform adds interactive form fields to PDF documents produced by gofpdf.
+//
+// gofpdf has no support for form fields, so fields are collected while the
+// document is rendered and appended afterwards as an incremental update: the
+// original bytes are kept as-is and new objects, rewritten page and catalog
+// dictionaries and a new cross-reference section are written after them.
+package acroform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// FieldType identifies the kind of form field
+type FieldType string
+
+const (
+	FieldText      FieldType = "text"
+	FieldCheckbox  FieldType = "checkbox"
+	FieldRadio     FieldType = "radio"
+	FieldDropdown  FieldType = "dropdown"
+	FieldSignature FieldType = "signature"
+)
+
+// Field flags from the PDF specification (table 221 and following)
+const (
+	flagReadOnly  = 1 << 0
+	flagRequired  = 1 << 1
+	flagMultiline = 1 << 12
+	flagNoToggle  = 1 << 14
+	flagRadio     = 1 << 15
+	flagCombo     = 1 << 17
+)
+
+// Rect is a widget rectangle in PDF points, measured from the bottom-left page corner
+type Rect struct {
+	X1, Y1, X2, Y2 float64
+}
+
+// Color is an RGB color with components between 0 and 1
+type Color struct {
+	R, G, B float64
+}
+
+// Widget is the clickable area of a field on a page. Radio groups have one
+// widget per option; every other field type has exactly one.
+type Widget struct {
+	Page int
+	Rect Rect
+	// State is the export value of a radio option or checkbox
+	State string
+}
+
+// Field describes one form field
+type Field struct {
+	Type      FieldType
+	Name      string
+	Value     string
+	Options   []string
+	ReadOnly  bool
+	Required  bool
+	Multiline bool
+	MaxLength int
+	FontSize  float64
+	TextColor Color
+	Border    *Color
+	// BorderWidth is in points
+	BorderWidth float64
+	Background  *Color
+	Widgets     []Widget
+}
+
+// Form collects the fields of a document while it is rendered
+type Form struct {
+	fields []*Field
+	names  map[string]bool
+}
+
+// NewForm creates an empty form
+func NewForm() *Form {
+	return &Form{names: make(map[string]bool)}
+}
+
+// Add registers a field. Field names must be unique within a document.
+func (f *Form) Add(field *Field) error {
+	if field.Name == "" {
+		return fmt.Errorf("form field name is required")
+	}
+	if f.names[field.Name] {
+		return fmt.Errorf("duplicate form field name: %s", field.Name)
+	}
+	if len(field.Widgets) == 0 {
+		return fmt.Errorf("form field %s has no widgets", field.Name)
+	}
+	f.names[field.Name] = true
+	f.fields = append(f.fields, field)
+	return nil
+}
+
+// Empty reports whether no fields were added
+func (f *Form) Empty() bool {
+	return len(f.fields) == 0
+}
+
+// objectWriter allocates object numbers and buffers the new objects of an update
+type objectWriter struct {
+	next    int
+	objects map[int]string
+}
+
+func (w *objectWriter) alloc() int {
+	n := w.next
+	w.next++
+	return n
+}
+
+func (w *objectWriter) set(n int, body string) {
+	w.objects[n] = body
+}
+
+func (w *objectWriter) add(body string) int {
+	n := w.alloc()
+	w.set(n, body)
+	return n
+}
+
+func (w *objectWriter) stream(dict, content string) int {
+	return w.add(fmt.Sprintf("<<%s /Length %d>>\nstream\n%s\nendstream", dict, len(content), content))
+}
+
+// writeFields creates the objects for every field and returns the field
+// references along with the widget references to add to each page
+func (f *Form) writeFields(w *objectWriter, pageRefs []int) ([]int, map[int][]int, error) {
+	var fieldRefs []int
+	pageAnnots := make(map[int][]int)
+
+	for _, field := range f.fields {
+		for _, widget := range field.Widgets {
+			if widget.Page < 1 || widget.Page > len(pageRefs) {
+				return nil, nil, fmt.Errorf("form field %s is on page %d of %d", field.Name, widget.Page, len(pageRefs))
+			}
+		}
+
+		switch field.Type {
+		case FieldRadio:
+			parent := w.alloc()
+			var kids []string
+			for _, widget := range field.Widgets {
+				pageRef := pageRefs[widget.Page-1]
+				kid := w.add(fmt.Sprintf("<</Type /Annot /Subtype /Widget /Parent %d 0 R /P %d 0 R /Rect %s /F 4 /AS /%s%s /AP %s>>",
+					parent, pageRef, formatRect(widget.Rect), stateName(widget.State, field.Value),
+					appearanceCharacteristics(field), toggleAppearance(w, field, widget, true)))
+				kids = append(kids, fmt.Sprintf("%d 0 R", kid))
+				pageAnnots[pageRef] = append(pageAnnots[pageRef], kid)
+			}
+			value := "/Off"
+			if field.Value != "" {
+				value = "/" + pdfName(field.Value)
+			}
+			w.set(parent, fmt.Sprintf("<</FT /Btn /Ff %d /T %s /V %s /Kids [%s]>>",
+				flagRadio|flagNoToggle|field.flags(), pdfString(field.Name), value, strings.Join(kids, " ")))
+			fieldRefs = append(fieldRefs, parent)
+
+		default:
+			widget := field.Widgets[0]
+			pageRef := pageRefs[widget.Page-1]
+			dict := fmt.Sprintf("<</Type /Annot /Subtype /Widget /P %d 0 R /Rect %s /F 4 /T %s%s%s>>",
+				pageRef, formatRect(widget.Rect), pdfString(field.Name), appearanceCharacteristics(field), field.typeEntries(w, widget))
+			ref := w.add(dict)
+			fieldRefs = append(fieldRefs, ref)
+			pageAnnots[pageRef] = append(pageAnnots[pageRef], ref)
+		}
+	}
+
+	return fieldRefs, pageAnnots, nil
+}
+
+func (field *Field) flags() int {
+	flags := 0
+	if field.ReadOnly {
+		flags |= flagReadOnly
+	}
+	if field.Required {
+		flags |= flagRequired
+	}
+	return flags
+}
+
+// typeEntries returns the dictionary entries specific to the field type
+func (field *Field) typeEntries(w *objectWriter, widget Widget) string {
+	var sb strings.Builder
+
+	switch field.Type {
+	case FieldCheckbox:
+		value := "/Off"
+		if field.Value != "" {
+			value = "/Yes"
+		}
+		fmt.Fprintf(&sb, " /FT /Btn /Ff %d /V %s /AS %s /AP %s", field.flags(), value, value, toggleAppearance(w, field, widget, false))
+
+	case FieldDropdown:
+		options := make([]string, len(field.Options))
+		for i, option := range field.Options {
+			options[i] = pdfString(option)
+		}
+		fmt.Fprintf(&sb, " /FT /Ch /Ff %d /Opt [%s] /DA %s", field.flags()|flagCombo, strings.Join(options, " "), pdfString(field.defaultAppearance()))
+		if field.Value != "" {
+			fmt.Fprintf(&sb, " /V %s", pdfString(field.Value))
+		}
+
+	case FieldSignature:
+		fmt.Fprintf(&sb, " /FT /Sig /Ff %d", field.flags())
+
+	default:
+		flags := field.flags()
+		if field.Multiline {
+			flags |= flagMultiline
+		}
+		fmt.Fprintf(&sb, " /FT /Tx /Ff %d /DA %s", flags, pdfString(field.defaultAppearance()))
+		if field.Value != "" {
+			fmt.Fprintf(&sb, " /V %s", pdfString(field.Value))
+		}
+		if field.MaxLength > 0 {
+			fmt.Fprintf(&sb, " /MaxLen %d", field.MaxLength)
+		}
+	}
+
+	return sb.String()
+}
+
+// defaultAppearance is the text style viewers use when drawing the field value
+func (field *Field) defaultAppearance() string {
+	size := field.FontSize
+	if size <= 0 {
+		size = 10
+	}
+	c := field.TextColor
+	return fmt.Sprintf("/Helv %.2f Tf %.3f %.3f %.3f rg", size, c.R, c.G, c.B)
+}
+
+// appearanceCharacteristics describes the border and background of a widget
+func appearanceCharacteristics(field *Field) string {
+	var sb strings.Builder
+	if field.Border != nil {
+		width := field.BorderWidth
+		if width <= 0 {
+			width = 1
+		}
+		fmt.Fprintf(&sb, " /BS <</W %.2f /S /S>>", width)
+	}
+
+	var mk []string
+	if field.Border != nil {
+		mk = append(mk, "/BC "+formatColor(*field.Border))
+	}
+	if field.Background != nil {
+		mk = append(mk, "/BG "+formatColor(*field.Background))
+	}
+	if len(mk) > 0 {
+		fmt.Fprintf(&sb, " /MK <<%s>>", strings.Join(mk, " "))
+	}
+	return sb.String()
+}
+
+// toggleAppearance creates the on and off appearance streams of a checkbox or radio widget
+func toggleAppearance(w *objectWriter, field *Field, widget Widget, round bool) string {
+	width := widget.Rect.X2 - widget.Rect.X1
+	height := widget.Rect.Y2 - widget.Rect.Y1
+	bbox := fmt.Sprintf(" /Type /XObject /Subtype /Form /BBox [0 0 %.2f %.2f]", width, height)
+
+	var mark string
+	c := field.TextColor
+	if round {
+		// Filled dot approximated with four Bezier curves
+		r := minFloat(width, height) / 4
+		cx, cy := width/2, height/2
+		k := r * 0.5523
+		mark = fmt.Sprintf("%.3f %.3f %.3f rg %.2f %.2f m %.2f %.2f %.2f %.2f %.2f %.2f c %.2f %.2f %.2f %.2f %.2f %.2f c %.2f %.2f %.2f %.2f %.2f %.2f c %.2f %.2f %.2f %.2f %.2f %.2f c f",
+			c.R, c.G, c.B,
+			cx+r, cy,
+			cx+r, cy+k, cx+k, cy+r, cx, cy+r,
+			cx-k, cy+r, cx-r, cy+k, cx-r, cy,
+			cx-r, cy-k, cx-k, cy-r, cx, cy-r,
+			cx+k, cy-r, cx+r, cy-k, cx+r, cy)
+	} else {
+		// Check mark stroked as two line segments
+		mark = fmt.Sprintf("%.3f %.3f %.3f RG %.2f w 1 J 1 j %.2f %.2f m %.2f %.2f l %.2f %.2f l S",
+			c.R, c.G, c.B, minFloat(width, height)/8,
+			width*0.2, height*0.5, width*0.42, height*0.25, width*0.8, height*0.78)
+	}
+
+	on := w.stream(bbox, mark)
+	off := w.stream(bbox, "")
+
+	state := "Yes"
+	if round {
+		state = pdfName(widget.State)
+	}
+	return fmt.Sprintf("<</N <</%s %d 0 R /Off %d 0 R>>>>", state, on, off)
+}
+
+// stateName returns the appearance state of a radio widget for the selected value
+func stateName(state, selected string) string {
+	if state != "" && state == selected {
+		return pdfName(state)
+	}
+	return "Off"
+}
+
+func formatRect(r Rect) string {
+	return fmt.Sprintf("[%.2f %.2f %.2f %.2f]", r.X1, r.Y1, r.X2, r.Y2)
+}
+
+func formatColor(c Color) string {
+	return fmt.Sprintf("[%.3f %.3f %.3f]", c.R, c.G, c.B)
+}
+
+// pdfString encodes text as a PDF string, using UTF-16 for non-ASCII text
+func pdfString(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r > 126 || (r < 32 && r != '\n' && r != '\t') {
+			ascii = false
+			break
+		}
+	}
+
+	if ascii {
+		replacer := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`, "\n", `\n`)
+		return "(" + replacer.Replace(s) + ")"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&sb, "%04X", unit)
+	}
+	sb.WriteString(">")
+	return sb.String()
+}
+
+// pdfName encodes s as the body of a PDF name object
+func pdfName(s string) string {
+	var sb strings.Builder
+	for _, b := range []byte(s) {
+		if b < 33 || b > 126 || strings.IndexByte("#()<>[]{}/%", b) >= 0 {
+			fmt.Fprintf(&sb, "#%02X", b)
+			continue
+		}
+		sb.WriteByte(b)
+	}
+	return sb.String()
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// sortedKeys returns the keys of m in ascending order
+func sortedKeys(m map[int]string) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,105 @@
+package acroform
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+func twoPagePDF(t *testing.T) []byte {
+	t.Helper()
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddPage()
+	pdf.LinkString(10, 10, 20, 5, "https://example.com")
+	pdf.AddPage()
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestForm_Apply(t *testing.T) {
+	original := twoPagePDF(t)
+
+	form := NewForm()
+	fields := []*Field{
+		{Type: FieldText, Name: "name", Value: "Zoë (guest)", Required: true, Widgets: []Widget{{Page: 1, Rect: Rect{10, 700, 200, 720}}}},
+		{Type: FieldCheckbox, Name: "terms", Value: "Yes", Widgets: []Widget{{Page: 1, Rect: Rect{10, 650, 22, 662}}}},
+		{Type: FieldRadio, Name: "plan", Value: "Pro", Options: []string{"Basic", "Pro"}, Widgets: []Widget{
+			{Page: 2, Rect: Rect{10, 600, 22, 612}, State: "Basic"},
+			{Page: 2, Rect: Rect{10, 580, 22, 592}, State: "Pro"},
+		}},
+		{Type: FieldDropdown, Name: "country", Value: "Malawi", Options: []string{"Malawi", "Zambia"}, Widgets: []Widget{{Page: 2, Rect: Rect{10, 500, 200, 520}}}},
+		{Type: FieldSignature, Name: "signature", ReadOnly: true, Widgets: []Widget{{Page: 2, Rect: Rect{10, 400, 200, 450}}}},
+	}
+	for _, field := range fields {
+		if err := form.Add(field); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	updated, err := form.Apply(original)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if !bytes.HasPrefix(updated, original) {
+		t.Fatal("Apply() modified the original bytes instead of appending an update")
+	}
+
+	doc, err := parseDocument(updated)
+	if err != nil {
+		t.Fatalf("updated PDF cannot be parsed: %v", err)
+	}
+
+	// Every entry of the new cross-reference section must point at its object
+	for num, offset := range doc.offsets {
+		if !bytes.HasPrefix(updated[offset:], []byte(fmt.Sprintf("%d 0 obj", num))) {
+			t.Errorf("xref offset of object %d does not point at the object", num)
+		}
+	}
+
+	catalog, err := doc.object(doc.root)
+	if err != nil {
+		t.Fatalf("catalog not found in update: %v", err)
+	}
+	if !strings.Contains(catalog, "/AcroForm") || !strings.Contains(catalog, "/Type /Catalog") {
+		t.Errorf("catalog = %s, want original catalog with /AcroForm", catalog)
+	}
+
+	update := string(updated[len(original):])
+	for _, want := range []string{
+		"/T (name)", "/V <FEFF005A006F00EB", "/Ff 2",
+		"/T (terms)", "/AS /Yes",
+		"/T (plan)", "/V /Pro", "/Kids [",
+		"/T (country)", "/Opt [(Malawi) (Zambia)]",
+		"/FT /Sig /Ff 1",
+		"/Annots [",
+		"/Prev ",
+	} {
+		if !strings.Contains(update, want) {
+			t.Errorf("update is missing %q", want)
+		}
+	}
+
+	// The page with an existing link keeps it alongside the new widgets
+	if strings.Count(update, "/Subtype /Link") != 1 {
+		t.Error("existing link annotation was not preserved")
+	}
+}
+
+func TestForm_AddRejectsDuplicateNames(t *testing.T) {
+	form := NewForm()
+	field := &Field{Type: FieldText, Name: "email", Widgets: []Widget{{Page: 1}}}
+	if err := form.Add(field); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := form.Add(field); err == nil {
+		t.Error("Add() error = nil, want duplicate name error")
+	}
+}
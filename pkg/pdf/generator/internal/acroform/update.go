@@ -0,0 +1,230 @@
+package acroform
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	startXrefPattern = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+	trailerPattern   = regexp.MustCompile(`/(Size|Root|Info)\s+(\d+)`)
+	kidsPattern      = regexp.MustCompile(`/Kids\s*\[([^\]]*)\]`)
+	refPattern       = regexp.MustCompile(`(\d+)\s+0\s+R`)
+	pagesRefPattern  = regexp.MustCompile(`/Pages\s+(\d+)\s+0\s+R`)
+)
+
+// document is the minimal view of an existing PDF needed to append an update
+type document struct {
+	data      []byte
+	offsets   map[int]int
+	startXref int
+	size      int
+	root      int
+	info      int
+}
+
+// Apply appends the form fields to a PDF as an incremental update. The PDF
+// must use a classic cross-reference table, as gofpdf writes.
+func (f *Form) Apply(pdf []byte) ([]byte, error) {
+	if f.Empty() {
+		return pdf, nil
+	}
+
+	doc, err := parseDocument(pdf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF structure: %w", err)
+	}
+
+	catalog, err := doc.object(doc.root)
+	if err != nil {
+		return nil, err
+	}
+	pages := pagesRefPattern.FindStringSubmatch(catalog)
+	if pages == nil {
+		return nil, fmt.Errorf("catalog has no page tree")
+	}
+	pagesNum, _ := strconv.Atoi(pages[1])
+	pageRefs, err := doc.pageRefs(pagesNum)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &objectWriter{next: doc.size, objects: make(map[int]string)}
+
+	font := w.add("<</Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding>>")
+	fieldRefs, pageAnnots, err := f.writeFields(w, pageRefs)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]string, len(fieldRefs))
+	for i, ref := range fieldRefs {
+		refs[i] = fmt.Sprintf("%d 0 R", ref)
+	}
+	acroForm := w.add(fmt.Sprintf("<</Fields [%s] /NeedAppearances true /DR <</Font <</Helv %d 0 R>>>> /DA (/Helv 10 Tf 0 g)>>",
+		strings.Join(refs, " "), font))
+
+	// Rewrite the catalog and the pages that gained widgets
+	w.set(doc.root, insertEntry(catalog, fmt.Sprintf("/AcroForm %d 0 R", acroForm)))
+	for pageRef, annots := range pageAnnots {
+		page, err := doc.object(pageRef)
+		if err != nil {
+			return nil, err
+		}
+		w.set(pageRef, addAnnots(page, annots))
+	}
+
+	return doc.appendUpdate(w), nil
+}
+
+func parseDocument(data []byte) (*document, error) {
+	match := startXrefPattern.FindSubmatch(data)
+	if match == nil {
+		return nil, fmt.Errorf("startxref not found")
+	}
+	startXref, _ := strconv.Atoi(string(match[1]))
+	if startXref >= len(data) || !bytes.HasPrefix(data[startXref:], []byte("xref")) {
+		return nil, fmt.Errorf("cross-reference table not found at offset %d", startXref)
+	}
+
+	doc := &document{data: data, offsets: make(map[int]int), startXref: startXref}
+
+	trailerStart := bytes.Index(data[startXref:], []byte("trailer"))
+	if trailerStart < 0 {
+		return nil, fmt.Errorf("trailer not found")
+	}
+	table := string(data[startXref+len("xref") : startXref+trailerStart])
+	trailer := string(data[startXref+trailerStart:])
+
+	for _, m := range trailerPattern.FindAllStringSubmatch(trailer, -1) {
+		n, _ := strconv.Atoi(m[2])
+		switch m[1] {
+		case "Size":
+			doc.size = n
+		case "Root":
+			doc.root = n
+		case "Info":
+			doc.info = n
+		}
+	}
+	if doc.size == 0 || doc.root == 0 {
+		return nil, fmt.Errorf("trailer is missing /Size or /Root")
+	}
+
+	// Each subsection starts with "first count" followed by count 20-byte entries
+	lines := strings.Split(strings.TrimSpace(table), "\n")
+	for i := 0; i < len(lines); {
+		var first, count int
+		if _, err := fmt.Sscanf(strings.TrimSpace(lines[i]), "%d %d", &first, &count); err != nil {
+			return nil, fmt.Errorf("invalid cross-reference subsection %q", lines[i])
+		}
+		i++
+		for j := 0; j < count && i < len(lines); j, i = j+1, i+1 {
+			fields := strings.Fields(lines[i])
+			if len(fields) == 3 && fields[2] == "n" {
+				offset, _ := strconv.Atoi(fields[0])
+				doc.offsets[first+j] = offset
+			}
+		}
+	}
+
+	return doc, nil
+}
+
+// object returns the dictionary of an object without its "n 0 obj" wrapper
+func (d *document) object(num int) (string, error) {
+	offset, ok := d.offsets[num]
+	if !ok {
+		return "", fmt.Errorf("object %d not found", num)
+	}
+
+	rest := d.data[offset:]
+	start := bytes.Index(rest, []byte("obj"))
+	end := bytes.Index(rest, []byte("endobj"))
+	if start < 0 || end < start {
+		return "", fmt.Errorf("object %d is malformed", num)
+	}
+	return strings.TrimSpace(string(rest[start+len("obj") : end])), nil
+}
+
+// pageRefs lists page object numbers in document order
+func (d *document) pageRefs(pagesNum int) ([]int, error) {
+	pages, err := d.object(pagesNum)
+	if err != nil {
+		return nil, err
+	}
+
+	kids := kidsPattern.FindStringSubmatch(pages)
+	if kids == nil {
+		return nil, fmt.Errorf("page tree has no kids")
+	}
+
+	var refs []int
+	for _, m := range refPattern.FindAllStringSubmatch(kids[1], -1) {
+		n, _ := strconv.Atoi(m[1])
+		refs = append(refs, n)
+	}
+	return refs, nil
+}
+
+// appendUpdate writes the buffered objects, a cross-reference section and a
+// trailer pointing back at the original one
+func (d *document) appendUpdate(w *objectWriter) []byte {
+	var buf bytes.Buffer
+	buf.Write(d.data)
+	if !bytes.HasSuffix(d.data, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	nums := sortedKeys(w.objects)
+	offsets := make(map[int]int, len(nums))
+	for _, n := range nums {
+		offsets[n] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", n, w.objects[n])
+	}
+
+	xref := buf.Len()
+	buf.WriteString("xref\n")
+	for i := 0; i < len(nums); {
+		// Group consecutive object numbers into subsections
+		j := i
+		for j+1 < len(nums) && nums[j+1] == nums[j]+1 {
+			j++
+		}
+		fmt.Fprintf(&buf, "%d %d\n", nums[i], j-i+1)
+		for _, n := range nums[i : j+1] {
+			fmt.Fprintf(&buf, "%010d 00000 n \n", offsets[n])
+		}
+		i = j + 1
+	}
+
+	fmt.Fprintf(&buf, "trailer\n<<\n/Size %d\n/Root %d 0 R\n", w.next, d.root)
+	if d.info != 0 {
+		fmt.Fprintf(&buf, "/Info %d 0 R\n", d.info)
+	}
+	fmt.Fprintf(&buf, "/Prev %d\n>>\nstartxref\n%d\n%%%%EOF\n", d.startXref, xref)
+	return buf.Bytes()
+}
+
+// insertEntry adds an entry at the start of a dictionary
+func insertEntry(dict, entry string) string {
+	return "<<" + entry + "\n" + strings.TrimPrefix(dict, "<<")
+}
+
+// addAnnots appends widget references to a page's /Annots array, creating it if needed
+func addAnnots(page string, annots []int) string {
+	refs := make([]string, len(annots))
+	for i, ref := range annots {
+		refs[i] = fmt.Sprintf("%d 0 R", ref)
+	}
+	joined := strings.Join(refs, " ")
+
+	if i := strings.Index(page, "/Annots ["); i >= 0 {
+		at := i + len("/Annots [")
+		return page[:at] + joined + " " + page[at:]
+	}
+	return insertEntry(page, "/Annots ["+joined+"]")
+}
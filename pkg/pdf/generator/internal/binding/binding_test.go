@@ -0,0 +1,343 @@
+package binding
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+func testScope(t *testing.T) *Scope {
+	t.Helper()
+	scope, err := NewScope(map[string]interface{}{
+		"customer": map[string]interface{}{
+			"name":  "john doe",
+			"email": "john@example.com",
+		},
+		"order": map[string]interface{}{
+			"id":     "ORD-12345",
+			"date":   "2024-03-15",
+			"status": "Completed",
+			"total":  1234.5,
+			"items": []interface{}{
+				map[string]interface{}{"name": "Product A", "quantity": 2},
+				map[string]interface{}{"name": "Product B", "quantity": 1},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewScope() error = %v", err)
+	}
+	return scope
+}
+
+func TestInterpolate(t *testing.T) {
+	scope := testScope(t)
+
+	tests := []struct {
+		name    string
+		input   string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "static text", input: "Invoice", want: "Invoice"},
+		{name: "path", input: "{{ customer.name }}", want: "john doe"},
+		{name: "embedded path", input: "Order {{order.id}} for {{ customer.email }}", want: "Order ORD-12345 for john@example.com"},
+		{name: "array index", input: "{{ order.items[1].name }}", want: "Product B"},
+		{name: "dotted index", input: "{{ order.items.0.quantity }}", want: float64(2)},
+		{name: "raw number", input: "{{ order.total }}", want: 1234.5},
+		{name: "upper filter", input: "{{ customer.name | upper }}", want: "JOHN DOE"},
+		{name: "chained filters", input: "{{ customer.name | title | truncate 4 }}", want: "John..."},
+		{name: "currency filter", input: "Total: {{ order.total | currency \"USD\" }}", want: "Total: $1,234.50"},
+		{name: "unknown currency", input: "{{ order.total | currency 'CHF' }}", want: "CHF 1,234.50"},
+		{name: "number filter", input: "{{ order.total | number 0 }}", want: "1,235"},
+		{name: "date filter", input: "{{ order.date | date \"02 Jan 2006\" }}", want: "15 Mar 2024"},
+		{name: "missing value", input: "[{{ customer.phone }}]", want: "[]"},
+		{name: "default filter", input: "{{ customer.phone | default \"N/A\" }}", want: "N/A"},
+		{name: "unknown filter", input: "{{ customer.name | shout }}", wantErr: true},
+		{name: "unterminated binding", input: "Hello {{ customer.name", wantErr: true},
+		{name: "bad date", input: "{{ order.id | date }}", wantErr: true},
+		{name: "truncate past the int range", input: "{{ customer.name | truncate 100000000000000000000 }}", want: "john doe"},
+		{name: "negative truncate", input: "{{ customer.name | truncate -1 }}", wantErr: true},
+		{name: "fractional truncate", input: "{{ customer.name | truncate 2.5 }}", wantErr: true},
+		{name: "truncate bound from data", input: "{{ customer.name | truncate order.items[0].quantity }}", want: "jo..."},
+		{name: "fractional truncate from data", input: "{{ customer.name | truncate order.total }}", wantErr: true},
+		{name: "NaN truncate", input: "{{ customer.name | truncate 'NaN' }}", wantErr: true},
+		{name: "truncate not a number", input: "{{ customer.name | truncate 'all' }}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Interpolate(tt.input, scope)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Interpolate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Interpolate() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterFilterReplacesParsedExpressions(t *testing.T) {
+	scope := testScope(t)
+	const input = "{{ customer.name | shout }}"
+
+	RegisterFilter("shout", func(value interface{}, args ...interface{}) (interface{}, error) {
+		return "one", nil
+	})
+	defer func() {
+		filtersMu.Lock()
+		defer filtersMu.Unlock()
+		delete(filters, "shout")
+	}()
+	if got, err := Interpolate(input, scope); err != nil || got != "one" {
+		t.Fatalf("Interpolate() = %v, %v, want one", got, err)
+	}
+
+	RegisterFilter("shout", func(value interface{}, args ...interface{}) (interface{}, error) {
+		return "two", nil
+	})
+	if got, err := Interpolate(input, scope); err != nil || got != "two" {
+		t.Errorf("Interpolate() after replacing the filter = %v, %v, want two", got, err)
+	}
+}
+
+func TestResolveElements(t *testing.T) {
+	scope := testScope(t)
+	elements := []model.Element{
+		{ID: "title", Type: model.ElementTypeText, Content: "Invoice {{ order.id }}"},
+		{ID: "total", Type: model.ElementTypeText, Content: "{{ order.total }}"},
+		{ID: "items", Type: model.ElementTypeTable, Content: []interface{}{
+			[]interface{}{"Item", "Qty"},
+			[]interface{}{"{{ order.items[0].name }}", "{{ order.items[0].quantity }}"},
+		}},
+	}
+
+	resolved, err := ResolveElements(elements, scope)
+	if err != nil {
+		t.Fatalf("ResolveElements() error = %v", err)
+	}
+
+	if got := resolved[0].Content; got != "Invoice ORD-12345" {
+		t.Errorf("title content = %v", got)
+	}
+	if got := resolved[1].Content; got != "1234.5" {
+		t.Errorf("total content = %#v, want text", got)
+	}
+	wantTable := []interface{}{
+		[]interface{}{"Item", "Qty"},
+		[]interface{}{"Product A", float64(2)},
+	}
+	if got := resolved[2].Content; !reflect.DeepEqual(got, wantTable) {
+		t.Errorf("table content = %#v, want %#v", got, wantTable)
+	}
+	if elements[0].Content != "Invoice {{ order.id }}" {
+		t.Errorf("template element was modified: %v", elements[0].Content)
+	}
+}
+
+func TestResolveElementsTextRuns(t *testing.T) {
+	elements := []model.Element{{ID: "greeting", Type: model.ElementTypeText, Content: []interface{}{
+		"Dear ",
+		map[string]interface{}{"text": "{{ customer.name | title }}", "bold": true},
+		", you owe ",
+		map[string]interface{}{"text": "{{ order.total }}", "color": "#ff0000"},
+		float64(3),
+	}}}
+
+	resolved, err := ResolveElements(elements, testScope(t))
+	if err != nil {
+		t.Fatalf("ResolveElements() error = %v", err)
+	}
+	want := []interface{}{
+		"Dear ",
+		map[string]interface{}{"text": "John Doe", "bold": true},
+		", you owe ",
+		map[string]interface{}{"text": "1234.5", "color": "#ff0000"},
+		"3",
+	}
+	if got := resolved[0].Content; !reflect.DeepEqual(got, want) {
+		t.Errorf("runs = %#v, want %#v", got, want)
+	}
+	if run := elements[0].Content.([]interface{})[1].(map[string]interface{}); run["text"] != "{{ customer.name | title }}" {
+		t.Errorf("template run was modified: %v", run)
+	}
+}
+
+func TestResolveElementsRepeat(t *testing.T) {
+	scope := testScope(t)
+	elements := []model.Element{
+		{
+			ID:       "line",
+			Type:     model.ElementTypeGroup,
+			Repeat:   "order.items",
+			RepeatAs: "line",
+			Children: []model.Element{
+				{ID: "name", Type: model.ElementTypeText, Content: "{{ loop.number }}. {{ line.name }} ({{ parent.order.id }})"},
+				{ID: "qty", Type: model.ElementTypeText, Content: "{{ line.quantity }} x {{ customer.name }}"},
+			},
+		},
+		{
+			ID:      "tag",
+			Type:    model.ElementTypeText,
+			Repeat:  "order.items",
+			Content: "#{{ index }} {{ item.name }}",
+		},
+		{
+			ID:      "missing",
+			Type:    model.ElementTypeText,
+			Repeat:  "order.refunds",
+			Content: "never rendered",
+		},
+		{ID: "items", Type: model.ElementTypeTable, Content: []interface{}{
+			[]interface{}{"Item", "Qty"},
+			map[string]interface{}{
+				"repeat": "order.items",
+				"cells":  []interface{}{"{{ item.name }}", "{{ item.quantity }}"},
+			},
+		}},
+	}
+
+	resolved, err := ResolveElements(elements, scope)
+	if err != nil {
+		t.Fatalf("ResolveElements() error = %v", err)
+	}
+
+	want := []struct{ id, content string }{
+		{"name-0", "1. Product A (ORD-12345)"},
+		{"qty-0", "2 x john doe"},
+		{"name-1", "2. Product B (ORD-12345)"},
+		{"qty-1", "1 x john doe"},
+		{"tag-0", "#0 Product A"},
+		{"tag-1", "#1 Product B"},
+	}
+	if len(resolved) != len(want)+1 {
+		t.Fatalf("ResolveElements() returned %d elements, want %d", len(resolved), len(want)+1)
+	}
+	for i, w := range want {
+		if resolved[i].ID != w.id || resolved[i].Content != w.content {
+			t.Errorf("element %d = %s %q, want %s %q", i, resolved[i].ID, resolved[i].Content, w.id, w.content)
+		}
+	}
+
+	wantTable := []interface{}{
+		[]interface{}{"Item", "Qty"},
+		[]interface{}{"Product A", float64(2)},
+		[]interface{}{"Product B", float64(1)},
+	}
+	if got := resolved[len(want)].Content; !reflect.DeepEqual(got, wantTable) {
+		t.Errorf("table content = %#v, want %#v", got, wantTable)
+	}
+}
+
+func TestResolveElementsRepeatNotArray(t *testing.T) {
+	elements := []model.Element{{ID: "bad", Type: model.ElementTypeText, Repeat: "customer.name"}}
+	if _, err := ResolveElements(elements, testScope(t)); err == nil {
+		t.Error("ResolveElements() error = nil, want error for non-array repeat")
+	}
+}
+
+func TestExpressionTest(t *testing.T) {
+	scope := testScope(t)
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"order.status == 'Completed'", true},
+		{"order.status == 'Refunded'", false},
+		{"order.status != \"Refunded\"", true},
+		{"order.total > 1000", true},
+		{"order.total <= 1000", false},
+		{"order.items | length >= 2", true},
+		{"order.discount", false},
+		{"!order.discount", true},
+		{"not order.discount and customer.email", true},
+		{"order.discount || order.status == 'Completed'", true},
+		{"(order.total > 10 && order.status == 'Pending') || customer.name | upper == 'JOHN DOE'", true},
+		{"order.items", true},
+		{"customer.phone == null", true},
+		{"order.discount > 0", false},
+		{"order.discount <= 0", false},
+		{"0 >= order.discount", false},
+		{"not (order.discount > 0)", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got, err := expr.Test(scope)
+			if err != nil {
+				t.Fatalf("Test() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Test() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveElementsConditions(t *testing.T) {
+	scope := testScope(t)
+	elements := []model.Element{
+		{ID: "paid", Type: model.ElementTypeText, If: "order.status == 'Completed'", Content: "PAID"},
+		{ID: "refunded", Type: model.ElementTypeText, If: "order.status == 'Refunded'", Content: "REFUNDED"},
+		{ID: "bulk", Type: model.ElementTypeText, Repeat: "order.items", If: "item.quantity > 1", Content: "{{ item.name }}"},
+		{ID: "items", Type: model.ElementTypeTable, Content: []interface{}{
+			[]interface{}{"Item", "Amount"},
+			map[string]interface{}{"if": "order.discount", "cells": []interface{}{"Discount", "{{ order.discount }}"}},
+			map[string]interface{}{"if": "order.total > 0", "cells": []interface{}{"Total", "{{ order.total }}"}},
+		}},
+	}
+
+	resolved, err := ResolveElements(elements, scope)
+	if err != nil {
+		t.Fatalf("ResolveElements() error = %v", err)
+	}
+
+	var ids []string
+	for _, element := range resolved {
+		ids = append(ids, element.ID)
+	}
+	if want := []string{"paid", "bulk-0", "items"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("resolved IDs = %v, want %v", ids, want)
+	}
+
+	wantTable := []interface{}{
+		[]interface{}{"Item", "Amount"},
+		[]interface{}{"Total", 1234.5},
+	}
+	if got := resolved[2].Content; !reflect.DeepEqual(got, wantTable) {
+		t.Errorf("table content = %#v, want %#v", got, wantTable)
+	}
+}
+
+func TestResolveElementsRowFields(t *testing.T) {
+	scope := testScope(t)
+	elements := []model.Element{{ID: "items", Type: model.ElementTypeTable, Content: []interface{}{
+		map[string]interface{}{
+			"repeat":       "order.items",
+			"as":           "line",
+			"cells":        []interface{}{"{{ line.name }}"},
+			"style":        map[string]interface{}{"background": "{{ order.status | lower }}"},
+			"keepWithNext": true,
+		},
+	}}}
+
+	resolved, err := ResolveElements(elements, scope)
+	if err != nil {
+		t.Fatalf("ResolveElements() error = %v", err)
+	}
+
+	want := []interface{}{
+		map[string]interface{}{"cells": []interface{}{"Product A"}, "style": map[string]interface{}{"background": "completed"}, "keepWithNext": true},
+		map[string]interface{}{"cells": []interface{}{"Product B"}, "style": map[string]interface{}{"background": "completed"}, "keepWithNext": true},
+	}
+	if got := resolved[0].Content; !reflect.DeepEqual(got, want) {
+		t.Errorf("table content = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,649 @@
+// Package binding resolves data binding expressions in template elements
+package binding
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Expression is a parsed binding expression such as `customer.name | upper`
+// or `order.status == 'Refunded' && order.total > 0`
+type Expression struct {
+	source string
+	root   node
+}
+
+// expressionCache avoids re-parsing the same template expressions for every payload
+var expressionCache sync.Map
+
+// Parse compiles an expression, reusing previously parsed expressions
+func Parse(source string) (*Expression, error) {
+	if cached, ok := expressionCache.Load(source); ok {
+		return cached.(*Expression), nil
+	}
+
+	p := &parser{lexer: newLexer(source)}
+	if err := p.advance(); err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, fmt.Errorf("invalid expression %q: unexpected %q", source, p.tok.text)
+	}
+
+	expr := &Expression{source: strings.TrimSpace(source), root: root}
+	expressionCache.Store(source, expr)
+	return expr, nil
+}
+
+// Test evaluates the expression as a condition
+func (e *Expression) Test(scope *Scope) (bool, error) {
+	value, err := e.Eval(scope)
+	if err != nil {
+		return false, err
+	}
+	return Truthy(value), nil
+}
+
+// Eval evaluates the expression against a scope
+func (e *Expression) Eval(scope *Scope) (interface{}, error) {
+	value, err := e.root.eval(scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %q: %w", e.source, err)
+	}
+	return value, nil
+}
+
+// String returns the expression source
+func (e *Expression) String() string {
+	return e.source
+}
+
+// node is an evaluable part of an expression tree
+type node interface {
+	eval(scope *Scope) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(scope *Scope) (interface{}, error) {
+	return n.value, nil
+}
+
+// pathNode looks up a dotted path such as order.items[0].name
+type pathNode struct {
+	name     string
+	segments []interface{} // string keys or int indices
+}
+
+func (n *pathNode) eval(scope *Scope) (interface{}, error) {
+	value, ok := scope.Lookup(n.name)
+	if !ok {
+		return nil, nil
+	}
+	for _, segment := range n.segments {
+		value = index(value, segment)
+		if value == nil {
+			return nil, nil
+		}
+	}
+	return value, nil
+}
+
+// filterNode applies a named filter to the result of its input. The filter
+// is looked up when the node is evaluated, so replacing a registered filter
+// also affects expressions that were parsed and cached before.
+type filterNode struct {
+	input node
+	name  string
+	args  []node
+}
+
+func (n *filterNode) eval(scope *Scope) (interface{}, error) {
+	value, err := n.input.eval(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		if args[i], err = arg.eval(scope); err != nil {
+			return nil, err
+		}
+	}
+
+	filter, ok := lookupFilter(n.name)
+	if !ok {
+		return nil, fmt.Errorf("unknown filter %q", n.name)
+	}
+	result, err := filter(value, args...)
+	if err != nil {
+		return nil, fmt.Errorf("filter %s: %w", n.name, err)
+	}
+	return result, nil
+}
+
+// notNode negates the truthiness of its operand
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) eval(scope *Scope) (interface{}, error) {
+	value, err := n.operand.eval(scope)
+	if err != nil {
+		return nil, err
+	}
+	return !Truthy(value), nil
+}
+
+// logicalNode implements short-circuit && and ||
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n *logicalNode) eval(scope *Scope) (interface{}, error) {
+	left, err := n.left.eval(scope)
+	if err != nil {
+		return nil, err
+	}
+	if Truthy(left) == (n.op == "||") {
+		return Truthy(left), nil
+	}
+
+	right, err := n.right.eval(scope)
+	if err != nil {
+		return nil, err
+	}
+	return Truthy(right), nil
+}
+
+// compareNode implements ==, !=, <, <=, > and >=
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n *compareNode) eval(scope *Scope) (interface{}, error) {
+	left, err := n.left.eval(scope)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	}
+	// A missing value is neither less nor greater than anything, so
+	// conditions on optional fields are false rather than failing
+	if left == nil || right == nil {
+		return false, nil
+	}
+
+	cmp, err := compare(left, right)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+// equal compares numbers numerically and everything else by value
+func equal(a, b interface{}) bool {
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			return x == y
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compare orders two numbers or two strings
+func compare(a, b interface{}) (int, error) {
+	switch x := a.(type) {
+	case float64:
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1, nil
+			case x > y:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %T with %T", a, b)
+}
+
+// Truthy reports whether a value counts as true in a condition. Missing
+// values, false, zero, empty strings and empty collections are false.
+func Truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+// index returns the child of value addressed by a key or array index
+func index(value interface{}, segment interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch key := segment.(type) {
+		case string:
+			return v[key]
+		case int:
+			return v[strconv.Itoa(key)]
+		}
+	case []interface{}:
+		i, ok := segment.(int)
+		if !ok {
+			parsed, err := strconv.Atoi(fmt.Sprint(segment))
+			if err != nil {
+				if segment == "length" {
+					return float64(len(v))
+				}
+				return nil
+			}
+			i = parsed
+		}
+		if i < 0 {
+			i += len(v)
+		}
+		if i >= 0 && i < len(v) {
+			return v[i]
+		}
+	case string:
+		if segment == "length" {
+			return float64(len([]rune(v)))
+		}
+	}
+	return nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// lexer splits an expression into tokens
+type lexer struct {
+	input []rune
+	pos   int
+}
+
+func newLexer(input string) *lexer {
+	return &lexer{input: []rune(input)}
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) && unicode.IsSpace(l.input[l.pos]) {
+		l.pos++
+	}
+	if l.pos >= len(l.input) {
+		return token{kind: tokenEOF}, nil
+	}
+
+	start := l.pos
+	r := l.input[l.pos]
+
+	switch {
+	case isIdentStart(r):
+		for l.pos < len(l.input) && isIdentPart(l.input[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokenIdent, text: string(l.input[start:l.pos])}, nil
+
+	case unicode.IsDigit(r) || (r == '-' && l.pos+1 < len(l.input) && unicode.IsDigit(l.input[l.pos+1])):
+		l.pos++
+		for l.pos < len(l.input) {
+			c := l.input[l.pos]
+			// A dot only continues the number when a digit follows, so items.0.name stays a path
+			if !unicode.IsDigit(c) && (c != '.' || l.pos+1 >= len(l.input) || !unicode.IsDigit(l.input[l.pos+1])) {
+				break
+			}
+			l.pos++
+		}
+		return token{kind: tokenNumber, text: string(l.input[start:l.pos])}, nil
+
+	case r == '"' || r == '\'':
+		var sb strings.Builder
+		l.pos++
+		for l.pos < len(l.input) && l.input[l.pos] != r {
+			if l.input[l.pos] == '\\' && l.pos+1 < len(l.input) {
+				l.pos++
+			}
+			sb.WriteRune(l.input[l.pos])
+			l.pos++
+		}
+		if l.pos >= len(l.input) {
+			return token{}, fmt.Errorf("unterminated string literal")
+		}
+		l.pos++
+		return token{kind: tokenString, text: sb.String()}, nil
+	}
+
+	for _, op := range operators {
+		if strings.HasPrefix(string(l.input[l.pos:]), op) {
+			l.pos += len([]rune(op))
+			return token{kind: tokenPunct, text: op}, nil
+		}
+	}
+
+	return token{}, fmt.Errorf("unexpected character %q", r)
+}
+
+// operators lists punctuation tokens, longest first so prefixes do not shadow them
+var operators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", ".", "[", "]", "|", "(", ")"}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_' || r == '$'
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r)
+}
+
+// parser builds an expression tree using recursive descent
+type parser struct {
+	lexer *lexer
+	tok   token
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) expect(text string) error {
+	if p.tok.kind != tokenPunct || p.tok.text != text {
+		return fmt.Errorf("expected %q, got %q", text, p.tok.text)
+	}
+	return p.advance()
+}
+
+func (p *parser) isPunct(text string) bool {
+	return p.tok.kind == tokenPunct && p.tok.text == text
+}
+
+// parseExpression parses `a || b`, the lowest precedence level
+func (p *parser) parseExpression() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("||") || p.isKeyword("or") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses `a && b`
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("&&") || p.isKeyword("and") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseNot parses `!a`
+func (p *parser) parseNot() (node, error) {
+	if p.isPunct("!") || p.isKeyword("not") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses `a == b`; filters bind tighter, so
+// `order.items | length > 0` compares the filtered value
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePipeline()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.isPunct(op) {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			right, err := p.parsePipeline()
+			if err != nil {
+				return nil, err
+			}
+			return &compareNode{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) isKeyword(word string) bool {
+	return p.tok.kind == tokenIdent && p.tok.text == word
+}
+
+// parsePipeline parses `operand | filter arg... | filter ...`
+func (p *parser) parsePipeline() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isPunct("|") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenIdent {
+			return nil, fmt.Errorf("expected filter name after |, got %q", p.tok.text)
+		}
+
+		name := p.tok.text
+		if _, ok := lookupFilter(name); !ok {
+			return nil, fmt.Errorf("unknown filter %q", name)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		f := &filterNode{input: n, name: name}
+		for p.startsPrimary() {
+			arg, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			f.args = append(f.args, arg)
+		}
+		n = f
+	}
+
+	return n, nil
+}
+
+func (p *parser) startsPrimary() bool {
+	switch p.tok.kind {
+	case tokenIdent:
+		return !p.isKeyword("and") && !p.isKeyword("or") && !p.isKeyword("not")
+	case tokenNumber, tokenString:
+		return true
+	case tokenPunct:
+		return p.tok.text == "("
+	}
+	return false
+}
+
+// parsePrimary parses a literal, a path or a parenthesized pipeline
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.tok
+
+	switch tok.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok.text)
+		}
+		return &literalNode{value: value}, p.advance()
+
+	case tokenString:
+		return &literalNode{value: tok.text}, p.advance()
+
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, p.advance()
+		case "false":
+			return &literalNode{value: false}, p.advance()
+		case "null", "nil":
+			return &literalNode{value: nil}, p.advance()
+		}
+		return p.parsePath()
+
+	case tokenPunct:
+		if tok.text == "(" {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			n, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		}
+	}
+
+	if tok.kind == tokenEOF {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q", tok.text)
+}
+
+func (p *parser) parsePath() (node, error) {
+	n := &pathNode{name: p.tok.text}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.isPunct("."):
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			switch p.tok.kind {
+			case tokenIdent:
+				n.segments = append(n.segments, p.tok.text)
+			case tokenNumber:
+				i, err := strconv.Atoi(p.tok.text)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %q", p.tok.text)
+				}
+				n.segments = append(n.segments, i)
+			default:
+				return nil, fmt.Errorf("expected field name after '.', got %q", p.tok.text)
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+
+		case p.isPunct("["):
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			switch p.tok.kind {
+			case tokenNumber:
+				i, err := strconv.Atoi(p.tok.text)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %q", p.tok.text)
+				}
+				n.segments = append(n.segments, i)
+			case tokenString:
+				n.segments = append(n.segments, p.tok.text)
+			default:
+				return nil, fmt.Errorf("expected index or key inside [], got %q", p.tok.text)
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+
+		default:
+			return n, nil
+		}
+	}
+}
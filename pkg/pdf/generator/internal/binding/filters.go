@@ -0,0 +1,276 @@
+package binding
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// FilterFunc transforms a bound value, optionally using filter arguments
+type FilterFunc func(value interface{}, args ...interface{}) (interface{}, error)
+
+var (
+	filtersMu sync.RWMutex
+	filters   = map[string]FilterFunc{
+		"upper":    stringFilter(strings.ToUpper),
+		"lower":    stringFilter(strings.ToLower),
+		"trim":     stringFilter(strings.TrimSpace),
+		"title":    stringFilter(titleCase),
+		"default":  defaultFilter,
+		"number":   numberFilter,
+		"currency": currencyFilter,
+		"date":     dateFilter,
+		"truncate": truncateFilter,
+		"length":   lengthFilter,
+	}
+)
+
+// RegisterFilter adds or replaces a named filter
+func RegisterFilter(name string, filter FilterFunc) {
+	filtersMu.Lock()
+	defer filtersMu.Unlock()
+	filters[name] = filter
+}
+
+func lookupFilter(name string) (FilterFunc, bool) {
+	filtersMu.RLock()
+	defer filtersMu.RUnlock()
+	filter, ok := filters[name]
+	return filter, ok
+}
+
+// currencySymbols maps ISO 4217 codes to the symbol printed before amounts
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"MWK": "MK",
+	"ZAR": "R",
+	"KES": "KSh",
+}
+
+// dateLayouts are tried in order when a date filter receives a string
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+func stringFilter(fn func(string) string) FilterFunc {
+	return func(value interface{}, args ...interface{}) (interface{}, error) {
+		return fn(Stringify(value)), nil
+	}
+}
+
+func titleCase(s string) string {
+	runes := []rune(s)
+	start := true
+	for i, r := range runes {
+		if unicode.IsSpace(r) {
+			start = true
+			continue
+		}
+		if start {
+			runes[i] = unicode.ToUpper(r)
+			start = false
+		}
+	}
+	return string(runes)
+}
+
+// defaultFilter substitutes its argument for missing or empty values
+func defaultFilter(value interface{}, args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+	}
+	if value == nil || value == "" {
+		return args[0], nil
+	}
+	return value, nil
+}
+
+// numberFilter formats a number with thousands separators and a fixed number of decimals
+func numberFilter(value interface{}, args ...interface{}) (interface{}, error) {
+	n, ok := ToNumber(value)
+	if !ok {
+		return nil, fmt.Errorf("value %v is not a number", value)
+	}
+
+	decimals := 2
+	if len(args) > 0 {
+		d, ok := ToNumber(args[0])
+		if !ok {
+			return nil, fmt.Errorf("decimals must be a number, got %v", args[0])
+		}
+		decimals = int(d)
+	}
+	return formatNumber(n, decimals), nil
+}
+
+// currencyFilter formats a number as an amount in the given ISO currency
+func currencyFilter(value interface{}, args ...interface{}) (interface{}, error) {
+	n, ok := ToNumber(value)
+	if !ok {
+		return nil, fmt.Errorf("value %v is not a number", value)
+	}
+
+	code := "USD"
+	if len(args) > 0 {
+		code = strings.ToUpper(Stringify(args[0]))
+	}
+
+	decimals := 2
+	if code == "JPY" {
+		decimals = 0
+	}
+
+	amount := formatNumber(math.Abs(n), decimals)
+	sign := ""
+	if n < 0 {
+		sign = "-"
+	}
+
+	if symbol, ok := currencySymbols[code]; ok {
+		return sign + symbol + amount, nil
+	}
+	return sign + code + " " + amount, nil
+}
+
+// dateFilter formats a date string, time.Time or unix timestamp using a Go layout
+func dateFilter(value interface{}, args ...interface{}) (interface{}, error) {
+	if value == nil || value == "" {
+		return "", nil
+	}
+
+	layout := "2006-01-02"
+	if len(args) > 0 {
+		layout = Stringify(args[0])
+	}
+
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case float64:
+		t = time.Unix(int64(v), 0).UTC()
+	case string:
+		parsed := false
+		for _, l := range dateLayouts {
+			if p, err := time.Parse(l, v); err == nil {
+				t, parsed = p, true
+				break
+			}
+		}
+		if !parsed {
+			return nil, fmt.Errorf("cannot parse %q as a date", v)
+		}
+	default:
+		return nil, fmt.Errorf("cannot format %T as a date", value)
+	}
+
+	return t.Format(layout), nil
+}
+
+// truncateFilter shortens a string to a maximum number of characters
+func truncateFilter(value interface{}, args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+	}
+	max, ok := ToNumber(args[0])
+	if !ok {
+		return nil, fmt.Errorf("length must be a number, got %v", args[0])
+	}
+	if max < 0 || max != math.Trunc(max) {
+		return nil, fmt.Errorf("length must be a whole number of at least 0, got %v", args[0])
+	}
+
+	runes := []rune(Stringify(value))
+	// Compared as floats, lengths too large for an int keep the whole string
+	if float64(len(runes)) <= max {
+		return string(runes), nil
+	}
+	return string(runes[:int(max)]) + "...", nil
+}
+
+// lengthFilter counts the items of an array or object, or the characters of a string
+func lengthFilter(value interface{}, args ...interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return float64(0), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	default:
+		return float64(len([]rune(Stringify(v)))), nil
+	}
+}
+
+// formatNumber renders n with the given decimals and comma thousands separators
+func formatNumber(n float64, decimals int) string {
+	// Round half away from zero, as people expect on invoices
+	scale := math.Pow(10, float64(decimals))
+	s := strconv.FormatFloat(math.Round(math.Abs(n)*scale)/scale, 'f', decimals, 64)
+
+	intPart, fracPart := s, ""
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		intPart, fracPart = s[:dot], s[dot:]
+	}
+
+	var sb strings.Builder
+	if n < 0 && strings.Trim(s, "0.") != "" {
+		sb.WriteByte('-')
+	}
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(digit)
+	}
+	sb.WriteString(fracPart)
+	return sb.String()
+}
+
+// ToNumber converts numeric values and numeric strings to float64
+func ToNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// Stringify converts a bound value to the text that is rendered
+func Stringify(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format("2006-01-02")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,379 @@
+package binding
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// Scope holds the values visible to binding expressions
+type Scope struct {
+	data   interface{}
+	vars   map[string]interface{}
+	parent *Scope
+
+	// item is the current repeat item, used to expose "parent" to nested loops
+	item    interface{}
+	hasItem bool
+}
+
+// NewScope creates a root scope for the render data. The data is normalized
+// to its JSON form so structs and typed maps resolve like decoded JSON.
+func NewScope(data interface{}) (*Scope, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode data: %w", err)
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to decode data: %w", err)
+	}
+	return &Scope{data: normalized}, nil
+}
+
+// Child creates a nested scope with additional variables
+func (s *Scope) Child(vars map[string]interface{}) *Scope {
+	return &Scope{vars: vars, parent: s}
+}
+
+// Root returns the data of the outermost scope
+func (s *Scope) Root() interface{} {
+	for s.parent != nil {
+		s = s.parent
+	}
+	return s.data
+}
+
+// Lookup resolves a top-level name, searching enclosing scopes outwards
+func (s *Scope) Lookup(name string) (interface{}, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		if value, ok := scope.vars[name]; ok {
+			return value, true
+		}
+		if obj, ok := scope.data.(map[string]interface{}); ok {
+			if value, ok := obj[name]; ok {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Interpolate replaces every {{ expression }} in s with its value. When s
+// consists of a single expression the raw value is returned, so arrays and
+// numbers can be bound to table and chart content without being stringified.
+func Interpolate(s string, scope *Scope) (interface{}, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	trimmed := strings.TrimSpace(s)
+	if strings.HasPrefix(trimmed, "{{") && strings.HasSuffix(trimmed, "}}") &&
+		strings.Count(trimmed, "{{") == 1 {
+		return evalSource(trimmed[2:len(trimmed)-2], scope)
+	}
+
+	var sb strings.Builder
+	rest := s
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			sb.WriteString(rest)
+			break
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated binding in %q", s)
+		}
+		end += start
+
+		value, err := evalSource(rest[start+2:end], scope)
+		if err != nil {
+			return nil, err
+		}
+		sb.WriteString(rest[:start])
+		sb.WriteString(Stringify(value))
+		rest = rest[end+2:]
+	}
+	return sb.String(), nil
+}
+
+func test(source string, scope *Scope) (bool, error) {
+	expr, err := Parse(source)
+	if err != nil {
+		return false, err
+	}
+	return expr.Test(scope)
+}
+
+func evalSource(source string, scope *Scope) (interface{}, error) {
+	expr, err := Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	return expr.Eval(scope)
+}
+
+// ResolveElements returns copies of elements with all bindings in their
+// content resolved against the scope. Repeated elements are expanded once
+// per array item and groups are flattened into their children, so the
+// result is a plain list ready for layout. The template elements are not modified.
+func ResolveElements(elements []model.Element, scope *Scope) ([]model.Element, error) {
+	resolved := make([]model.Element, 0, len(elements))
+	for _, element := range elements {
+		expanded, err := resolveElement(element, scope)
+		if err != nil {
+			return nil, fmt.Errorf("element %s: %w", element.ID, err)
+		}
+		resolved = append(resolved, expanded...)
+	}
+	return resolved, nil
+}
+
+func resolveElement(element model.Element, scope *Scope) ([]model.Element, error) {
+	if element.Repeat != "" {
+		return repeatElement(element, scope)
+	}
+
+	// Conditions are checked after repeat expansion so they can test each item
+	if element.If != "" {
+		visible, err := test(element.If, scope)
+		if err != nil {
+			return nil, err
+		}
+		if !visible {
+			return nil, nil
+		}
+	}
+
+	if element.Type == model.ElementTypeGroup || len(element.Children) > 0 {
+		return ResolveElements(element.Children, scope)
+	}
+
+	content, err := resolveContent(element.Content, scope)
+	if err != nil {
+		return nil, err
+	}
+	if element.Type == model.ElementTypeText {
+		content = textValue(content)
+	}
+	element.Content = content
+	return []model.Element{element}, nil
+}
+
+// textValue shapes resolved text content for the text renderer, which takes
+// a string or a run list. Runs keep their formatting with their text
+// formatted, and a lone binding to any other value is formatted as a string.
+func textValue(content interface{}) interface{} {
+	switch v := content.(type) {
+	case string:
+		return v
+	case []interface{}:
+		for i, item := range v {
+			run, ok := item.(map[string]interface{})
+			if !ok {
+				v[i] = Stringify(item)
+				continue
+			}
+			if text, ok := run["text"]; ok && text != nil {
+				run["text"] = Stringify(text)
+			}
+		}
+		return v
+	}
+	return Stringify(content)
+}
+
+// repeatElement emits the element once per item of its Repeat array. Each
+// copy gets a child scope holding the item, its index and the parent item.
+func repeatElement(element model.Element, scope *Scope) ([]model.Element, error) {
+	items, err := evalArray(element.Repeat, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	template := element
+	template.Repeat = ""
+	template.RepeatAs = ""
+
+	var resolved []model.Element
+	for i, item := range items {
+		clone := template
+		clone.ID = fmt.Sprintf("%s-%d", element.ID, i)
+		clone.Children = suffixIDs(element.Children, i)
+
+		expanded, err := resolveElement(clone, loopScope(scope, element.RepeatAs, item, i, len(items)))
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, expanded...)
+	}
+	return resolved, nil
+}
+
+// loopScope creates the scope for one iteration of a repeat
+func loopScope(scope *Scope, as string, item interface{}, index, length int) *Scope {
+	if as == "" {
+		as = "item"
+	}
+
+	// parent is the item of the enclosing repeat, or the root data at the top level
+	parent := scope.Root()
+	for s := scope; s != nil; s = s.parent {
+		if s.hasItem {
+			parent = s.item
+			break
+		}
+	}
+
+	child := scope.Child(map[string]interface{}{
+		as:       item,
+		"index":  float64(index),
+		"parent": parent,
+		"loop": map[string]interface{}{
+			"index":  float64(index),
+			"number": float64(index + 1),
+			"first":  index == 0,
+			"last":   index == length-1,
+			"length": float64(length),
+		},
+	})
+	child.item, child.hasItem = item, true
+	return child
+}
+
+// evalArray evaluates a repeat expression, treating a missing value as empty
+func evalArray(source string, scope *Scope) ([]interface{}, error) {
+	value, err := evalSource(source, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("repeat %q is not an array (got %T)", source, value)
+	}
+}
+
+// suffixIDs copies elements, making their IDs unique for one repeat iteration
+func suffixIDs(elements []model.Element, index int) []model.Element {
+	if len(elements) == 0 {
+		return nil
+	}
+
+	copies := make([]model.Element, len(elements))
+	for i, element := range elements {
+		element.ID = fmt.Sprintf("%s-%d", element.ID, index)
+		element.Children = suffixIDs(element.Children, index)
+		copies[i] = element
+	}
+	return copies
+}
+
+// resolveContent walks nested content such as table rows, resolving every
+// string. Row templates carrying "repeat" or "if" are expanded in place.
+func resolveContent(content interface{}, scope *Scope) (interface{}, error) {
+	switch v := content.(type) {
+	case string:
+		return Interpolate(v, scope)
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if row, ok := item.(map[string]interface{}); ok && isRowTemplate(row) {
+				rows, err := resolveRow(row, scope)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, rows...)
+				continue
+			}
+
+			value, err := resolveContent(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, value)
+		}
+		return out, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			value, err := resolveContent(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = value
+		}
+		return out, nil
+	default:
+		return content, nil
+	}
+}
+
+// isRowTemplate reports whether a map in table content is a row template
+// rather than plain data such as an object bound from the payload
+func isRowTemplate(row map[string]interface{}) bool {
+	_, hasCells := row["cells"]
+	_, hasRepeat := row["repeat"]
+	_, hasIf := row["if"]
+	return hasCells && (hasRepeat || hasIf)
+}
+
+// resolveRow expands a row template such as
+// {"repeat": "order.items", "if": "item.quantity > 0", "cells": ["{{ item.name }}", "{{ item.price }}"]}
+// into zero or more rows. Row fields other than repeat, as and if, such as
+// a row style, are kept with the cells in a row object.
+func resolveRow(row map[string]interface{}, scope *Scope) ([]interface{}, error) {
+	condition, _ := row["if"].(string)
+
+	emit := func(scope *Scope) ([]interface{}, error) {
+		if condition != "" {
+			visible, err := test(condition, scope)
+			if err != nil || !visible {
+				return nil, err
+			}
+		}
+		resolved, err := resolveContent(row, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		// Row fields such as style stay with the cells in a row object
+		fields := resolved.(map[string]interface{})
+		for _, key := range []string{"repeat", "as", "if"} {
+			delete(fields, key)
+		}
+		if len(fields) == 1 {
+			return []interface{}{fields["cells"]}, nil
+		}
+		return []interface{}{fields}, nil
+	}
+
+	source, ok := row["repeat"].(string)
+	if !ok {
+		return emit(scope)
+	}
+
+	items, err := evalArray(source, scope)
+	if err != nil {
+		return nil, err
+	}
+	as, _ := row["as"].(string)
+
+	rows := make([]interface{}, 0, len(items))
+	for i, item := range items {
+		emitted, err := emit(loopScope(scope, as, item, i, len(items)))
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, emitted...)
+	}
+	return rows, nil
+}
@@ -6,10 +6,27 @@ import (
 	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
 )
 
+// PageSizeFunc returns the size of a 1-based page. A zero height means the
+// page has no fixed height and grows to fit its content.
+type PageSizeFunc func(page int) model.Size
+
+// MeasureFunc returns the height an element needs for its content, or its
+// bounds height when it keeps the size it was given
+type MeasureFunc func(element model.Element) (float64, error)
+
+// SplitFunc breaks an element that does not fit the rest of a page into a
+// first part no taller than height and the rest, or returns nil when the
+// element cannot break there. pageHeight is the content height of the next page.
+type SplitFunc func(element model.Element, height, pageHeight float64) ([]model.Element, error)
+
 // Manager handles the positioning and layout of PDF elements
 type Manager struct {
-	pageSize     model.Size
+	pageSize     PageSizeFunc
+	measure      MeasureFunc
+	split        SplitFunc
 	margins      model.Padding
+	header       *model.Region
+	footer       *model.Region
 	currentPage  int
 	currentY     float64
 	elements     []model.Element
@@ -17,7 +34,7 @@ type Manager struct {
 }
 
 // NewManager creates a new layout manager
-func NewManager(pageSize model.Size, margins model.Padding) *Manager {
+func NewManager(pageSize PageSizeFunc, margins model.Padding) *Manager {
 	return &Manager{
 		pageSize:     pageSize,
 		margins:      margins,
@@ -27,8 +44,9 @@ func NewManager(pageSize model.Size, margins model.Padding) *Manager {
 	}
 }
 
-// CalculateLayout positions all elements on pages
+// CalculateLayout positions all elements on pages, discarding any previous layout
 func (m *Manager) CalculateLayout(elements []model.Element) error {
+	m.reset()
 	m.elements = elements
 
 	for _, element := range elements {
@@ -42,31 +60,103 @@ func (m *Manager) CalculateLayout(elements []model.Element) error {
 
 // positionElement calculates the position for a single element
 func (m *Manager) positionElement(element *model.Element) error {
-	// Calculate available space
-	availableHeight := m.pageSize.Height - m.currentY - m.margins.Bottom
+	// Elements that grow with their content take the height they need
+	if m.measure != nil {
+		height, err := m.measure(*element)
+		if err != nil {
+			return fmt.Errorf("failed to measure element %s: %w", element.ID, err)
+		}
+		if height > element.Bounds.Height {
+			element.Bounds.Height = height
+		}
+	}
+
+	// Check if element fits on current page; pages without a fixed height never break.
+	// Elements that can split fill the rest of the page and continue on the next.
+	moved := false
+	for {
+		pageHeight := m.pageSize(m.currentPage).Height
+		availableHeight := pageHeight - m.currentY - m.bottomReserve(m.currentPage)
+		if pageHeight <= 0 || element.Bounds.Height <= availableHeight {
+			break
+		}
+
+		if m.split != nil {
+			parts, err := m.split(*element, availableHeight, m.contentHeight(m.currentPage+1))
+			if err != nil {
+				return fmt.Errorf("failed to split element %s: %w", element.ID, err)
+			}
+			if len(parts) == 2 {
+				m.place(&parts[0])
+				m.startNewPage()
+				*element = parts[1]
+				moved = true
+				continue
+			}
+		}
 
-	// Check if element fits on current page
-	if element.Bounds.Height > availableHeight {
+		// An element taller than a whole page is placed at the top of one
+		if moved {
+			break
+		}
 		m.startNewPage()
+		moved = true
 	}
 
-	// Set element position
+	m.place(element)
+	return nil
+}
+
+// place puts an element at the current position and moves below it
+func (m *Manager) place(element *model.Element) {
 	element.Bounds.X = m.margins.Left
 	element.Bounds.Y = m.currentY
-
-	// Update current Y position
 	m.currentY += element.Bounds.Height
-
-	// Add element to current page
 	m.pageElements[m.currentPage] = append(m.pageElements[m.currentPage], *element)
+}
 
-	return nil
+// reset clears state left over from a previous layout calculation
+func (m *Manager) reset() {
+	m.currentPage = 1
+	m.currentY = m.contentTop(1)
+	m.pageElements = make(map[int][]model.Element)
 }
 
 // startNewPage begins a new page for element positioning
 func (m *Manager) startNewPage() {
 	m.currentPage++
-	m.currentY = m.margins.Top
+	m.currentY = m.contentTop(m.currentPage)
+}
+
+// SetMeasure sets the function used to size elements to their content
+func (m *Manager) SetMeasure(measure MeasureFunc) {
+	m.measure = measure
+}
+
+// SetSplit sets the function used to break elements across pages
+func (m *Manager) SetSplit(split SplitFunc) {
+	m.split = split
+}
+
+// SetRegions reserves space for a header and footer on every page
+func (m *Manager) SetRegions(header, footer *model.Region) {
+	m.header = header
+	m.footer = footer
+}
+
+// contentTop returns the Y position where content starts on a page
+func (m *Manager) contentTop(page int) float64 {
+	return m.margins.Top + m.header.HeightOn(page)
+}
+
+// contentHeight returns the height available to content on an empty page
+func (m *Manager) contentHeight(page int) float64 {
+	return m.pageSize(page).Height - m.contentTop(page) - m.bottomReserve(page)
+}
+
+// bottomReserve returns the space below the content of a page
+func (m *Manager) bottomReserve(page int) float64 {
+	return m.margins.Bottom + m.footer.HeightOn(page)
 }
 
 // GetPageElements returns all elements for a specific page
@@ -78,3 +168,20 @@ func (m *Manager) GetPageElements(page int) []model.Element {
 func (m *Manager) TotalPages() int {
 	return m.currentPage
 }
+
+// PageSize returns the size of a page after layout. Pages without a fixed
+// height are as tall as their content plus the margins, header and footer.
+func (m *Manager) PageSize(page int) model.Size {
+	size := m.pageSize(page)
+	if size.Height > 0 {
+		return size
+	}
+
+	size.Height = m.contentTop(page) + m.bottomReserve(page)
+	for _, element := range m.pageElements[page] {
+		if bottom := element.Bounds.Y + element.Bounds.Height + m.bottomReserve(page); bottom > size.Height {
+			size.Height = bottom
+		}
+	}
+	return size
+}
@@ -0,0 +1,128 @@
+package layout
+
+import (
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+func TestManager_CalculateLayoutGrow(t *testing.T) {
+	a5 := func(int) model.Size { return model.Size{Width: 148, Height: 210} }
+	m := NewManager(a5, model.Padding{Top: 10, Right: 10, Bottom: 10, Left: 10})
+	m.SetMeasure(func(element model.Element) (float64, error) {
+		if element.Style.Overflow == model.OverflowGrow {
+			return 120, nil
+		}
+		return element.Bounds.Height, nil
+	})
+
+	block := func(id string, height float64, overflow model.Overflow) model.Element {
+		return model.Element{
+			ID:     id,
+			Bounds: model.Bounds{Size: model.Size{Width: 128, Height: height}},
+			Style:  &model.Style{Overflow: overflow},
+		}
+	}
+	elements := []model.Element{
+		block("title", 20, model.OverflowVisible),
+		block("notes", 30, model.OverflowGrow),
+		block("total", 20, model.OverflowVisible),
+		block("terms", 60, model.OverflowVisible),
+	}
+
+	if err := m.CalculateLayout(elements); err != nil {
+		t.Fatalf("CalculateLayout() error = %v", err)
+	}
+
+	tests := []struct {
+		page   int
+		id     string
+		y      float64
+		height float64
+	}{
+		{page: 1, id: "title", y: 10, height: 20},
+		{page: 1, id: "notes", y: 30, height: 120},
+		{page: 1, id: "total", y: 150, height: 20},
+		{page: 2, id: "terms", y: 10, height: 60},
+	}
+
+	for _, tt := range tests {
+		var found *model.Element
+		for _, element := range m.GetPageElements(tt.page) {
+			if element.ID == tt.id {
+				element := element
+				found = &element
+			}
+		}
+		if found == nil {
+			t.Errorf("element %s is not on page %d", tt.id, tt.page)
+			continue
+		}
+		if found.Bounds.Y != tt.y || found.Bounds.Height != tt.height {
+			t.Errorf("element %s at y=%.0f height=%.0f, want y=%.0f height=%.0f",
+				tt.id, found.Bounds.Y, found.Bounds.Height, tt.y, tt.height)
+		}
+	}
+}
+
+func TestManager_CalculateLayoutSplit(t *testing.T) {
+	a5 := func(int) model.Size { return model.Size{Width: 148, Height: 210} }
+	m := NewManager(a5, model.Padding{Top: 10, Right: 10, Bottom: 10, Left: 10})
+
+	// The fake splits into 10mm rows below a 10mm header repeated on every part
+	var pageHeights []float64
+	m.SetSplit(func(element model.Element, height, pageHeight float64) ([]model.Element, error) {
+		pageHeights = append(pageHeights, pageHeight)
+		rows := int((height - 10) / 10)
+		if element.ID == "keep" || rows < 1 {
+			return nil, nil
+		}
+		head, tail := element, element
+		head.Bounds.Height = 10 + float64(rows)*10
+		tail.Bounds.Height = element.Bounds.Height - float64(rows)*10
+		return []model.Element{head, tail}, nil
+	})
+
+	block := func(id string, height float64) model.Element {
+		return model.Element{ID: id, Bounds: model.Bounds{Size: model.Size{Width: 128, Height: height}}}
+	}
+	elements := []model.Element{
+		block("title", 45),
+		block("rows", 410),
+		block("keep", 150),
+	}
+	if err := m.CalculateLayout(elements); err != nil {
+		t.Fatalf("CalculateLayout() error = %v", err)
+	}
+
+	tests := []struct {
+		page   int
+		id     string
+		y      float64
+		height float64
+	}{
+		{page: 1, id: "title", y: 10, height: 45},
+		{page: 1, id: "rows", y: 55, height: 140},
+		{page: 2, id: "rows", y: 10, height: 190},
+		{page: 3, id: "rows", y: 10, height: 100},
+		{page: 4, id: "keep", y: 10, height: 150},
+	}
+
+	if m.TotalPages() != 4 {
+		t.Fatalf("TotalPages() = %d, want 4", m.TotalPages())
+	}
+	for _, tt := range tests {
+		found := false
+		for _, element := range m.GetPageElements(tt.page) {
+			if element.ID == tt.id && element.Bounds.Y == tt.y && element.Bounds.Height == tt.height {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no %s at y=%.0f height=%.0f on page %d: %+v", tt.id, tt.y, tt.height, tt.page, m.GetPageElements(tt.page))
+		}
+	}
+	if pageHeights[0] != 190 {
+		t.Errorf("split got page height %v, want 190", pageHeights[0])
+	}
+}
@@ -0,0 +1,179 @@
+// Package markdown reads the inline subset of Markdown used by rich text
+// elements into text runs.
+//
+// Supported syntax is **bold** or __bold__, *italic* or _italic_,
+// ~~strike-through~~, `code` and [links](https://example.com). A backslash
+// escapes punctuation. Newlines are kept as paragraph breaks; block syntax
+// such as headings and lists is left as plain text.
+package markdown
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// escapable lists the characters a backslash makes literal
+const escapable = "\\`*_~[](){}#+-.!>|"
+
+// markers are the emphasis delimiters, longest first so "**" is not read as two "*"
+var markers = []string{"**", "__", "~~", "*", "_"}
+
+// Parse converts Markdown source to text runs. Adjacent text with the same
+// formatting is merged into one run.
+func Parse(source string) []model.TextRun {
+	p := &parser{}
+	p.parse(source, model.TextRun{})
+	return p.runs
+}
+
+type parser struct {
+	runs []model.TextRun
+}
+
+// parse reads s with the formatting of base, toggling emphasis at markers
+func (p *parser) parse(s string, base model.TextRun) {
+	open := make(map[string]bool)
+	format := func() model.TextRun {
+		f := base
+		f.Bold = f.Bold || open["**"] || open["__"]
+		f.Italic = f.Italic || open["*"] || open["_"]
+		f.Strike = f.Strike || open["~~"]
+		return f
+	}
+
+	var text strings.Builder
+	flush := func() {
+		p.emit(text.String(), format())
+		text.Reset()
+	}
+
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(escapable, s[i+1]) >= 0:
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				flush()
+				code := format()
+				code.Code = true
+				p.emit(s[i+1:i+1+end], code)
+				i += end + 2
+				continue
+			}
+
+		case c == '[':
+			if label, url, n, ok := link(s[i:]); ok {
+				flush()
+				linked := format()
+				linked.Link = url
+				p.parse(label, linked)
+				i += n
+				continue
+			}
+
+		case c == '*' || c == '_' || c == '~':
+			if marker := markerAt(s, i); marker != "" && toggles(s, i, marker, open[marker]) {
+				flush()
+				open[marker] = !open[marker]
+				i += len(marker)
+				continue
+			}
+		}
+
+		text.WriteByte(s[i])
+		i++
+	}
+	flush()
+}
+
+// emit appends text in the given format, extending the previous run when
+// its format matches
+func (p *parser) emit(text string, format model.TextRun) {
+	if text == "" {
+		return
+	}
+	if n := len(p.runs); n > 0 {
+		last := p.runs[n-1]
+		last.Text = ""
+		if last == format {
+			p.runs[n-1].Text += text
+			return
+		}
+	}
+	format.Text = text
+	p.runs = append(p.runs, format)
+}
+
+// markerAt returns the emphasis delimiter starting at s[i], if any
+func markerAt(s string, i int) string {
+	for _, marker := range markers {
+		if strings.HasPrefix(s[i:], marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+// toggles reports whether the marker at s[i] opens or closes emphasis.
+// Openers must be followed by text and closed later in s; closers must
+// follow text. Underscores inside words, as in snake_case, stay literal.
+func toggles(s string, i int, marker string, closing bool) bool {
+	before, _ := utf8.DecodeLastRuneInString(s[:i])
+	after, _ := utf8.DecodeRuneInString(s[i+len(marker):])
+	if i == 0 {
+		before = ' '
+	}
+	if i+len(marker) == len(s) {
+		after = ' '
+	}
+
+	if marker[0] == '_' && (isWordRune(before) && isWordRune(after)) {
+		return false
+	}
+	if closing {
+		return !unicode.IsSpace(before)
+	}
+	return !unicode.IsSpace(after) && strings.Contains(s[i+len(marker):], marker)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// link parses "[label](url)" at the start of s, returning the label, the
+// URL and the length of the whole link
+func link(s string) (string, string, int, bool) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if i+1 >= len(s) || s[i+1] != '(' {
+				return "", "", 0, false
+			}
+			end := strings.IndexByte(s[i+2:], ')')
+			if end < 0 {
+				return "", "", 0, false
+			}
+			url := strings.TrimSpace(s[i+2 : i+2+end])
+			if url == "" {
+				return "", "", 0, false
+			}
+			return s[1:i], url, i + 3 + end, true
+		}
+	}
+	return "", "", 0, false
+}
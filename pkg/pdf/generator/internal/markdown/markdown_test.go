@@ -0,0 +1,82 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []model.TextRun
+	}{
+		{
+			name:   "plain",
+			source: "Dear customer,\nthank you.",
+			want:   []model.TextRun{{Text: "Dear customer,\nthank you."}},
+		},
+		{
+			name:   "bold and italic",
+			source: "Pay **now** or _later_",
+			want: []model.TextRun{
+				{Text: "Pay "}, {Text: "now", Bold: true}, {Text: " or "}, {Text: "later", Italic: true},
+			},
+		},
+		{
+			name:   "nested emphasis",
+			source: "**bold *both***",
+			want:   []model.TextRun{{Text: "bold ", Bold: true}, {Text: "both", Bold: true, Italic: true}},
+		},
+		{
+			name:   "strike and code",
+			source: "~~old~~ `new_value`",
+			want:   []model.TextRun{{Text: "old", Strike: true}, {Text: " "}, {Text: "new_value", Code: true}},
+		},
+		{
+			name:   "link with emphasis",
+			source: "See [our **terms**](https://example.com/terms).",
+			want: []model.TextRun{
+				{Text: "See "},
+				{Text: "our ", Link: "https://example.com/terms"},
+				{Text: "terms", Bold: true, Link: "https://example.com/terms"},
+				{Text: "."},
+			},
+		},
+		{
+			name:   "escapes",
+			source: `\*not italic\* and \[not a link\]`,
+			want:   []model.TextRun{{Text: "*not italic* and [not a link]"}},
+		},
+		{
+			name:   "unmatched markers stay literal",
+			source: "5 * 3 and 2*x",
+			want:   []model.TextRun{{Text: "5 * 3 and 2*x"}},
+		},
+		{
+			name:   "underscores inside words",
+			source: "order_id and snake_case_name",
+			want:   []model.TextRun{{Text: "order_id and snake_case_name"}},
+		},
+		{
+			name:   "incomplete link",
+			source: "[draft](",
+			want:   []model.TextRun{{Text: "[draft]("}},
+		},
+		{
+			name:   "unclosed code",
+			source: "a ` b",
+			want:   []model.TextRun{{Text: "a ` b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.source); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
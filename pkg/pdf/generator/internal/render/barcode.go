@@ -0,0 +1,275 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/code39"
+	"github.com/boombuler/barcode/datamatrix"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/pdf417"
+	"github.com/boombuler/barcode/qr"
+	"github.com/boombuler/barcode/twooffive"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/fonts"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// captionFontSize is the default size in points of the human-readable barcode text
+const captionFontSize = 8.0
+
+// defaultPDF417SecurityLevel balances capacity and error correction for short payloads
+const defaultPDF417SecurityLevel = 2
+
+// BarcodeRenderer draws linear and 2D barcodes as vector modules inside the
+// element bounds. Linear codes are stretched to fill the box, leaving their
+// quiet zones clear; 2D codes keep their module aspect ratio and are
+// centered.
+type BarcodeRenderer struct{}
+
+func (r *BarcodeRenderer) Render(ctx *Context, element model.Element) error {
+	content, ok := contentString(element.Content)
+	if !ok || content == "" {
+		return fmt.Errorf("invalid content type for barcode element: expected non-empty string, got %T", element.Content)
+	}
+
+	var opts model.BarcodeOptions
+	if err := element.DecodeMetadata(&opts); err != nil {
+		return err
+	}
+
+	code, err := encodeBarcode(content, opts)
+	if err != nil {
+		return fmt.Errorf("failed to encode barcode %s: %w", element.ID, err)
+	}
+
+	pdf := ctx.PDF
+	bounds, err := drawBox(ctx, element)
+	if err != nil {
+		return err
+	}
+
+	// Bars and caption use the font color
+	ink, err := setTextColor(pdf, element.Style)
+	if err != nil {
+		return err
+	}
+
+	var caption string
+	var face typeface
+	var captionSize model.Size
+	if opts.ShowText || opts.Text != "" {
+		caption = opts.Text
+		if caption == "" {
+			caption = code.Content()
+		}
+
+		family, size := "Arial", captionFontSize
+		if element.Style != nil {
+			if element.Style.FontFamily != "" {
+				family = element.Style.FontFamily
+			}
+			if element.Style.FontSize > 0 {
+				size = element.Style.FontSize
+			}
+		}
+		face = newTypeface(ctx, family, fonts.Regular, size)
+		captionSize = model.Size{Width: face.width(caption), Height: pdf.PointToUnitConvert(size)}
+	}
+
+	box, at, err := barcodeLayout(code, bounds, captionSize)
+	if err != nil {
+		return fmt.Errorf("barcode %s %w", element.ID, err)
+	}
+	withAlpha(pdf, ink, func() {
+		if caption != "" {
+			face.text(at.X, at.Y, caption)
+		}
+		pdf.SetFillColor(ink.R, ink.G, ink.B)
+		for _, bar := range moduleBars(code, box) {
+			pdf.Rect(bar.X, bar.Y, bar.Width, bar.Height, "F")
+		}
+	})
+	return nil
+}
+
+// quietZone returns the clear space in modules a code needs before and
+// after its bars: ten modules for most linear codes, and 11 on the left
+// and 7 on the right of EAN-13. 2D codes need theirs on every side.
+func quietZone(code barcode.Barcode) (int, int) {
+	switch code.Metadata().CodeKind {
+	case barcode.TypeEAN13:
+		return 11, 7
+	case barcode.TypeCode128, barcode.TypeCode39, barcode.Type2of5Interleaved:
+		return 10, 10
+	case barcode.TypeQR:
+		return 4, 4
+	case barcode.TypePDF:
+		return 2, 2
+	case barcode.TypeDataMatrix:
+		return 1, 1
+	}
+	return 0, 0
+}
+
+// barcodeLayout places a code in the bounds. It returns the box the modules
+// fill and, for a caption of the given size, where its baseline starts,
+// centered under the modules. Linear codes stretch across the bounds less
+// their quiet zones; 2D codes keep square modules and are centered with
+// their quiet zones inside the bounds.
+func barcodeLayout(code barcode.Barcode, bounds model.Bounds, caption model.Size) (model.Bounds, model.Position, error) {
+	area := bounds
+	if caption.Height > 0 {
+		area.Height -= caption.Height * 1.4
+		if area.Height <= 0 {
+			return model.Bounds{}, model.Position{}, fmt.Errorf("is too short to fit its caption")
+		}
+	}
+
+	rect := code.Bounds()
+	before, after := quietZone(code)
+	cols, rows := float64(rect.Dx()), float64(rect.Dy())
+	var box model.Bounds
+	if code.Metadata().Dimensions == 2 {
+		module := min(area.Width/(cols+float64(before+after)), area.Height/(rows+float64(before+after)))
+		box.Size = model.Size{Width: module * cols, Height: module * rows}
+		box.X = area.X + (area.Width-box.Width)/2
+		box.Y = area.Y + (area.Height-box.Height)/2
+	} else {
+		module := area.Width / (cols + float64(before+after))
+		box = model.Bounds{
+			Position: model.Position{X: area.X + float64(before)*module, Y: area.Y},
+			Size:     model.Size{Width: module * cols, Height: area.Height},
+		}
+	}
+
+	at := model.Position{
+		X: box.X + (box.Width-caption.Width)/2,
+		Y: bounds.Y + bounds.Height - caption.Height*0.2,
+	}
+	return box, at, nil
+}
+
+// encodeBarcode encodes content with the requested symbology
+func encodeBarcode(content string, opts model.BarcodeOptions) (barcode.Barcode, error) {
+	switch opts.Symbology {
+	case model.SymbologyQR:
+		level, err := qrErrorCorrection(opts.ErrorCorrection)
+		if err != nil {
+			return nil, err
+		}
+		return qr.Encode(content, level, qr.Auto)
+	case model.SymbologyDataMatrix:
+		return datamatrix.Encode(content)
+	case model.SymbologyPDF417:
+		level := defaultPDF417SecurityLevel
+		if opts.SecurityLevel != nil {
+			level = *opts.SecurityLevel
+		}
+		if level < 0 || level > 8 {
+			return nil, fmt.Errorf("PDF417 security level must be between 0 and 8, got %d", level)
+		}
+		return pdf417.Encode(content, byte(level))
+	case model.SymbologyCode128, "":
+		return code128.Encode(content)
+	case model.SymbologyCode39:
+		return code39.Encode(content, opts.Checksum, true)
+	case model.SymbologyEAN13:
+		if len(content) != 12 && len(content) != 13 {
+			return nil, fmt.Errorf("EAN-13 requires 12 or 13 digits, got %d", len(content))
+		}
+		return ean.Encode(content)
+	case model.SymbologyInterleaved:
+		if opts.Checksum {
+			withChecksum, err := twooffive.AddCheckSum(content)
+			if err != nil {
+				return nil, err
+			}
+			content = withChecksum
+		}
+		return twooffive.Encode(content, true)
+	default:
+		return nil, fmt.Errorf("unsupported barcode symbology: %s", opts.Symbology)
+	}
+}
+
+func qrErrorCorrection(level string) (qr.ErrorCorrectionLevel, error) {
+	switch strings.ToUpper(level) {
+	case "L":
+		return qr.L, nil
+	case "M", "":
+		return qr.M, nil
+	case "Q":
+		return qr.Q, nil
+	case "H":
+		return qr.H, nil
+	}
+	return qr.M, fmt.Errorf("unsupported QR error correction level: %s", level)
+}
+
+// moduleBars returns the dark modules of a code as rectangles scaled to the
+// box. Adjacent dark modules in a row are merged into a single bar, and
+// identical consecutive rows into one so stacked codes have no seams.
+func moduleBars(code barcode.Barcode, box model.Bounds) []model.Bounds {
+	rect := code.Bounds()
+	cols, rows := rect.Dx(), rect.Dy()
+	moduleWidth := box.Width / float64(cols)
+	moduleHeight := box.Height / float64(rows)
+
+	rowPattern := func(row int) string {
+		pattern := make([]byte, cols)
+		for col := range pattern {
+			pattern[col] = '0'
+			if isDark(code, rect.Min.X+col, rect.Min.Y+row) {
+				pattern[col] = '1'
+			}
+		}
+		return string(pattern)
+	}
+
+	var bars []model.Bounds
+	for row := 0; row < rows; {
+		pattern := rowPattern(row)
+		span := 1
+		for row+span < rows && rowPattern(row+span) == pattern {
+			span++
+		}
+
+		start := -1
+		for col := 0; col <= cols; col++ {
+			dark := col < cols && pattern[col] == '1'
+			switch {
+			case dark && start < 0:
+				start = col
+			case !dark && start >= 0:
+				bars = append(bars, model.Bounds{
+					Position: model.Position{X: box.X + float64(start)*moduleWidth, Y: box.Y + float64(row)*moduleHeight},
+					Size:     model.Size{Width: float64(col-start) * moduleWidth, Height: float64(span) * moduleHeight},
+				})
+				start = -1
+			}
+		}
+		row += span
+	}
+	return bars
+}
+
+func isDark(code barcode.Barcode, x, y int) bool {
+	r, g, b, _ := code.At(x, y).RGBA()
+	return (r+g+b)/3 < 0x8000
+}
+
+// contentString returns element content as text, formatting bound numbers
+func contentString(content interface{}) (string, bool) {
+	switch v := content.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	}
+	return "", false
+}
@@ -0,0 +1,339 @@
+package render
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/boombuler/barcode"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// testCode is a barcode with the given rows of modules, 1 for dark
+type testCode struct {
+	kind       string
+	dimensions byte
+	rows       []string
+}
+
+func (c testCode) ColorModel() color.Model { return color.GrayModel }
+func (c testCode) Bounds() image.Rectangle { return image.Rect(0, 0, len(c.rows[0]), len(c.rows)) }
+func (c testCode) Content() string         { return "test" }
+
+func (c testCode) Metadata() barcode.Metadata {
+	return barcode.Metadata{CodeKind: c.kind, Dimensions: c.dimensions}
+}
+
+func (c testCode) At(x, y int) color.Color {
+	if c.rows[y][x] == '1' {
+		return color.Black
+	}
+	return color.White
+}
+
+func nearBounds(a, b model.Bounds) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9 &&
+		math.Abs(a.Width-b.Width) < 1e-9 && math.Abs(a.Height-b.Height) < 1e-9
+}
+
+func TestModuleBars(t *testing.T) {
+	bounds := func(x, y, w, h float64) model.Bounds {
+		return model.Bounds{Position: model.Position{X: x, Y: y}, Size: model.Size{Width: w, Height: h}}
+	}
+
+	tests := []struct {
+		name string
+		code testCode
+		box  model.Bounds
+		want []model.Bounds
+	}{
+		{
+			// Modules 2mm wide; runs of dark modules make one bar each
+			name: "linear",
+			code: testCode{dimensions: 1, rows: []string{"0110100111"}},
+			box:  bounds(10, 10, 20, 5),
+			want: []model.Bounds{bounds(12, 10, 4, 5), bounds(18, 10, 2, 5), bounds(24, 10, 6, 5)},
+		},
+		{
+			// The first two rows match and are drawn once
+			name: "stacked rows",
+			code: testCode{dimensions: 2, rows: []string{"110", "110", "011"}},
+			box:  bounds(0, 0, 3, 3),
+			want: []model.Bounds{bounds(0, 0, 2, 2), bounds(1, 2, 2, 1)},
+		},
+		{
+			name: "blank",
+			code: testCode{dimensions: 1, rows: []string{"000"}},
+			box:  bounds(0, 0, 3, 3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := moduleBars(tt.code, tt.box)
+			if len(got) != len(tt.want) {
+				t.Fatalf("moduleBars() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if !nearBounds(got[i], tt.want[i]) {
+					t.Errorf("bar %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBarcodeLayout_Linear(t *testing.T) {
+	bounds := model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 113, Height: 20}}
+	row := func(n int) []string { return []string{strings.Repeat("1", n)} }
+
+	tests := []struct {
+		name    string
+		code    testCode
+		caption model.Size
+		wantBox model.Bounds
+		wantAt  model.Position
+		wantErr bool
+	}{
+		{
+			// 93 modules and 10 either side make 1mm modules
+			name:    "code 128 quiet zones",
+			code:    testCode{kind: barcode.TypeCode128, dimensions: 1, rows: row(93)},
+			wantBox: model.Bounds{Position: model.Position{X: 20, Y: 10}, Size: model.Size{Width: 93, Height: 20}},
+			wantAt:  model.Position{X: 66.5, Y: 30},
+		},
+		{
+			// 11 modules on the left and 7 on the right; the caption takes
+			// 1.4 times its height and is centered under the bars
+			name:    "ean-13 caption",
+			code:    testCode{kind: barcode.TypeEAN13, dimensions: 1, rows: row(95)},
+			caption: model.Size{Width: 30, Height: 4},
+			wantBox: model.Bounds{Position: model.Position{X: 21, Y: 10}, Size: model.Size{Width: 95, Height: 14.4}},
+			wantAt:  model.Position{X: 53.5, Y: 29.2},
+		},
+		{
+			name:    "caption too tall",
+			code:    testCode{kind: barcode.TypeCode39, dimensions: 1, rows: row(93)},
+			caption: model.Size{Width: 30, Height: 15},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box, at, err := barcodeLayout(tt.code, bounds, tt.caption)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("barcodeLayout() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("barcodeLayout() error = %v", err)
+			}
+			if !nearBounds(box, tt.wantBox) {
+				t.Errorf("barcodeLayout() box = %+v, want %+v", box, tt.wantBox)
+			}
+			if math.Abs(at.X-tt.wantAt.X) > 1e-9 || math.Abs(at.Y-tt.wantAt.Y) > 1e-9 {
+				t.Errorf("barcodeLayout() caption at %+v, want %+v", at, tt.wantAt)
+			}
+		})
+	}
+}
+
+func TestBarcodeLayout_LinearSymbologies(t *testing.T) {
+	bounds := model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 80, Height: 20}}
+
+	tests := []struct {
+		symbology   model.BarcodeSymbology
+		content     string
+		left, right int
+	}{
+		{symbology: model.SymbologyCode128, content: "ORD-12345", left: 10, right: 10},
+		{symbology: model.SymbologyCode39, content: "ORD-12345", left: 10, right: 10},
+		{symbology: model.SymbologyEAN13, content: "4006381333931", left: 11, right: 7},
+		{symbology: model.SymbologyInterleaved, content: "12345678", left: 10, right: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.symbology), func(t *testing.T) {
+			code, err := encodeBarcode(tt.content, model.BarcodeOptions{Symbology: tt.symbology})
+			if err != nil {
+				t.Fatalf("encodeBarcode() error = %v", err)
+			}
+			box, _, err := barcodeLayout(code, bounds, model.Size{})
+			if err != nil {
+				t.Fatalf("barcodeLayout() error = %v", err)
+			}
+			bars := moduleBars(code, box)
+			if len(bars) == 0 {
+				t.Fatal("moduleBars() drew no bars")
+			}
+
+			// The codes start and end with a bar, right at their quiet zones
+			module := bounds.Width / float64(code.Bounds().Dx()+tt.left+tt.right)
+			first, last := bars[0], bars[len(bars)-1]
+			if math.Abs(first.X-(bounds.X+float64(tt.left)*module)) > 1e-9 {
+				t.Errorf("first bar starts at %v, want %d modules of %v in from %v", first.X, tt.left, module, bounds.X)
+			}
+			if end := last.X + last.Width; math.Abs(end-(bounds.X+bounds.Width-float64(tt.right)*module)) > 1e-9 {
+				t.Errorf("last bar ends at %v, want %d modules of %v before %v", end, tt.right, module, bounds.X+bounds.Width)
+			}
+			for _, bar := range bars {
+				if n := bar.Width / module; math.Abs(n-math.Round(n)) > 1e-9 || bar.Height != bounds.Height {
+					t.Errorf("bar %+v is not whole modules of %v across the full height", bar, module)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestBarcodeLayout_2D(t *testing.T) {
+	filled := func(cols, rows int) []string {
+		lines := make([]string, rows)
+		for i := range lines {
+			lines[i] = strings.Repeat("1", cols)
+		}
+		return lines
+	}
+	bounds := func(x, y, w, h float64) model.Bounds {
+		return model.Bounds{Position: model.Position{X: x, Y: y}, Size: model.Size{Width: w, Height: h}}
+	}
+
+	tests := []struct {
+		name    string
+		code    testCode
+		bounds  model.Bounds
+		wantBox model.Bounds
+	}{
+		{
+			// 21 modules and 4 either side fit the height in 1mm modules,
+			// centered across the spare width
+			name:    "qr in wide bounds",
+			code:    testCode{kind: barcode.TypeQR, dimensions: 2, rows: filled(21, 21)},
+			bounds:  bounds(10, 10, 58, 29),
+			wantBox: bounds(28.5, 14, 21, 21),
+		},
+		{
+			name:    "qr in tall bounds",
+			code:    testCode{kind: barcode.TypeQR, dimensions: 2, rows: filled(21, 21)},
+			bounds:  bounds(10, 10, 29, 60),
+			wantBox: bounds(14, 29.5, 21, 21),
+		},
+		{
+			// One module of quiet zone on every side
+			name:    "datamatrix",
+			code:    testCode{kind: barcode.TypeDataMatrix, dimensions: 2, rows: filled(10, 10)},
+			bounds:  bounds(0, 0, 24, 12),
+			wantBox: bounds(7, 1, 10, 10),
+		},
+		{
+			// 20 by 6 modules with 2 either side fit both ways in 2mm modules
+			name:    "pdf417",
+			code:    testCode{kind: barcode.TypePDF, dimensions: 2, rows: filled(20, 6)},
+			bounds:  bounds(0, 0, 48, 20),
+			wantBox: bounds(4, 4, 40, 12),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box, _, err := barcodeLayout(tt.code, tt.bounds, model.Size{})
+			if err != nil {
+				t.Fatalf("barcodeLayout() error = %v", err)
+			}
+			if !nearBounds(box, tt.wantBox) {
+				t.Errorf("barcodeLayout() box = %+v, want %+v", box, tt.wantBox)
+			}
+		})
+	}
+}
+
+func TestBarcodeLayout_2DSymbologies(t *testing.T) {
+	bounds := model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 80, Height: 30}}
+
+	tests := []struct {
+		symbology model.BarcodeSymbology
+		quiet     int
+	}{
+		{symbology: model.SymbologyQR, quiet: 4},
+		{symbology: model.SymbologyDataMatrix, quiet: 1},
+		{symbology: model.SymbologyPDF417, quiet: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.symbology), func(t *testing.T) {
+			code, err := encodeBarcode("https://example.com/orders/ORD-12345", model.BarcodeOptions{Symbology: tt.symbology})
+			if err != nil {
+				t.Fatalf("encodeBarcode() error = %v", err)
+			}
+			box, _, err := barcodeLayout(code, bounds, model.Size{})
+			if err != nil {
+				t.Fatalf("barcodeLayout() error = %v", err)
+			}
+
+			cols, rows := code.Bounds().Dx(), code.Bounds().Dy()
+			module := math.Min(bounds.Width/float64(cols+2*tt.quiet), bounds.Height/float64(rows+2*tt.quiet))
+			if math.Abs(box.Width/float64(cols)-module) > 1e-9 || math.Abs(box.Height/float64(rows)-module) > 1e-9 {
+				t.Errorf("box %+v is not %d by %d square modules of %v", box, cols, rows, module)
+			}
+
+			// Centered, with the quiet zone inside the bounds
+			if math.Abs((box.X+box.Width/2)-(bounds.X+bounds.Width/2)) > 1e-9 ||
+				math.Abs((box.Y+box.Height/2)-(bounds.Y+bounds.Height/2)) > 1e-9 {
+				t.Errorf("box %+v is not centered in %+v", box, bounds)
+			}
+			zone := float64(tt.quiet) * module
+			if box.X-zone < bounds.X-1e-9 || box.Y-zone < bounds.Y-1e-9 ||
+				box.X+box.Width+zone > bounds.X+bounds.Width+1e-9 || box.Y+box.Height+zone > bounds.Y+bounds.Height+1e-9 {
+				t.Errorf("box %+v leaves no %v quiet zone inside %+v", box, zone, bounds)
+			}
+
+			for _, bar := range moduleBars(code, box) {
+				w, h := bar.Width/module, bar.Height/module
+				if math.Abs(w-math.Round(w)) > 1e-9 || math.Abs(h-math.Round(h)) > 1e-9 {
+					t.Errorf("bar %+v is not whole modules of %v", bar, module)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestBarcodeRenderer_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  interface{}
+		metadata string
+		wantErr  string
+	}{
+		{name: "empty", content: "", metadata: `{}`, wantErr: "expected non-empty string"},
+		{name: "unknown symbology", content: "1", metadata: `{"symbology": "aztec"}`, wantErr: "unsupported barcode symbology"},
+		{name: "ean-13 length", content: "12345", metadata: `{"symbology": "ean13"}`, wantErr: "12 or 13 digits"},
+		{name: "interleaved letters", content: "12AB", metadata: `{"symbology": "i2of5"}`, wantErr: "failed to encode barcode"},
+		{name: "caption too tall", content: "ORD-1", metadata: `{"showText": true}`, wantErr: "too short to fit its caption"},
+		{name: "qr too long", content: strings.Repeat("a", 3000), metadata: `{"symbology": "qr"}`, wantErr: "failed to encode barcode"},
+		{name: "datamatrix too long", content: strings.Repeat("a", 2000), metadata: `{"symbology": "datamatrix"}`, wantErr: "failed to encode barcode"},
+		{name: "pdf417 too long", content: strings.Repeat("a", 2000), metadata: `{"symbology": "pdf417"}`, wantErr: "failed to encode barcode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			element := model.Element{
+				ID:       "code",
+				Type:     model.ElementTypeBarcode,
+				Bounds:   model.Bounds{Size: model.Size{Width: 60, Height: 3}},
+				Content:  tt.content,
+				Metadata: json.RawMessage(tt.metadata),
+			}
+			err := (&BarcodeRenderer{}).Render(newTestContext(), element)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Render() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
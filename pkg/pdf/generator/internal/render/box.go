@@ -0,0 +1,145 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/internal/color"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// drawBox paints the background and borders of an element and returns the
+// bounds left for its content once the padding is removed
+func drawBox(ctx *Context, element model.Element) (model.Bounds, error) {
+	bounds := element.Bounds
+	style := element.Style
+	if style == nil {
+		return bounds, nil
+	}
+
+	pdf := ctx.PDF
+	if style.Background != "" {
+		background, err := color.Parse(style.Background)
+		if err != nil {
+			return bounds, fmt.Errorf("invalid background of element %s: %w", element.ID, err)
+		}
+		if !background.Transparent() {
+			withAlpha(pdf, background, func() {
+				pdf.SetFillColor(background.R, background.G, background.B)
+				pdf.Rect(bounds.X, bounds.Y, bounds.Width, bounds.Height, "F")
+			})
+		}
+	}
+
+	left, top := bounds.X, bounds.Y
+	right, bottom := bounds.X+bounds.Width, bounds.Y+bounds.Height
+	sides := []struct {
+		border         *model.Border
+		x1, y1, x2, y2 float64
+	}{
+		{sideBorder(style.BorderTop, style.Border), left, top, right, top},
+		{sideBorder(style.BorderRight, style.Border), right, top, right, bottom},
+		{sideBorder(style.BorderBottom, style.Border), left, bottom, right, bottom},
+		{sideBorder(style.BorderLeft, style.Border), left, top, left, bottom},
+	}
+	for _, side := range sides {
+		ok, err := setStroke(pdf, side.border)
+		if err != nil {
+			return bounds, fmt.Errorf("invalid border of element %s: %w", element.ID, err)
+		}
+		if ok {
+			pdf.Line(side.x1, side.y1, side.x2, side.y2)
+		}
+	}
+	resetStroke(pdf)
+
+	return insetPadding(bounds, style), nil
+}
+
+// insetPadding returns the bounds left for content inside the style's padding
+func insetPadding(bounds model.Bounds, style *model.Style) model.Bounds {
+	if style != nil && style.Padding != nil {
+		bounds.X += style.Padding.Left
+		bounds.Y += style.Padding.Top
+		bounds.Width -= style.Padding.Left + style.Padding.Right
+		bounds.Height -= style.Padding.Top + style.Padding.Bottom
+	}
+	return bounds
+}
+
+// sideBorder returns the border of one side, falling back to the shared border
+func sideBorder(side, shared *model.Border) *model.Border {
+	if side != nil {
+		return side
+	}
+	return shared
+}
+
+// setStroke configures the line width, color and dash pattern of a border.
+// It reports false when the border draws nothing.
+func setStroke(pdf *gofpdf.Fpdf, border *model.Border) (bool, error) {
+	if border == nil || border.Width <= 0 || border.Style == model.BorderNone {
+		return false, nil
+	}
+
+	c := color.Black
+	if border.Color != "" {
+		var err error
+		if c, err = color.Parse(border.Color); err != nil {
+			return false, err
+		}
+		if c.Transparent() {
+			return false, nil
+		}
+	}
+
+	pdf.SetLineWidth(border.Width)
+	pdf.SetDrawColor(c.R, c.G, c.B)
+	switch border.Style {
+	case model.BorderDashed:
+		pdf.SetLineCapStyle("butt")
+		pdf.SetDashPattern([]float64{border.Width * 3, border.Width * 2}, 0)
+	case model.BorderDotted:
+		// Zero-length dashes with round caps draw as dots
+		pdf.SetLineCapStyle("round")
+		pdf.SetDashPattern([]float64{0, border.Width * 2}, 0)
+	case model.BorderSolid, "":
+		pdf.SetLineCapStyle("butt")
+		pdf.SetDashPattern(nil, 0)
+	default:
+		return false, fmt.Errorf("unknown border style %q", border.Style)
+	}
+	return true, nil
+}
+
+// resetStroke restores solid black lines after a styled border
+func resetStroke(pdf *gofpdf.Fpdf) {
+	pdf.SetDashPattern(nil, 0)
+	pdf.SetLineCapStyle("butt")
+	pdf.SetDrawColor(0, 0, 0)
+}
+
+// setTextColor applies the style's font color, black by default, and
+// returns it so translucent text can be drawn with withAlpha
+func setTextColor(pdf *gofpdf.Fpdf, style *model.Style) (color.Color, error) {
+	c := color.Black
+	if style != nil && style.FontColor != "" {
+		var err error
+		if c, err = color.Parse(style.FontColor); err != nil {
+			return c, fmt.Errorf("invalid font color: %w", err)
+		}
+	}
+	pdf.SetTextColor(c.R, c.G, c.B)
+	return c, nil
+}
+
+// withAlpha runs draw with the color's opacity and restores full opacity afterwards
+func withAlpha(pdf *gofpdf.Fpdf, c color.Color, draw func()) {
+	if c.Opaque() {
+		draw()
+		return
+	}
+	pdf.SetAlpha(c.A, "Normal")
+	draw()
+	pdf.SetAlpha(1, "Normal")
+}
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// newTestContext returns a context on a blank, uncompressed A4 page
+func newTestContext() *Context {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(false)
+	pdf.SetFont("Arial", "", 12)
+	pdf.AddPage()
+	return &Context{PDF: pdf, PageSize: model.Size{Width: 210, Height: 297}}
+}
+
+// output returns the uncompressed PDF written for ctx
+func output(t *testing.T, ctx *Context) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := ctx.PDF.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestDrawBox(t *testing.T) {
+	element := model.Element{
+		ID:     "card",
+		Bounds: model.Bounds{Position: model.Position{X: 10, Y: 20}, Size: model.Size{Width: 100, Height: 40}},
+		Style: &model.Style{
+			Background:   "#1a73e8",
+			Border:       &model.Border{Width: 0.5, Color: "rgb(255, 0, 0)"},
+			BorderBottom: &model.Border{Width: 1, Color: "navy", Style: model.BorderDashed},
+			BorderLeft:   &model.Border{Style: model.BorderNone},
+			Padding:      &model.Padding{Top: 2, Right: 4, Bottom: 6, Left: 8},
+		},
+	}
+
+	ctx := newTestContext()
+	content, err := drawBox(ctx, element)
+	if err != nil {
+		t.Fatalf("drawBox() error = %v", err)
+	}
+
+	want := model.Bounds{Position: model.Position{X: 18, Y: 22}, Size: model.Size{Width: 88, Height: 32}}
+	if content != want {
+		t.Errorf("drawBox() = %+v, want %+v", content, want)
+	}
+
+	out := output(t, ctx)
+	for _, op := range []string{
+		"0.102 0.451 0.910 rg", // background
+		"1.000 0.000 0.000 RG", // shared border
+		"0.000 0.000 0.502 RG", // bottom border
+		"[8.50 5.67] 0.00 d",   // dashes of the 1mm bottom border
+	} {
+		if !strings.Contains(out, op) {
+			t.Errorf("page content is missing %q", op)
+		}
+	}
+	// Top, right and bottom are stroked; the left side has no border
+	if got := strings.Count(out, " l S"); got != 3 {
+		t.Errorf("drew %d border lines, want 3", got)
+	}
+}
+
+func TestDrawBox_InvalidColor(t *testing.T) {
+	tests := []struct {
+		name  string
+		style *model.Style
+	}{
+		{name: "background", style: &model.Style{Background: "#12"}},
+		{name: "border", style: &model.Style{Border: &model.Border{Width: 1, Color: "blurple"}}},
+		{name: "border style", style: &model.Style{Border: &model.Border{Width: 1, Style: "groove"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			element := model.Element{ID: "box", Style: tt.style}
+			if _, err := drawBox(newTestContext(), element); err == nil {
+				t.Error("drawBox() error = nil, want error")
+			}
+		})
+	}
+}
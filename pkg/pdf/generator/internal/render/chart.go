@@ -0,0 +1,821 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/generator/internal/binding"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/internal/color"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// Chart defaults in millimetres, with smaller text than text elements
+const (
+	chartFontSize = 8.0
+	// chartGap separates labels, the legend and the plot
+	chartGap = 1.5
+	// legendSwatch is the size of the color squares in the legend
+	legendSwatch = 2.5
+	// chartTicks is the number of value axis intervals aimed for
+	chartTicks = 5
+	// barShare is the share of each category's width taken by its bars
+	barShare = 0.7
+)
+
+// chartPalette colors series, or pie slices, that set no color of their own
+var chartPalette = []string{"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f", "#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac"}
+
+// Colors of the chart frame
+var (
+	gridColor = color.Color{R: 221, G: 221, B: 221, A: 1}
+	axisColor = color.Color{R: 102, G: 102, B: 102, A: 1}
+)
+
+// ChartRenderer handles rendering of chart elements. Content holds the
+// data, and the metadata selects the kind of chart and its series, axis
+// and legend.
+type ChartRenderer struct{}
+
+func (r *ChartRenderer) Render(ctx *Context, element model.Element) error {
+	var opts model.ChartOptions
+	if err := element.DecodeMetadata(&opts); err != nil {
+		return err
+	}
+	switch opts.Chart {
+	case model.ChartBar, model.ChartStackedBar, model.ChartLine, model.ChartArea, model.ChartPie, model.ChartDonut:
+	case "":
+		return fmt.Errorf("chart of element %s is required", element.ID)
+	default:
+		return fmt.Errorf("unknown chart %q of element %s", opts.Chart, element.ID)
+	}
+
+	data, err := readChart(opts, element.Content)
+	if err != nil {
+		return fmt.Errorf("invalid chart data of element %s: %w", element.ID, err)
+	}
+	c, err := newChart(ctx, element, opts, data)
+	if err != nil {
+		return fmt.Errorf("invalid chart %s: %w", element.ID, err)
+	}
+
+	bounds, err := drawBox(ctx, element)
+	if err != nil {
+		return err
+	}
+	if err := c.draw(bounds); err != nil {
+		return fmt.Errorf("failed to draw chart %s: %w", element.ID, err)
+	}
+	return nil
+}
+
+// chartData holds the category labels and the series of a chart
+type chartData struct {
+	categories []string
+	series     []chartSeries
+}
+
+// chartSeries is one named set of values, one per category
+type chartSeries struct {
+	name   string
+	values []float64
+	color  string
+}
+
+// readChart reads the chart data from the element content, either data
+// objects or an object with categories and series
+func readChart(opts model.ChartOptions, content interface{}) (chartData, error) {
+	switch v := content.(type) {
+	case nil:
+		return chartData{}, nil
+	case []interface{}:
+		return readChartRows(opts, v)
+	case map[string]interface{}:
+		return readChartSeries(opts, v)
+	}
+	return chartData{}, fmt.Errorf("content must be a list of data objects or an object with categories and series")
+}
+
+// readChartRows reads one category from each data object through the
+// category key and the series keys
+func readChartRows(opts model.ChartOptions, rows []interface{}) (chartData, error) {
+	scope, err := binding.NewScope(nil)
+	if err != nil {
+		return chartData{}, err
+	}
+	var category *binding.Expression
+	if opts.CategoryKey != "" {
+		if category, err = binding.Parse(opts.CategoryKey); err != nil {
+			return chartData{}, fmt.Errorf("invalid category key: %w", err)
+		}
+	}
+	if len(opts.Series) == 0 {
+		return chartData{}, fmt.Errorf("data objects need series with a key")
+	}
+	data := chartData{series: make([]chartSeries, len(opts.Series))}
+	keys := make([]*binding.Expression, len(opts.Series))
+	for i, series := range opts.Series {
+		if series.Key == "" {
+			return chartData{}, fmt.Errorf("series %d needs a key", i)
+		}
+		if keys[i], err = binding.Parse(series.Key); err != nil {
+			return chartData{}, fmt.Errorf("invalid key of series %d: %w", i, err)
+		}
+		data.series[i] = chartSeries{name: series.Name, color: series.Color}
+	}
+
+	for i, raw := range rows {
+		fields, ok := raw.(map[string]interface{})
+		if !ok {
+			return chartData{}, fmt.Errorf("row %d is not an object", i)
+		}
+		row := scope.Child(fields)
+		label := ""
+		if category != nil {
+			value, err := category.Eval(row)
+			if err != nil {
+				return chartData{}, fmt.Errorf("failed to read category of row %d: %w", i, err)
+			}
+			label = binding.Stringify(value)
+		}
+		data.categories = append(data.categories, label)
+
+		for j, key := range keys {
+			value, err := key.Eval(row)
+			if err != nil {
+				return chartData{}, fmt.Errorf("failed to read series %d of row %d: %w", j, i, err)
+			}
+			n, err := chartValue(value)
+			if err != nil {
+				return chartData{}, fmt.Errorf("series %d of row %d: %w", j, i, err)
+			}
+			data.series[j].values = append(data.series[j].values, n)
+		}
+	}
+	return data, nil
+}
+
+// readChartSeries reads explicit categories and series. Names and colors
+// missing from the content are taken from the series in the metadata.
+func readChartSeries(opts model.ChartOptions, content map[string]interface{}) (chartData, error) {
+	var data chartData
+	if raw, ok := content["categories"]; ok && raw != nil {
+		categories, ok := raw.([]interface{})
+		if !ok {
+			return data, fmt.Errorf("categories must be a list")
+		}
+		for _, category := range categories {
+			data.categories = append(data.categories, binding.Stringify(category))
+		}
+	}
+
+	list, ok := content["series"].([]interface{})
+	if !ok {
+		return data, fmt.Errorf("series must be a list")
+	}
+	for i, raw := range list {
+		fields, ok := raw.(map[string]interface{})
+		if !ok {
+			return data, fmt.Errorf("series %d is not an object", i)
+		}
+		series := chartSeries{}
+		if i < len(opts.Series) {
+			series.name, series.color = opts.Series[i].Name, opts.Series[i].Color
+		}
+		if name, ok := fields["name"]; ok {
+			series.name = binding.Stringify(name)
+		}
+		if c, ok := fields["color"].(string); ok && c != "" {
+			series.color = c
+		}
+		values, ok := fields["values"].([]interface{})
+		if !ok {
+			return data, fmt.Errorf("values of series %d must be a list", i)
+		}
+		for j, value := range values {
+			n, err := chartValue(value)
+			if err != nil {
+				return data, fmt.Errorf("value %d of series %d: %w", j, i, err)
+			}
+			series.values = append(series.values, n)
+		}
+		data.series = append(data.series, series)
+	}
+
+	// Without categories, each value of the longest series gets a blank label
+	for _, series := range data.series {
+		if len(data.categories) > 0 && len(series.values) > len(data.categories) {
+			return data, fmt.Errorf("series %q has %d values for %d categories", series.name, len(series.values), len(data.categories))
+		}
+	}
+	if len(data.categories) == 0 {
+		for _, series := range data.series {
+			for len(data.categories) < len(series.values) {
+				data.categories = append(data.categories, "")
+			}
+		}
+	}
+	// Series shorter than the categories have no value for the rest
+	for i := range data.series {
+		for len(data.series[i].values) < len(data.categories) {
+			data.series[i].values = append(data.series[i].values, 0)
+		}
+	}
+	return data, nil
+}
+
+// chartValue converts a bound value to a number, with missing values as zero
+func chartValue(value interface{}) (float64, error) {
+	if value == nil || value == "" {
+		return 0, nil
+	}
+	n, ok := binding.ToNumber(value)
+	if !ok || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("value %v is not a number", value)
+	}
+	return n, nil
+}
+
+// chart draws chart data with the element's text style
+type chart struct {
+	ctx    *Context
+	opts   model.ChartOptions
+	data   chartData
+	style  model.Style
+	face   typeface
+	format *binding.Expression
+	scope  *binding.Scope
+	// colors holds the fill of each series, or of each slice of a pie
+	colors []color.Color
+}
+
+func newChart(ctx *Context, element model.Element, opts model.ChartOptions, data chartData) (*chart, error) {
+	c := &chart{ctx: ctx, opts: opts, data: data}
+	c.style = textStyle(element.Style)
+	if element.Style == nil || element.Style.FontSize <= 0 {
+		c.style.FontSize = chartFontSize
+	}
+	c.face = styleTypeface(ctx, c.style)
+
+	scope, err := binding.NewScope(nil)
+	if err != nil {
+		return nil, err
+	}
+	c.scope = scope
+	if opts.Format != "" {
+		if c.format, err = binding.Parse("value | " + opts.Format); err != nil {
+			return nil, fmt.Errorf("invalid format: %w", err)
+		}
+	}
+	if opts.HoleSize < 0 || opts.HoleSize >= 1 {
+		return nil, fmt.Errorf("hole size must be between 0 and 1, got %v", opts.HoleSize)
+	}
+
+	palette := opts.Colors
+	if len(palette) == 0 {
+		palette = chartPalette
+	}
+	n := len(data.series)
+	if c.isPie() {
+		if len(data.series) > 1 {
+			return nil, fmt.Errorf("%s charts take one series, got %d", opts.Chart, len(data.series))
+		}
+		n = len(data.categories)
+	}
+	for i := 0; i < n; i++ {
+		spec := palette[i%len(palette)]
+		if !c.isPie() && data.series[i].color != "" {
+			spec = data.series[i].color
+		}
+		fill, err := color.Parse(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid color: %w", err)
+		}
+		c.colors = append(c.colors, fill)
+	}
+	return c, nil
+}
+
+func (c *chart) isPie() bool {
+	return c.opts.Chart == model.ChartPie || c.opts.Chart == model.ChartDonut
+}
+
+func (c *chart) draw(bounds model.Bounds) error {
+	pdf := c.ctx.PDF
+	c.face.set()
+	if _, err := setTextColor(pdf, &c.style); err != nil {
+		return err
+	}
+	defer resetStroke(pdf)
+
+	plot, err := c.drawLegend(bounds)
+	if err != nil {
+		return err
+	}
+	if len(c.data.categories) == 0 || len(c.data.series) == 0 {
+		return nil
+	}
+	if c.isPie() {
+		return c.drawPie(plot)
+	}
+	return c.drawCartesian(plot)
+}
+
+// label formats a value for the axis and value labels
+func (c *chart) label(value float64) (string, error) {
+	// Ticks computed in steps carry rounding noise such as 0.30000000000000004
+	value = math.Round(value*1e9) / 1e9
+	if c.format == nil {
+		return binding.Stringify(value), nil
+	}
+	text, err := c.format.Eval(c.scope.Child(map[string]interface{}{"value": value}))
+	if err != nil {
+		return "", fmt.Errorf("failed to format %v: %w", value, err)
+	}
+	return binding.Stringify(text), nil
+}
+
+// legendEntries returns the names shown in the legend, series names or the
+// categories of a pie
+func (c *chart) legendEntries() []string {
+	if c.isPie() {
+		return c.data.categories
+	}
+	names := make([]string, len(c.data.series))
+	for i, series := range c.data.series {
+		names[i] = series.name
+	}
+	return names
+}
+
+// drawLegend draws the legend along one side of the bounds and returns the
+// bounds left for the plot. Charts with a single series have no legend
+// unless one is placed.
+func (c *chart) drawLegend(bounds model.Bounds) (model.Bounds, error) {
+	entries := c.legendEntries()
+	position := c.opts.Legend
+	switch position {
+	case "":
+		position = model.LegendBottom
+		if !c.isPie() && len(entries) < 2 {
+			return bounds, nil
+		}
+	case model.LegendNone:
+		return bounds, nil
+	case model.LegendBottom, model.LegendTop, model.LegendRight:
+	default:
+		return bounds, fmt.Errorf("unknown legend position %q", position)
+	}
+	if len(entries) == 0 {
+		return bounds, nil
+	}
+
+	pdf := c.ctx.PDF
+	fontHeight := c.face.height()
+	rowHeight := max(fontHeight, legendSwatch) + 1
+	widths := make([]float64, len(entries))
+	for i, entry := range entries {
+		widths[i] = legendSwatch + 1 + c.face.width(entry)
+	}
+
+	// Entries flow in rows below or above the plot, or down its right side
+	type placed struct{ x, y float64 }
+	places := make([]placed, len(entries))
+	var used float64
+	if position == model.LegendRight {
+		column := 0.0
+		for _, w := range widths {
+			column = max(column, w)
+		}
+		column = min(column, bounds.Width/2)
+		x := bounds.X + bounds.Width - column
+		for i := range entries {
+			places[i] = placed{x, bounds.Y + float64(i)*rowHeight}
+		}
+		used = column + chartGap
+	} else {
+		x, row := 0.0, 0
+		for i, w := range widths {
+			if x > 0 && x+w > bounds.Width {
+				x, row = 0, row+1
+			}
+			places[i] = placed{x, float64(row) * rowHeight}
+			x += w + 2*chartGap
+		}
+		used = float64(row+1)*rowHeight + chartGap
+		top := bounds.Y
+		if position == model.LegendBottom {
+			top = bounds.Y + bounds.Height - float64(row+1)*rowHeight
+		}
+		for i := range places {
+			places[i].x += bounds.X
+			places[i].y += top
+		}
+	}
+
+	for i, entry := range entries {
+		p := places[i]
+		fill := c.colors[i%len(c.colors)]
+		pdf.SetFillColor(fill.R, fill.G, fill.B)
+		pdf.Rect(p.x, p.y+(rowHeight-1-legendSwatch)/2, legendSwatch, legendSwatch, "F")
+		c.face.text(p.x+legendSwatch+1, p.y+(rowHeight-1)/2+fontHeight*0.35, entry)
+	}
+
+	plot := bounds
+	switch position {
+	case model.LegendRight:
+		plot.Width -= used
+	case model.LegendTop:
+		plot.Y += used
+		plot.Height -= used
+	default:
+		plot.Height -= used
+	}
+	return plot, nil
+}
+
+// valueRange returns the lowest and highest values drawn, the sums of each
+// category when bars are stacked
+func (c *chart) valueRange() (float64, float64) {
+	lo, hi := 0.0, 0.0
+	for i := range c.data.categories {
+		positive, negative := 0.0, 0.0
+		for _, series := range c.data.series {
+			v := series.values[i]
+			if c.opts.Chart == model.ChartStackedBar {
+				if v >= 0 {
+					positive += v
+				} else {
+					negative += v
+				}
+				continue
+			}
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		lo, hi = min(lo, negative), max(hi, positive)
+	}
+	return lo, hi
+}
+
+// niceScale returns axis ends and a tick step of 1, 2 or 5 times a power of
+// ten that cover the range in about the given number of intervals. Ends
+// that are fixed are kept.
+func niceScale(lo, hi float64, fixedMin, fixedMax *float64, ticks int) (float64, float64, float64) {
+	if fixedMin != nil {
+		lo = *fixedMin
+	}
+	if fixedMax != nil {
+		hi = *fixedMax
+	}
+	if hi <= lo {
+		hi = lo + 1
+	}
+
+	raw := (hi - lo) / float64(ticks)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude * 10
+	for _, f := range []float64{1, 2, 5} {
+		if raw <= f*magnitude {
+			step = f * magnitude
+			break
+		}
+	}
+	if fixedMin == nil {
+		lo = math.Floor(lo/step) * step
+	}
+	if fixedMax == nil {
+		hi = math.Ceil(hi/step) * step
+	}
+	return lo, hi, step
+}
+
+// axisTicks returns the multiples of step from lo to hi
+func axisTicks(lo, hi, step float64) []float64 {
+	var ticks []float64
+	for v := math.Ceil(lo/step-1e-9) * step; v <= hi+step*1e-9; v += step {
+		ticks = append(ticks, v)
+	}
+	return ticks
+}
+
+// drawCartesian draws the value axis and gridlines, the category labels and
+// the series as bars, lines or areas
+func (c *chart) drawCartesian(bounds model.Bounds) error {
+	pdf := c.ctx.PDF
+	lo, hi := c.valueRange()
+	lo, hi, step := niceScale(lo, hi, c.opts.Min, c.opts.Max, chartTicks)
+
+	ticks := axisTicks(lo, hi, step)
+	labels := make([]string, len(ticks))
+	labelWidth := 0.0
+	for i, v := range ticks {
+		text, err := c.label(v)
+		if err != nil {
+			return err
+		}
+		labels[i] = text
+		labelWidth = max(labelWidth, c.face.width(text))
+	}
+
+	fontHeight := c.face.height()
+	plot := bounds
+	plot.X += labelWidth + chartGap
+	plot.Width -= labelWidth + chartGap
+	// Leave room for the top tick label and the category labels
+	plot.Y += fontHeight / 2
+	plot.Height -= fontHeight/2 + fontHeight + chartGap
+	if plot.Width <= 0 || plot.Height <= 0 {
+		return fmt.Errorf("chart is too small for its labels")
+	}
+	y := func(v float64) float64 {
+		return plot.Y + plot.Height - (v-lo)/(hi-lo)*plot.Height
+	}
+
+	gridlines := c.opts.Gridlines == nil || *c.opts.Gridlines
+	pdf.SetLineCapStyle("butt")
+	pdf.SetDashPattern(nil, 0)
+	for i, v := range ticks {
+		if gridlines {
+			pdf.SetLineWidth(0.1)
+			pdf.SetDrawColor(gridColor.R, gridColor.G, gridColor.B)
+			pdf.Line(plot.X, y(v), plot.X+plot.Width, y(v))
+		}
+		c.face.text(plot.X-chartGap-c.face.width(labels[i]), y(v)+fontHeight*0.35, labels[i])
+	}
+
+	// Category labels are thinned out when they would overlap
+	n := len(c.data.categories)
+	group := plot.Width / float64(n)
+	widest := 0.0
+	for _, category := range c.data.categories {
+		widest = max(widest, c.face.width(category))
+	}
+	every := max(1, int(math.Ceil((widest+chartGap)/group)))
+	for i := 0; i < n; i += every {
+		text := c.data.categories[i]
+		center := plot.X + (float64(i)+0.5)*group
+		c.face.text(center-c.face.width(text)/2, plot.Y+plot.Height+chartGap+fontHeight*0.8, text)
+	}
+
+	pdf.ClipRect(plot.X, plot.Y, plot.Width, plot.Height, false)
+	var err error
+	switch c.opts.Chart {
+	case model.ChartBar, model.ChartStackedBar:
+		err = c.drawBars(plot, group, y)
+	default:
+		err = c.drawLines(plot, group, y)
+	}
+	pdf.ClipEnd()
+	if err != nil {
+		return err
+	}
+
+	// The axes sit on top of the series, the category axis at zero
+	base := y(min(max(0, lo), hi))
+	pdf.SetLineWidth(0.2)
+	pdf.SetDrawColor(axisColor.R, axisColor.G, axisColor.B)
+	pdf.Line(plot.X, plot.Y, plot.X, plot.Y+plot.Height)
+	pdf.Line(plot.X, base, plot.X+plot.Width, base)
+	return nil
+}
+
+// chartBar is a bar of a bar chart and the value it shows
+type chartBar struct {
+	bounds model.Bounds
+	series int
+	value  float64
+}
+
+// drawBars draws the series side by side in each category, or stacked
+func (c *chart) drawBars(plot model.Bounds, group float64, y func(float64) float64) error {
+	pdf := c.ctx.PDF
+	stacked := c.opts.Chart == model.ChartStackedBar
+	for _, bar := range layoutBars(c.data.series, len(c.data.categories), stacked, plot, group, y) {
+		fill := c.colors[bar.series]
+		pdf.SetFillColor(fill.R, fill.G, fill.B)
+		pdf.Rect(bar.bounds.X, bar.bounds.Y, bar.bounds.Width, bar.bounds.Height, "F")
+
+		if c.opts.ShowValues && bar.value != 0 {
+			text, err := c.label(bar.value)
+			if err != nil {
+				return err
+			}
+			top, bottom := bar.bounds.Y, bar.bounds.Y+bar.bounds.Height
+			ty := top - 0.5
+			if stacked {
+				ty = (top+bottom)/2 + c.face.height()*0.35
+			} else if bar.value < 0 {
+				ty = bottom + c.face.height()*0.8 + 0.5
+			}
+			c.face.text(bar.bounds.X+(bar.bounds.Width-c.face.width(text))/2, ty, text)
+		}
+	}
+	return nil
+}
+
+// layoutBars places the bars of the series in categories of the given
+// width, side by side or stacked. y maps values to the page.
+func layoutBars(series []chartSeries, categories int, stacked bool, plot model.Bounds, group float64, y func(float64) float64) []chartBar {
+	width := group * barShare
+	if !stacked {
+		width /= float64(len(series))
+	}
+
+	var bars []chartBar
+	for i := 0; i < categories; i++ {
+		left := plot.X + float64(i)*group + group*(1-barShare)/2
+		positive, negative := 0.0, 0.0
+		for j, s := range series {
+			v := s.values[i]
+			x, from := left+float64(j)*width, 0.0
+			if stacked {
+				x = left
+				if v >= 0 {
+					from, positive = positive, positive+v
+				} else {
+					from, negative = negative, negative+v
+				}
+			}
+			top, bottom := min(y(from), y(from+v)), max(y(from), y(from+v))
+			bars = append(bars, chartBar{
+				bounds: model.Bounds{Position: model.Position{X: x, Y: top}, Size: model.Size{Width: width, Height: bottom - top}},
+				series: j,
+				value:  v,
+			})
+		}
+	}
+	return bars
+}
+
+// drawLines draws each series as a line through the category centers, and
+// for area charts fills the space down to zero
+func (c *chart) drawLines(plot model.Bounds, group float64, y func(float64) float64) error {
+	pdf := c.ctx.PDF
+	base := min(max(y(0), plot.Y), plot.Y+plot.Height)
+	pdf.SetLineJoinStyle("round")
+	defer pdf.SetLineJoinStyle("miter")
+
+	for j, series := range c.data.series {
+		points := linePoints(series.values, plot, group, y)
+		stroke := c.colors[j]
+
+		if c.opts.Chart == model.ChartArea && len(points) > 1 {
+			outline := areaOutline(points, base)
+			pdf.SetAlpha(0.3, "Normal")
+			pdf.SetFillColor(stroke.R, stroke.G, stroke.B)
+			tracePath(pdf, polylinePath(outline, true), model.Position{})
+			pdf.DrawPath("f")
+			pdf.SetAlpha(1, "Normal")
+		}
+
+		pdf.SetLineWidth(0.5)
+		pdf.SetDrawColor(stroke.R, stroke.G, stroke.B)
+		tracePath(pdf, polylinePath(points, false), model.Position{})
+		pdf.DrawPath("D")
+		if c.opts.Chart == model.ChartLine {
+			pdf.SetFillColor(stroke.R, stroke.G, stroke.B)
+			for _, p := range points {
+				pdf.Circle(p.X, p.Y, 0.6, "F")
+			}
+		}
+
+		if c.opts.ShowValues {
+			for i, v := range series.values {
+				text, err := c.label(v)
+				if err != nil {
+					return err
+				}
+				c.face.text(points[i].X-c.face.width(text)/2, points[i].Y-1, text)
+			}
+		}
+	}
+	return nil
+}
+
+// linePoints returns where the values of a series sit over the centers of
+// categories of the given width
+func linePoints(values []float64, plot model.Bounds, group float64, y func(float64) float64) []model.Position {
+	points := make([]model.Position, len(values))
+	for i, v := range values {
+		points[i] = model.Position{X: plot.X + (float64(i)+0.5)*group, Y: y(v)}
+	}
+	return points
+}
+
+// areaOutline closes the points of a line down to the base
+func areaOutline(points []model.Position, base float64) []model.Position {
+	outline := append([]model.Position{{X: points[0].X, Y: base}}, points...)
+	return append(outline, model.Position{X: points[len(points)-1].X, Y: base})
+}
+
+// pieSlice is the part of a pie one value takes, from the start angle
+// through sweep in radians
+type pieSlice struct {
+	index        int
+	start, sweep float64
+	// share is the fraction of the total
+	share float64
+}
+
+// drawPie draws the slices of the first series clockwise from the top,
+// leaving a hole in the middle of donut charts
+func (c *chart) drawPie(bounds model.Bounds) error {
+	pdf := c.ctx.PDF
+	slices, err := pieSlices(c.data.series[0].values)
+	if err != nil {
+		return fmt.Errorf("%s chart %w", c.opts.Chart, err)
+	}
+	center, radius, hole := pieCircle(bounds, c.opts)
+
+	// Slices are parted by thin white lines
+	pdf.SetLineWidth(0.3)
+	pdf.SetDrawColor(255, 255, 255)
+	pdf.SetLineJoinStyle("round")
+	defer pdf.SetLineJoinStyle("miter")
+	for _, slice := range slices {
+		fill := c.colors[slice.index]
+		pdf.SetFillColor(fill.R, fill.G, fill.B)
+		tracePath(pdf, sectorPath(center, radius, hole, slice.start, slice.sweep), model.Position{})
+		pdf.DrawPath("B")
+	}
+
+	if !c.opts.ShowValues {
+		return nil
+	}
+	for _, slice := range slices {
+		text := strconv.FormatFloat(math.Round(slice.share*100), 'f', 0, 64) + "%"
+		at := pieLabelPoint(center, radius, hole, slice)
+		c.face.text(at.X-c.face.width(text)/2, at.Y+c.face.height()*0.35, text)
+	}
+	return nil
+}
+
+// pieSlices parts a circle between the values clockwise from the top,
+// leaving out zeros
+func pieSlices(values []float64) ([]pieSlice, error) {
+	total := 0.0
+	for i, v := range values {
+		if v < 0 {
+			return nil, fmt.Errorf("value %d is negative", i)
+		}
+		total += v
+	}
+	if total == 0 {
+		return nil, nil
+	}
+
+	var slices []pieSlice
+	angle := -math.Pi / 2
+	for i, v := range values {
+		if v == 0 {
+			continue
+		}
+		share := v / total
+		slices = append(slices, pieSlice{index: i, start: angle, sweep: share * 2 * math.Pi, share: share})
+		angle += share * 2 * math.Pi
+	}
+	return slices, nil
+}
+
+// pieCircle returns the center and radius of the largest circle in the
+// bounds, and the radius of the hole of a donut
+func pieCircle(bounds model.Bounds, opts model.ChartOptions) (model.Position, float64, float64) {
+	center := model.Position{X: bounds.X + bounds.Width/2, Y: bounds.Y + bounds.Height/2}
+	radius := min(bounds.Width, bounds.Height) / 2
+	hole := 0.0
+	if opts.Chart == model.ChartDonut {
+		hole = radius * 0.5
+		if opts.HoleSize > 0 {
+			hole = radius * opts.HoleSize
+		}
+	}
+	return center, radius, hole
+}
+
+// pieLabelPoint returns where the share of a slice is shown: halfway across
+// a ring, or toward the rim of a pie
+func pieLabelPoint(center model.Position, radius, hole float64, slice pieSlice) model.Position {
+	distance := (radius + hole) / 2
+	if hole == 0 {
+		distance = radius * 0.6
+	}
+	return arcPoint(center, distance, distance, slice.start+slice.sweep/2)
+}
+
+// sectorPath outlines a slice of a circle, or of a ring when hole is set
+func sectorPath(center model.Position, radius, hole, start, sweep float64) []pathSegment {
+	var segments []pathSegment
+	if hole > 0 {
+		segments = append(segments, pathSegment{op: pathMove, to: arcPoint(center, hole, hole, start)})
+		segments = append(segments, pathSegment{op: pathLine, to: arcPoint(center, radius, radius, start)})
+	} else {
+		segments = append(segments, pathSegment{op: pathMove, to: center})
+		segments = append(segments, pathSegment{op: pathLine, to: arcPoint(center, radius, radius, start)})
+	}
+	segments = append(segments, arcPath(center, radius, radius, start, sweep)...)
+	if hole > 0 {
+		segments = append(segments, pathSegment{op: pathLine, to: arcPoint(center, hole, hole, start+sweep)})
+		segments = append(segments, arcPath(center, hole, hole, start+sweep, -sweep)...)
+	}
+	return append(segments, pathSegment{op: pathClose})
+}
@@ -0,0 +1,373 @@
+package render
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+func TestReadChart(t *testing.T) {
+	revenue := []model.ChartSeries{{Name: "Revenue", Key: "totals.revenue"}, {Name: "Costs", Key: "costs", Color: "#ff0000"}}
+
+	tests := []struct {
+		name           string
+		opts           model.ChartOptions
+		content        string
+		wantCategories []string
+		wantSeries     []chartSeries
+		wantErr        string
+	}{
+		{
+			name:           "data objects",
+			opts:           model.ChartOptions{CategoryKey: "month", Series: revenue},
+			content:        `[{"month": "Jan", "totals": {"revenue": 10}, "costs": "4.5"}, {"month": "Feb", "totals": {"revenue": 12}}]`,
+			wantCategories: []string{"Jan", "Feb"},
+			wantSeries:     []chartSeries{{name: "Revenue", values: []float64{10, 12}}, {name: "Costs", values: []float64{4.5, 0}, color: "#ff0000"}},
+		},
+		{
+			name:           "explicit series",
+			opts:           model.ChartOptions{Series: []model.ChartSeries{{Name: "Fallback", Color: "#00ff00"}}},
+			content:        `{"categories": ["Q1", "Q2", "Q3"], "series": [{"values": [1, 2, 3]}, {"name": "Target", "values": [2, 2]}]}`,
+			wantCategories: []string{"Q1", "Q2", "Q3"},
+			wantSeries:     []chartSeries{{name: "Fallback", values: []float64{1, 2, 3}, color: "#00ff00"}, {name: "Target", values: []float64{2, 2, 0}}},
+		},
+		{
+			name:           "series without categories",
+			content:        `{"series": [{"name": "Sales", "values": [5, 6]}]}`,
+			wantCategories: []string{"", ""},
+			wantSeries:     []chartSeries{{name: "Sales", values: []float64{5, 6}}},
+		},
+		{name: "no content", content: `null`},
+		{name: "data objects without series", content: `[{"month": "Jan"}]`, wantErr: "need series with a key"},
+		{name: "series without key", opts: model.ChartOptions{Series: []model.ChartSeries{{Name: "Revenue"}}}, content: `[]`, wantErr: "needs a key"},
+		{name: "not a number", opts: model.ChartOptions{Series: revenue}, content: `[{"totals": {"revenue": "lots"}}]`, wantErr: "is not a number"},
+		{name: "too many values", content: `{"categories": ["Q1"], "series": [{"name": "Sales", "values": [1, 2]}]}`, wantErr: "2 values for 1 categories"},
+		{name: "text content", content: `"sales"`, wantErr: "must be a list of data objects"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var content interface{}
+			if err := json.Unmarshal([]byte(tt.content), &content); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readChart(tt.opts, content)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("readChart() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readChart() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.categories, tt.wantCategories) {
+				t.Errorf("categories = %q, want %q", got.categories, tt.wantCategories)
+			}
+			if !reflect.DeepEqual(got.series, tt.wantSeries) {
+				t.Errorf("series = %+v, want %+v", got.series, tt.wantSeries)
+			}
+		})
+	}
+}
+
+func TestNiceScale(t *testing.T) {
+	fixed := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name               string
+		lo, hi             float64
+		fixedMin, fixedMax *float64
+		want               [3]float64
+	}{
+		{name: "round ends", lo: 0, hi: 87, want: [3]float64{0, 100, 20}},
+		{name: "negative values", lo: -12, hi: 30, want: [3]float64{-20, 30, 10}},
+		{name: "small values", lo: 0, hi: 0.42, want: [3]float64{0, 0.5, 0.1}},
+		{name: "empty range", lo: 0, hi: 0, want: [3]float64{0, 1, 0.2}},
+		{name: "fixed ends", lo: 0, hi: 87, fixedMin: fixed(50), fixedMax: fixed(90), want: [3]float64{50, 90, 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lo, hi, step := niceScale(tt.lo, tt.hi, tt.fixedMin, tt.fixedMax, chartTicks)
+			for i, got := range []float64{lo, hi, step} {
+				if math.Abs(got-tt.want[i]) > 1e-9 {
+					t.Errorf("niceScale() = %v, %v, %v, want %v", lo, hi, step, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func chartElement(t *testing.T, metadata, content string) model.Element {
+	t.Helper()
+	element := model.Element{
+		ID:       "sales",
+		Type:     model.ElementTypeChart,
+		Bounds:   model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 120, Height: 70}},
+		Metadata: json.RawMessage(metadata),
+	}
+	if err := json.Unmarshal([]byte(content), &element.Content); err != nil {
+		t.Fatal(err)
+	}
+	return element
+}
+
+func TestChartRenderer(t *testing.T) {
+	months := `[{"month": "Jan", "revenue": 120, "costs": 80}, {"month": "Feb", "revenue": 150, "costs": -20}, {"month": "Mar", "revenue": 90, "costs": 60}]`
+	series := `"categoryKey": "month", "series": [{"name": "Revenue", "key": "revenue"}, {"name": "Costs", "key": "costs"}]`
+	shares := `{"categories": ["North", "South", "East"], "series": [{"values": [50, 30, 20]}]}`
+
+	tests := []struct {
+		name     string
+		metadata string
+		content  string
+		wantErr  string
+	}{
+		{name: "bar", metadata: `{"chart": "bar", ` + series + `, "showValues": true}`, content: months},
+		{name: "stacked bar", metadata: `{"chart": "stackedBar", ` + series + `, "showValues": true}`, content: months},
+		{name: "line", metadata: `{"chart": "line", ` + series + `, "gridlines": false, "legend": "right"}`, content: months},
+		{name: "area", metadata: `{"chart": "area", ` + series + `}`, content: months},
+		{name: "pie", metadata: `{"chart": "pie", "showValues": true}`, content: shares},
+		{name: "donut", metadata: `{"chart": "donut", "holeSize": 0.6, "showValues": true}`, content: shares},
+		{name: "missing kind", metadata: `{}`, content: shares, wantErr: "is required"},
+		{name: "unknown kind", metadata: `{"chart": "radar"}`, content: shares, wantErr: "unknown chart"},
+		{name: "pie with two series", metadata: `{"chart": "pie", ` + series + `}`, content: months, wantErr: "take one series"},
+		{name: "negative slice", metadata: `{"chart": "pie"}`, content: `{"series": [{"values": [5, -1]}]}`, wantErr: "pie chart value 1 is negative"},
+		{name: "invalid color", metadata: `{"chart": "bar", "colors": ["nope"]}`, content: shares, wantErr: "invalid color"},
+		{name: "unknown legend", metadata: `{"chart": "pie", "legend": "left"}`, content: shares, wantErr: "unknown legend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&ChartRenderer{}).Render(newTestContext(), chartElement(t, tt.metadata, tt.content))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Render() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Render() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChart_Label(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		value  float64
+		want   string
+	}{
+		{name: "plain", value: 150, want: "150"},
+		{name: "rounding noise", value: 0.1 + 0.2, want: "0.3"},
+		{name: "currency", format: `currency "USD"`, value: 200, want: "$200.00"},
+		{name: "negative currency", format: `currency "USD"`, value: -50, want: "-$50.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := model.ChartOptions{Chart: model.ChartBar, Format: tt.format}
+			c, err := newChart(newTestContext(), model.Element{}, opts, chartData{})
+			if err != nil {
+				t.Fatalf("newChart() error = %v", err)
+			}
+			got, err := c.label(tt.value)
+			if err != nil {
+				t.Fatalf("label() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("label() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChart_ValueRange(t *testing.T) {
+	data := chartData{
+		categories: []string{"Jan", "Feb"},
+		series:     []chartSeries{{values: []float64{10, 20}}, {values: []float64{5, -10}}, {values: []float64{8, 0}}},
+	}
+
+	tests := []struct {
+		kind   model.ChartKind
+		lo, hi float64
+	}{
+		{kind: model.ChartBar, lo: -10, hi: 20},
+		// January stacks to 23
+		{kind: model.ChartStackedBar, lo: -10, hi: 23},
+	}
+	for _, tt := range tests {
+		c := &chart{opts: model.ChartOptions{Chart: tt.kind}, data: data}
+		if lo, hi := c.valueRange(); lo != tt.lo || hi != tt.hi {
+			t.Errorf("%s valueRange() = %v, %v, want %v, %v", tt.kind, lo, hi, tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestAxisTicks(t *testing.T) {
+	if got, want := axisTicks(-50, 150, 50), []float64{-50, 0, 50, 100, 150}; !reflect.DeepEqual(got, want) {
+		t.Errorf("axisTicks() = %v, want %v", got, want)
+	}
+	// Steps that do not add up exactly still reach the end
+	if got := axisTicks(0, 1, 0.2); len(got) != 6 {
+		t.Errorf("axisTicks() = %v, want 6 ticks", got)
+	}
+}
+
+func TestLayoutBars(t *testing.T) {
+	// Categories 20mm wide from x 10, with zero at y 50
+	plot := model.Bounds{Position: model.Position{X: 10}, Size: model.Size{Width: 40, Height: 100}}
+	y := func(v float64) float64 { return 50 - v }
+	series := []chartSeries{{values: []float64{10, 20}}, {values: []float64{5, -10}}}
+	bar := func(x, y, w, h float64, series int, value float64) chartBar {
+		return chartBar{bounds: model.Bounds{Position: model.Position{X: x, Y: y}, Size: model.Size{Width: w, Height: h}}, series: series, value: value}
+	}
+
+	tests := []struct {
+		name    string
+		stacked bool
+		want    []chartBar
+	}{
+		{
+			// 0.7 of each category is bars, two 7mm bars after a 3mm gap
+			name: "side by side",
+			want: []chartBar{
+				bar(13, 40, 7, 10, 0, 10), bar(20, 45, 7, 5, 1, 5),
+				bar(33, 30, 7, 20, 0, 20), bar(40, 50, 7, 10, 1, -10),
+			},
+		},
+		{
+			// Negative values stack down from zero apart from positive ones
+			name:    "stacked",
+			stacked: true,
+			want: []chartBar{
+				bar(13, 40, 14, 10, 0, 10), bar(13, 35, 14, 5, 1, 5),
+				bar(33, 30, 14, 20, 0, 20), bar(33, 50, 14, 10, 1, -10),
+			},
+		},
+	}
+
+	near := func(a, b model.Bounds) bool {
+		return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9 && math.Abs(a.Width-b.Width) < 1e-9 && math.Abs(a.Height-b.Height) < 1e-9
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := layoutBars(series, 2, tt.stacked, plot, 20, y)
+			if len(got) != len(tt.want) {
+				t.Fatalf("layoutBars() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if !near(got[i].bounds, tt.want[i].bounds) || got[i].series != tt.want[i].series || got[i].value != tt.want[i].value {
+					t.Errorf("bar %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLinePoints(t *testing.T) {
+	plot := model.Bounds{Position: model.Position{X: 10}, Size: model.Size{Width: 40, Height: 100}}
+	y := func(v float64) float64 { return 50 - v }
+
+	points := linePoints([]float64{10, 20}, plot, 20, y)
+	if want := []model.Position{{X: 20, Y: 40}, {X: 40, Y: 30}}; !reflect.DeepEqual(points, want) {
+		t.Errorf("linePoints() = %v, want %v", points, want)
+	}
+	outline := areaOutline(points, 50)
+	if want := []model.Position{{X: 20, Y: 50}, {X: 20, Y: 40}, {X: 40, Y: 30}, {X: 40, Y: 50}}; !reflect.DeepEqual(outline, want) {
+		t.Errorf("areaOutline() = %v, want %v", outline, want)
+	}
+}
+
+func TestPieSlices(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []float64
+		want    []pieSlice
+		wantErr bool
+	}{
+		{
+			// Clockwise from the top, leaving out the zero
+			name:   "shares",
+			values: []float64{50, 0, 30, 20},
+			want: []pieSlice{
+				{index: 0, start: -math.Pi / 2, sweep: math.Pi, share: 0.5},
+				{index: 2, start: math.Pi / 2, sweep: 0.6 * math.Pi, share: 0.3},
+				{index: 3, start: 1.1 * math.Pi, sweep: 0.4 * math.Pi, share: 0.2},
+			},
+		},
+		{name: "all zero", values: []float64{0, 0}},
+		{name: "negative", values: []float64{5, -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pieSlices(tt.values)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("pieSlices() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pieSlices() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("pieSlices() = %+v, want %+v", got, tt.want)
+			}
+			for i, slice := range got {
+				want := tt.want[i]
+				if slice.index != want.index || math.Abs(slice.start-want.start) > 1e-9 || math.Abs(slice.sweep-want.sweep) > 1e-9 || math.Abs(slice.share-want.share) > 1e-9 {
+					t.Errorf("slice %d = %+v, want %+v", i, slice, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPieCircle(t *testing.T) {
+	bounds := model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 120, Height: 70}}
+	tests := []struct {
+		opts     model.ChartOptions
+		wantHole float64
+	}{
+		{opts: model.ChartOptions{Chart: model.ChartPie}},
+		{opts: model.ChartOptions{Chart: model.ChartDonut}, wantHole: 17.5},
+		{opts: model.ChartOptions{Chart: model.ChartDonut, HoleSize: 0.6}, wantHole: 21},
+	}
+	for _, tt := range tests {
+		center, radius, hole := pieCircle(bounds, tt.opts)
+		if center != (model.Position{X: 70, Y: 45}) || radius != 35 || math.Abs(hole-tt.wantHole) > 1e-9 {
+			t.Errorf("pieCircle(%+v) = %v, %v, %v, want {70 45}, 35, %v", tt.opts, center, radius, hole, tt.wantHole)
+		}
+	}
+
+	// Shares sit toward the rim of a pie and halfway across a ring
+	half := pieSlice{start: -math.Pi / 2, sweep: math.Pi}
+	if at := pieLabelPoint(model.Position{}, 10, 0, half); math.Abs(at.X-6) > 1e-9 || math.Abs(at.Y) > 1e-9 {
+		t.Errorf("pieLabelPoint() = %+v, want {6 0}", at)
+	}
+	if at := pieLabelPoint(model.Position{}, 10, 6, half); math.Abs(at.X-8) > 1e-9 || math.Abs(at.Y) > 1e-9 {
+		t.Errorf("pieLabelPoint() = %+v, want {8 0}", at)
+	}
+}
+
+func TestArcPath(t *testing.T) {
+	center := model.Position{X: 10, Y: 10}
+	segments := arcPath(center, 5, 5, -math.Pi/2, math.Pi)
+	if len(segments) != 2 {
+		t.Fatalf("arcPath() made %d curves for half a circle, want 2", len(segments))
+	}
+	// Clockwise from the top through the right to the bottom
+	if mid, end := segments[0].to, segments[1].to; math.Abs(mid.X-15) > 1e-9 || math.Abs(mid.Y-10) > 1e-9 || math.Abs(end.X-10) > 1e-9 || math.Abs(end.Y-15) > 1e-9 {
+		t.Errorf("arcPath() ends at %+v and %+v, want {15 10} and {10 15}", mid, end)
+	}
+}
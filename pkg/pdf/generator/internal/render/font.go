@@ -0,0 +1,225 @@
+package render
+
+import (
+	"unicode/utf8"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/fonts"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// cp1252 lists the characters above Latin-1 that the Windows-1252 code
+// page, used by the gofpdf core fonts, can encode
+var cp1252 = map[rune]bool{
+	'€': true, '‚': true, 'ƒ': true, '„': true, '…': true, '†': true, '‡': true, 'ˆ': true,
+	'‰': true, 'Š': true, '‹': true, 'Œ': true, 'Ž': true, '‘': true, '’': true, '“': true,
+	'”': true, '•': true, '–': true, '—': true, '˜': true, '™': true, 'š': true, '›': true,
+	'œ': true, 'ž': true, 'Ÿ': true,
+}
+
+// coreHas reports whether a core font can draw r
+func coreHas(r rune) bool {
+	return r < 0x80 || (r >= 0xA0 && r <= 0xFF) || cp1252[r]
+}
+
+// fontRun is a stretch of text drawn with one font; a nil font is the
+// typeface's core font
+type fontRun struct {
+	font *fonts.Font
+	text string
+}
+
+// typeface measures and draws Unicode text in one family, style and size.
+// Families registered with the context's font registry are embedded as
+// UTF-8 fonts; any other family is a gofpdf core font, for which text is
+// translated to Windows-1252. Characters the font cannot draw are taken
+// from the registry's fallback fonts.
+type typeface struct {
+	ctx    *Context
+	family string
+	style  fonts.Style
+	size   float64
+	// font is the registered font of the family, nil for core fonts
+	font *fonts.Font
+	// spacing is the extra space after each character in document units
+	spacing   float64
+	underline bool
+	strike    bool
+}
+
+func newTypeface(ctx *Context, family string, style fonts.Style, size float64) typeface {
+	font, _ := ctx.Fonts.Lookup(family, style)
+	return typeface{ctx: ctx, family: family, style: style, size: size, font: font}
+}
+
+// Decoration line positions and thickness as a share of the font size,
+// matching the metrics of the core Helvetica font
+const (
+	underlineOffset     = 0.1
+	strikeOffset        = -0.3
+	decorationThickness = 0.05
+)
+
+// styleVariant returns the font variant selected by a style's weight and slant
+func styleVariant(style *model.Style) fonts.Style {
+	if style == nil {
+		return fonts.Regular
+	}
+	switch bold, italic := style.FontWeight.Bold(), style.FontStyle.Italic(); {
+	case bold && italic:
+		return fonts.BoldItalic
+	case bold:
+		return fonts.Bold
+	case italic:
+		return fonts.Italic
+	}
+	return fonts.Regular
+}
+
+// withSize returns the same typeface at another size in points
+func (t typeface) withSize(size float64) typeface {
+	t.size = size
+	return t
+}
+
+// set selects the typeface's own font in the PDF
+func (t typeface) set() {
+	t.use(t.font)
+}
+
+// use selects a registered font, embedding it on first use, or the core
+// font when font is nil
+func (t typeface) use(font *fonts.Font) {
+	pdf := t.ctx.PDF
+	if font == nil {
+		pdf.SetFont(t.family, string(t.style), t.size)
+		return
+	}
+	pdf.AddUTF8FontFromBytes(font.Family, string(font.Style), font.Data())
+	pdf.SetFont(font.Family, string(font.Style), t.size)
+}
+
+// height returns the font size in document units
+func (t typeface) height() float64 {
+	return t.ctx.PDF.PointToUnitConvert(t.size)
+}
+
+// width returns the width of s in document units
+func (t typeface) width(s string) float64 {
+	shaped := fonts.Shape(s)
+	spacing := t.spacing * float64(utf8.RuneCountInString(shaped))
+
+	runs := t.runs(shaped)
+	if len(runs) == 1 && runs[0].font == t.font {
+		t.set()
+		return t.ctx.PDF.GetStringWidth(t.encode(runs[0])) + spacing
+	}
+
+	width := spacing
+	for _, run := range runs {
+		t.use(run.font)
+		width += t.ctx.PDF.GetStringWidth(t.encode(run))
+	}
+	t.set()
+	return width
+}
+
+// text draws s with its left end at x and its baseline at y, followed by
+// the typeface's decoration
+func (t typeface) text(x, y float64, s string) {
+	end := t.write(x, y, s)
+	t.decorate(x, y, end-x)
+}
+
+// write draws s without decoration and returns the x where it ends
+func (t typeface) write(x, y float64, s string) float64 {
+	pdf := t.ctx.PDF
+	for _, run := range t.runs(fonts.Visual(fonts.Shape(s))) {
+		t.use(run.font)
+		if t.spacing == 0 {
+			encoded := t.encode(run)
+			pdf.Text(x, y, encoded)
+			x += pdf.GetStringWidth(encoded)
+			continue
+		}
+
+		// Spaced text is drawn one character at a time
+		for _, r := range run.text {
+			encoded := t.encode(fontRun{font: run.font, text: string(r)})
+			pdf.Text(x, y, encoded)
+			x += pdf.GetStringWidth(encoded) + t.spacing
+		}
+	}
+	t.set()
+	return x
+}
+
+// decorate draws the underline and line-through of text of the given width
+// starting at x on the baseline y, in the current text color
+func (t typeface) decorate(x, y, width float64) {
+	if (!t.underline && !t.strike) || width <= 0 {
+		return
+	}
+
+	pdf := t.ctx.PDF
+	fr, fg, fb := pdf.GetFillColor()
+	pdf.SetFillColor(pdf.GetTextColor())
+	defer pdf.SetFillColor(fr, fg, fb)
+
+	height := t.height()
+	if t.underline {
+		pdf.Rect(x, y+height*underlineOffset, width, height*decorationThickness, "F")
+	}
+	if t.strike {
+		pdf.Rect(x, y+height*strikeOffset, width, height*decorationThickness, "F")
+	}
+}
+
+// runs splits s into stretches that share a font, choosing the first font
+// in the fallback chain that has each character
+func (t typeface) runs(s string) []fontRun {
+	var fallbacks []*fonts.Font
+	var runs []fontRun
+	var current *fonts.Font
+	start := 0
+
+	for i, r := range s {
+		font := t.font
+		if !t.has(r) {
+			if fallbacks == nil {
+				fallbacks = t.ctx.Fonts.Fallbacks(t.style)
+			}
+			for _, fallback := range fallbacks {
+				if fallback.Has(r) {
+					font = fallback
+					break
+				}
+			}
+		}
+
+		if i > 0 && font != current {
+			runs = append(runs, fontRun{font: current, text: s[start:i]})
+			start = i
+		}
+		current = font
+	}
+	return append(runs, fontRun{font: current, text: s[start:]})
+}
+
+// has reports whether the typeface's own font can draw r
+func (t typeface) has(r rune) bool {
+	if t.font == nil {
+		return coreHas(r)
+	}
+	return r == ' ' || t.font.Has(r)
+}
+
+// encode converts run text to the encoding its font expects
+func (t typeface) encode(run fontRun) string {
+	if run.font != nil {
+		return run.text
+	}
+	if t.ctx.translate == nil {
+		t.ctx.translate = t.ctx.PDF.UnicodeTranslatorFromDescriptor("")
+	}
+	return t.ctx.translate(run.text)
+}
@@ -0,0 +1,84 @@
+package render
+
+import (
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/fonts"
+)
+
+// coverageFont returns a minimal TrueType file whose format 12 cmap maps
+// the characters from lo to hi
+func coverageFont(lo, hi rune) []byte {
+	u32 := func(b []byte, v uint32) []byte { return binary.BigEndian.AppendUint32(b, v) }
+
+	sub := []byte{0, 12, 0, 0}
+	sub = u32(sub, 28)
+	sub = u32(sub, 0)
+	sub = u32(sub, 1)
+	sub = u32(sub, uint32(lo))
+	sub = u32(sub, uint32(hi))
+	sub = u32(sub, 1)
+
+	cmap := []byte{0, 0, 0, 1, 0, 3, 0, 10}
+	cmap = u32(cmap, 12)
+	cmap = append(cmap, sub...)
+
+	font := u32(nil, 0x00010000)
+	font = append(font, 0, 1, 0, 0, 0, 0, 0, 0)
+	font = append(font, "cmap"...)
+	font = u32(font, 0)
+	font = u32(font, 28)
+	font = u32(font, uint32(len(cmap)))
+	return append(font, cmap...)
+}
+
+func TestTypeface_Runs(t *testing.T) {
+	registry := fonts.NewRegistry()
+	for family, cover := range map[string][2]rune{"Greek": {0x0370, 0x03FF}, "Hebrew": {0x0590, 0x05FF}} {
+		if err := registry.Add(family, fonts.Regular, coverageFont(cover[0], cover[1])); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	registry.SetFallbacks("Greek", "Hebrew")
+
+	ctx := newTestContext()
+	ctx.Fonts = registry
+	face := newTypeface(ctx, "Arial", fonts.Bold, 12)
+
+	type run struct{ family, text string }
+	var got []run
+	for _, r := range face.runs("Zoë αβ שלום ✓") {
+		family := ""
+		if r.font != nil {
+			family = r.font.Family
+		}
+		got = append(got, run{family, r.text})
+	}
+
+	want := []run{
+		{"", "Zoë "},
+		{"Greek", "αβ"},
+		{"", " "},
+		{"Hebrew", "שלום"},
+		// No font has the check mark, it stays with the core font
+		{"", " ✓"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("runs() = %q, want %q", got, want)
+	}
+}
+
+func TestTypeface_CoreEncoding(t *testing.T) {
+	ctx := newTestContext()
+	face := newTypeface(ctx, "Arial", fonts.Regular, 12)
+	face.set()
+	face.text(10, 20, "Zoë – 5€")
+
+	// Core fonts receive Windows-1252 bytes rather than UTF-8
+	if out := output(t, ctx); !strings.Contains(out, "(Zo\xeb \x96 5\x80) Tj") {
+		t.Errorf("text was not encoded as Windows-1252")
+	}
+}
@@ -0,0 +1,225 @@
+package render
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/fonts"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/generator/internal/acroform"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/internal/color"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// defaultFormFontSize is used for field values and labels without a style
+const defaultFormFontSize = 10.0
+
+// FormRenderer creates interactive AcroForm fields. Widgets are recorded in
+// the context's form and written into the PDF after rendering; only labels
+// are drawn on the page itself.
+type FormRenderer struct{}
+
+func (r *FormRenderer) Render(ctx *Context, element model.Element) error {
+	if ctx.Form == nil {
+		return fmt.Errorf("form fields are not supported by this render context")
+	}
+
+	var opts model.FormFieldOptions
+	if err := element.DecodeMetadata(&opts); err != nil {
+		return err
+	}
+
+	field := &acroform.Field{
+		Name:      opts.Name,
+		Value:     formValue(element.Content),
+		Options:   opts.Options,
+		ReadOnly:  opts.ReadOnly,
+		Required:  opts.Required,
+		Multiline: opts.Multiline,
+		MaxLength: opts.MaxLength,
+		FontSize:  defaultFormFontSize,
+	}
+	if field.Name == "" {
+		field.Name = element.ID
+	}
+	if err := applyFieldStyle(ctx, field, element.Style); err != nil {
+		return fmt.Errorf("form field %s: %w", field.Name, err)
+	}
+
+	bounds := element.Bounds
+	switch opts.Field {
+	case model.FormFieldText, "":
+		field.Type = acroform.FieldText
+		field.Widgets = []acroform.Widget{widget(ctx, bounds)}
+
+	case model.FormFieldSignature:
+		field.Type = acroform.FieldSignature
+		field.Widgets = []acroform.Widget{widget(ctx, bounds)}
+
+	case model.FormFieldDropdown:
+		if len(opts.Options) == 0 {
+			return fmt.Errorf("dropdown field %s requires options", field.Name)
+		}
+		field.Type = acroform.FieldDropdown
+		field.Widgets = []acroform.Widget{widget(ctx, bounds)}
+
+	case model.FormFieldCheckbox:
+		field.Type = acroform.FieldCheckbox
+		if isChecked(element.Content) {
+			field.Value = "Yes"
+		} else {
+			field.Value = ""
+		}
+		field.Widgets = []acroform.Widget{toggleWidget(ctx, element, bounds, opts.Label)}
+
+	case model.FormFieldRadio:
+		if len(opts.Options) == 0 {
+			return fmt.Errorf("radio field %s requires options", field.Name)
+		}
+		field.Type = acroform.FieldRadio
+
+		// Options are stacked vertically, one row each
+		row := bounds
+		row.Height = bounds.Height / float64(len(opts.Options))
+		for i, option := range opts.Options {
+			row.Y = bounds.Y + float64(i)*row.Height
+			w := toggleWidget(ctx, element, row, option)
+			w.State = option
+			field.Widgets = append(field.Widgets, w)
+		}
+
+	default:
+		return fmt.Errorf("unsupported form field type: %s", opts.Field)
+	}
+
+	return ctx.Form.Add(field)
+}
+
+// widget converts element bounds in document units to a widget rectangle in points
+func widget(ctx *Context, bounds model.Bounds) acroform.Widget {
+	pdf := ctx.PDF
+	k := pdf.GetConversionRatio()
+	_, pageHeight := pdf.GetPageSize()
+
+	return acroform.Widget{
+		Page: pdf.PageNo(),
+		Rect: acroform.Rect{
+			X1: bounds.X * k,
+			Y1: (pageHeight - bounds.Y - bounds.Height) * k,
+			X2: (bounds.X + bounds.Width) * k,
+			Y2: (pageHeight - bounds.Y) * k,
+		},
+	}
+}
+
+// toggleWidget places a square checkbox or radio button at the left of the
+// bounds and prints the label beside it
+func toggleWidget(ctx *Context, element model.Element, bounds model.Bounds, label string) acroform.Widget {
+	pdf := ctx.PDF
+	family, size := "Arial", defaultFormFontSize
+	if element.Style != nil {
+		if element.Style.FontFamily != "" {
+			family = element.Style.FontFamily
+		}
+		if element.Style.FontSize > 0 {
+			size = element.Style.FontSize
+		}
+	}
+
+	fontHeight := pdf.PointToUnitConvert(size)
+	side := math.Min(bounds.Height*0.8, fontHeight*1.2)
+	box := model.Bounds{
+		Position: model.Position{X: bounds.X, Y: bounds.Y + (bounds.Height-side)/2},
+		Size:     model.Size{Width: side, Height: side},
+	}
+
+	if label != "" {
+		face := newTypeface(ctx, family, fonts.Regular, size)
+		face.text(box.X+side+fontHeight/2, bounds.Y+(bounds.Height+fontHeight*0.7)/2, label)
+	}
+
+	return widget(ctx, box)
+}
+
+// applyFieldStyle maps the element style onto the field appearance. Fields
+// draw their own border and background, so drawBox is not used.
+func applyFieldStyle(ctx *Context, field *acroform.Field, style *model.Style) error {
+	if style == nil {
+		return nil
+	}
+	if style.FontSize > 0 {
+		field.FontSize = style.FontSize
+	}
+	if style.FontColor != "" {
+		c, err := fieldColor(style.FontColor)
+		if err != nil {
+			return fmt.Errorf("invalid font color: %w", err)
+		}
+		if c != nil {
+			field.TextColor = *c
+		}
+	}
+	if style.Background != "" {
+		c, err := fieldColor(style.Background)
+		if err != nil {
+			return fmt.Errorf("invalid background: %w", err)
+		}
+		field.Background = c
+	}
+	if style.Border != nil && style.Border.Style != model.BorderNone {
+		border := &acroform.Color{}
+		if style.Border.Color != "" {
+			c, err := fieldColor(style.Border.Color)
+			if err != nil {
+				return fmt.Errorf("invalid border: %w", err)
+			}
+			border = c
+		}
+		if border != nil {
+			field.Border = border
+			field.BorderWidth = style.Border.Width * ctx.PDF.GetConversionRatio()
+		}
+	}
+	return nil
+}
+
+// fieldColor parses a style color into an acroform color, which has no
+// alpha. Transparent colors return nil, leaving that part of the field
+// undrawn.
+func fieldColor(value string) (*acroform.Color, error) {
+	c, err := color.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	if c.Transparent() {
+		return nil, nil
+	}
+	return &acroform.Color{R: float64(c.R) / 255, G: float64(c.G) / 255, B: float64(c.B) / 255}, nil
+}
+
+// formValue converts bound content to a field value
+func formValue(content interface{}) string {
+	if b, ok := content.(bool); ok {
+		if b {
+			return "Yes"
+		}
+		return ""
+	}
+	value, _ := contentString(content)
+	return value
+}
+
+// isChecked interprets checkbox content such as true, "true", "yes" or "1"
+func isChecked(content interface{}) bool {
+	switch v := content.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		switch v {
+		case "true", "True", "TRUE", "yes", "Yes", "YES", "on", "1", "x", "X":
+			return true
+		}
+	}
+	return false
+}
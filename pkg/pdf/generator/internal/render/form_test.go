@@ -0,0 +1,49 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/generator/internal/acroform"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+func TestApplyFieldStyle(t *testing.T) {
+	tests := []struct {
+		name           string
+		style          *model.Style
+		wantBackground *acroform.Color
+		wantBorder     *acroform.Color
+		wantText       acroform.Color
+	}{
+		{
+			name:           "solid colors",
+			style:          &model.Style{Background: "#ff0000", FontColor: "blue", Border: &model.Border{Width: 1, Color: "#000000"}},
+			wantBackground: &acroform.Color{R: 1},
+			wantBorder:     &acroform.Color{},
+			wantText:       acroform.Color{B: 1},
+		},
+		{
+			name:  "transparent colors are left out",
+			style: &model.Style{Background: "transparent", FontColor: "transparent", Border: &model.Border{Width: 1, Color: "rgba(0, 0, 0, 0)"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := &acroform.Field{}
+			if err := applyFieldStyle(newTestContext(), field, tt.style); err != nil {
+				t.Fatalf("applyFieldStyle() error = %v", err)
+			}
+			if !reflect.DeepEqual(field.Background, tt.wantBackground) {
+				t.Errorf("background = %+v, want %+v", field.Background, tt.wantBackground)
+			}
+			if !reflect.DeepEqual(field.Border, tt.wantBorder) {
+				t.Errorf("border = %+v, want %+v", field.Border, tt.wantBorder)
+			}
+			if field.TextColor != tt.wantText {
+				t.Errorf("text color = %+v, want %+v", field.TextColor, tt.wantText)
+			}
+		})
+	}
+}
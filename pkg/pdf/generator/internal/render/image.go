@@ -0,0 +1,244 @@
+package render
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/assets"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/image/webp"
+)
+
+// imageDpi is the resolution images have at their natural size, that of
+// CSS pixels
+const imageDpi = 96
+
+// ImageRenderer handles rendering of image elements. Content is the image
+// source: a data URI, or a name opened with the context's asset resolver.
+// The metadata sets how the image fits its bounds, its rotation and opacity.
+type ImageRenderer struct{}
+
+func (r *ImageRenderer) Render(ctx *Context, element model.Element) error {
+	src, ok := element.Content.(string)
+	if !ok {
+		return fmt.Errorf("invalid content type for image element")
+	}
+	var opts model.ImageOptions
+	if err := element.DecodeMetadata(&opts); err != nil {
+		return err
+	}
+
+	name, info, err := registerImage(ctx, src)
+	if err != nil {
+		return fmt.Errorf("failed to load image of element %s: %w", element.ID, err)
+	}
+
+	natural := model.Size{Width: info.Width(), Height: info.Height()}
+	return placeImage(ctx, element, opts, natural, func(placed model.Bounds, opacity float64) error {
+		pdf := ctx.PDF
+		if opacity < 1 {
+			pdf.SetAlpha(opacity, "Normal")
+		}
+		pdf.ImageOptions(name, placed.X, placed.Y, placed.Width, placed.Height, false, gofpdf.ImageOptions{}, 0, "")
+		if opacity < 1 {
+			pdf.SetAlpha(1, "Normal")
+		}
+		return nil
+	})
+}
+
+// imagePlacement is where an image element draws its content
+type imagePlacement struct {
+	// placed is where the content goes, before rotation
+	placed model.Bounds
+	// pivot is the center of the box the content turns about
+	pivot   model.Position
+	opacity float64
+	// crop clips the content to the box
+	crop bool
+}
+
+// planImage works out where content of the given natural size goes in the
+// box by the image options. Image and SVG elements take the same options.
+func planImage(opts model.ImageOptions, bounds model.Bounds, natural model.Size) (imagePlacement, error) {
+	plan := imagePlacement{
+		pivot:   model.Position{X: bounds.X + bounds.Width/2, Y: bounds.Y + bounds.Height/2},
+		opacity: 1,
+		// Cropping turns with the image, as a CSS transform would
+		crop: opts.Fit == model.ImageFitCover || opts.Fit == model.ImageFitNone,
+	}
+	if opts.Opacity != nil {
+		plan.opacity = *opts.Opacity
+		if plan.opacity < 0 || plan.opacity > 1 {
+			return plan, fmt.Errorf("opacity must be between 0 and 1, got %v", plan.opacity)
+		}
+	}
+	placed, err := fitImage(opts, bounds, natural)
+	if err != nil {
+		return plan, err
+	}
+	plan.placed = placed
+	return plan, nil
+}
+
+// placeImage draws the element's box and calls draw with where content of
+// the given natural size goes by the image options, and with its opacity.
+// draw runs inside the rotation and, for fits that crop, the clip of the
+// box.
+func placeImage(ctx *Context, element model.Element, opts model.ImageOptions, natural model.Size, draw func(placed model.Bounds, opacity float64) error) error {
+	bounds, err := drawBox(ctx, element)
+	if err != nil {
+		return err
+	}
+	plan, err := planImage(opts, bounds, natural)
+	if err != nil {
+		return fmt.Errorf("invalid %s options of element %s: %w", element.Type, element.ID, err)
+	}
+
+	pdf := ctx.PDF
+	if opts.Rotation != 0 {
+		pdf.TransformBegin()
+		// gofpdf turns counter-clockwise
+		pdf.TransformRotate(-opts.Rotation, plan.pivot.X, plan.pivot.Y)
+		defer pdf.TransformEnd()
+	}
+	if plan.crop {
+		pdf.ClipRect(bounds.X, bounds.Y, bounds.Width, bounds.Height, false)
+		defer pdf.ClipEnd()
+	}
+	return draw(plan.placed, plan.opacity)
+}
+
+// fitImage returns where an image of the given natural size is drawn in the
+// bounds. Images that do not fill the bounds are placed by the alignments.
+func fitImage(opts model.ImageOptions, bounds model.Bounds, natural model.Size) (model.Bounds, error) {
+	if natural.Width <= 0 || natural.Height <= 0 {
+		return bounds, fmt.Errorf("image has no size")
+	}
+
+	scale := 1.0
+	switch opts.Fit {
+	case "", model.ImageFitFill:
+		return bounds, nil
+	case model.ImageFitContain:
+		scale = min(bounds.Width/natural.Width, bounds.Height/natural.Height)
+	case model.ImageFitCover:
+		scale = max(bounds.Width/natural.Width, bounds.Height/natural.Height)
+	case model.ImageFitNone:
+	default:
+		return bounds, fmt.Errorf("unknown fit %q", opts.Fit)
+	}
+
+	width, height := natural.Width*scale, natural.Height*scale
+	placed := model.Bounds{Position: bounds.Position, Size: model.Size{Width: width, Height: height}}
+	switch opts.Alignment {
+	case model.AlignLeft:
+	case model.AlignRight:
+		placed.X += bounds.Width - width
+	default:
+		placed.X += (bounds.Width - width) / 2
+	}
+	switch opts.VerticalAlignment {
+	case model.AlignTop:
+	case model.AlignBottom:
+		placed.Y += bounds.Height - height
+	default:
+		placed.Y += (bounds.Height - height) / 2
+	}
+	return placed, nil
+}
+
+// registerImage reads an image source into the document once and returns
+// the name it is registered under. Data URIs are registered under a hash of
+// their data so that long payloads are not kept as names.
+func registerImage(ctx *Context, src string) (string, *gofpdf.ImageInfoType, error) {
+	pdf := ctx.PDF
+	name := src
+	if assets.IsDataURI(src) {
+		sum := sha256.Sum256([]byte(src))
+		name = "data:" + hex.EncodeToString(sum[:])
+	}
+	if info := pdf.GetImageInfo(name); info != nil {
+		return name, info, nil
+	}
+
+	data, err := assets.ReadAll(ctx.Assets, src)
+	if err != nil {
+		return "", nil, err
+	}
+	data, imageType, err := prepareImage(data)
+	if err != nil {
+		return "", nil, err
+	}
+	info := pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(data))
+	if err := pdf.Error(); err != nil {
+		return "", nil, err
+	}
+	info.SetDpi(imageDpi)
+	return name, info, nil
+}
+
+// prepareImage converts encoded image data to a form gofpdf embeds and
+// returns its gofpdf image type. JPEG photos are turned upright by their
+// EXIF orientation, WebP images become PNG, and PNG images gofpdf cannot
+// read, with 16-bit samples or interlacing, are re-encoded.
+func prepareImage(data []byte) ([]byte, string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		// The IHDR chunk holds the bit depth and interlace method
+		if len(data) > 28 && (data[24] == 16 || data[28] != 0) {
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				return nil, "", err
+			}
+			data, err = encodePNG(img)
+			return data, "PNG", err
+		}
+		return data, "PNG", nil
+
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		orientation := exifOrientation(data)
+		if orientation <= 1 {
+			return data, "JPG", nil
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", err
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, orient(img, orientation), &jpeg.Options{Quality: 95}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "JPG", nil
+
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return data, "GIF", nil
+
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		img, err := webp.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", err
+		}
+		data, err = encodePNG(img)
+		return data, "PNG", err
+	}
+	return nil, "", fmt.Errorf("unsupported image format")
+}
+
+// encodePNG writes an image as an 8-bit PNG, keeping its alpha channel
+func encodePNG(img image.Image) ([]byte, error) {
+	rgba := image.NewNRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, rgba); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
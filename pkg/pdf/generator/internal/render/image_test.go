@@ -0,0 +1,319 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/assets"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// testPNG returns an encoded gray image of the given size
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func imageElement(src string) model.Element {
+	return model.Element{
+		ID:      "logo",
+		Type:    model.ElementTypeImage,
+		Bounds:  model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 40, Height: 20}},
+		Content: src,
+	}
+}
+
+func TestImageRenderer_Sources(t *testing.T) {
+	data := testPNG(t, 4, 2)
+	dataURI := "data:image/png;base64," + base64.StdEncoding.EncodeToString(data)
+
+	tests := []struct {
+		name    string
+		src     string
+		assets  assets.Resolver
+		wantErr string
+	}{
+		{name: "data URI", src: dataURI},
+		{name: "resolver", src: "logo:42", assets: assets.Map{"logo:42": data}},
+		{name: "missing", src: "logo:7", assets: assets.Map{}, wantErr: "does not exist"},
+		{name: "unsupported", src: "notes.txt", assets: assets.Map{"notes.txt": []byte("hello")}, wantErr: "unsupported image format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext()
+			ctx.Assets = tt.assets
+			err := (&ImageRenderer{}).Render(ctx, imageElement(tt.src))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Render() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if out := output(t, ctx); !strings.Contains(out, "/Subtype /Image") {
+				t.Errorf("document does not contain the image")
+			}
+		})
+	}
+}
+
+func TestImageRenderer_RegistersOnce(t *testing.T) {
+	ctx := newTestContext()
+	reads := 0
+	ctx.Assets = assets.ResolverFunc(func(name string) (io.ReadCloser, error) {
+		reads++
+		return io.NopCloser(bytes.NewReader(testPNG(t, 4, 2))), nil
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := (&ImageRenderer{}).Render(ctx, imageElement("logo.png")); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("image read %d times, want once", reads)
+	}
+	if got := strings.Count(output(t, ctx), "/Subtype /Image"); got != 1 {
+		t.Errorf("document embeds %d images, want 1", got)
+	}
+}
+
+func TestFitImage(t *testing.T) {
+	bounds := model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 40, Height: 20}}
+	// A square image in a wide box
+	natural := model.Size{Width: 10, Height: 10}
+
+	tests := []struct {
+		name    string
+		opts    model.ImageOptions
+		want    model.Bounds
+		wantErr bool
+	}{
+		{name: "fill", want: bounds},
+		{name: "contain", opts: model.ImageOptions{Fit: model.ImageFitContain}, want: model.Bounds{Position: model.Position{X: 20, Y: 10}, Size: model.Size{Width: 20, Height: 20}}},
+		{name: "contain left", opts: model.ImageOptions{Fit: model.ImageFitContain, Alignment: model.AlignLeft}, want: model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 20, Height: 20}}},
+		{name: "cover", opts: model.ImageOptions{Fit: model.ImageFitCover}, want: model.Bounds{Position: model.Position{X: 10, Y: 0}, Size: model.Size{Width: 40, Height: 40}}},
+		{name: "cover top", opts: model.ImageOptions{Fit: model.ImageFitCover, VerticalAlignment: model.AlignTop}, want: model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 40, Height: 40}}},
+		{name: "none bottom right", opts: model.ImageOptions{Fit: model.ImageFitNone, Alignment: model.AlignRight, VerticalAlignment: model.AlignBottom}, want: model.Bounds{Position: model.Position{X: 40, Y: 20}, Size: model.Size{Width: 10, Height: 10}}},
+		{name: "unknown", opts: model.ImageOptions{Fit: "stretch"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fitImage(tt.opts, bounds, natural)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("fitImage() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fitImage() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("fitImage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// withOrientation inserts an EXIF segment with the given orientation after
+// the start of a JPEG image
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry, exifOrientationTag)
+	binary.BigEndian.PutUint16(entry[2:], 3)
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+	tiff = append(append(tiff, entry...), 0, 0, 0, 0)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	header := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(header[2:], uint16(len(segment)+2))
+	out := append([]byte{}, data[:2]...)
+	out = append(append(out, header...), segment...)
+	return append(out, data[2:]...)
+}
+
+func TestPrepareImage(t *testing.T) {
+	var jpg bytes.Buffer
+	if err := jpeg.Encode(&jpg, image.NewGray(image.Rect(0, 0, 4, 2)), nil); err != nil {
+		t.Fatal(err)
+	}
+	var deep bytes.Buffer
+	if err := png.Encode(&deep, image.NewNRGBA64(image.Rect(0, 0, 4, 2))); err != nil {
+		t.Fatal(err)
+	}
+	// A lossless 1x1 WebP image
+	webp, err := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		wantType string
+		width    int
+		height   int
+	}{
+		{name: "png", data: testPNG(t, 4, 2), wantType: "PNG", width: 4, height: 2},
+		{name: "16-bit png", data: deep.Bytes(), wantType: "PNG", width: 4, height: 2},
+		{name: "jpeg", data: jpg.Bytes(), wantType: "JPG", width: 4, height: 2},
+		{name: "jpeg upside down", data: withOrientation(jpg.Bytes(), 3), wantType: "JPG", width: 4, height: 2},
+		{name: "jpeg turned", data: withOrientation(jpg.Bytes(), 6), wantType: "JPG", width: 2, height: 4},
+		{name: "webp", data: webp, wantType: "PNG", width: 1, height: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, imageType, err := prepareImage(tt.data)
+			if err != nil {
+				t.Fatalf("prepareImage() error = %v", err)
+			}
+			if imageType != tt.wantType {
+				t.Errorf("prepareImage() type = %s, want %s", imageType, tt.wantType)
+			}
+			config, _, err := image.DecodeConfig(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("DecodeConfig() error = %v", err)
+			}
+			if config.Width != tt.width || config.Height != tt.height {
+				t.Errorf("prepared image is %dx%d, want %dx%d", config.Width, config.Height, tt.width, tt.height)
+			}
+			if imageType == "PNG" && data[24] != 8 {
+				t.Errorf("prepared PNG has %d-bit samples, want 8", data[24])
+			}
+
+			// gofpdf reads the result
+			ctx := newTestContext()
+			ctx.Assets = assets.Map{"photo": tt.data}
+			if err := (&ImageRenderer{}).Render(ctx, imageElement("photo")); err != nil {
+				t.Errorf("Render() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 2x1 image with a white left pixel
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.Pix[0] = 0xff
+
+	tests := []struct {
+		orientation int
+		x, y        int
+	}{
+		{orientation: 2, x: 1, y: 0},
+		{orientation: 6, x: 0, y: 0},
+		{orientation: 8, x: 0, y: 1},
+	}
+	for _, tt := range tests {
+		out := orient(img, tt.orientation)
+		if r, _, _, _ := out.At(tt.x, tt.y).RGBA(); r != 0xffff {
+			t.Errorf("orientation %d: white pixel not at %d,%d", tt.orientation, tt.x, tt.y)
+		}
+	}
+}
+
+func TestPlanImage(t *testing.T) {
+	bounds := model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 40, Height: 20}}
+	natural := model.Size{Width: 10, Height: 10}
+	half, outside := 0.5, 2.0
+
+	tests := []struct {
+		name    string
+		opts    model.ImageOptions
+		want    imagePlacement
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			want: imagePlacement{placed: bounds, pivot: model.Position{X: 30, Y: 20}, opacity: 1},
+		},
+		{
+			name: "opacity",
+			opts: model.ImageOptions{Opacity: &half},
+			want: imagePlacement{placed: bounds, pivot: model.Position{X: 30, Y: 20}, opacity: 0.5},
+		},
+		{
+			// Rotation turns about the center of the box, not of the image
+			name: "rotated contain",
+			opts: model.ImageOptions{Fit: model.ImageFitContain, Alignment: model.AlignLeft, Rotation: 90},
+			want: imagePlacement{
+				placed:  model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 20, Height: 20}},
+				pivot:   model.Position{X: 30, Y: 20},
+				opacity: 1,
+			},
+		},
+		{
+			name: "cover crops",
+			opts: model.ImageOptions{Fit: model.ImageFitCover},
+			want: imagePlacement{
+				placed:  model.Bounds{Position: model.Position{X: 10, Y: 0}, Size: model.Size{Width: 40, Height: 40}},
+				pivot:   model.Position{X: 30, Y: 20},
+				opacity: 1,
+				crop:    true,
+			},
+		},
+		{
+			name: "none crops",
+			opts: model.ImageOptions{Fit: model.ImageFitNone},
+			want: imagePlacement{
+				placed:  model.Bounds{Position: model.Position{X: 25, Y: 15}, Size: natural},
+				pivot:   model.Position{X: 30, Y: 20},
+				opacity: 1,
+				crop:    true,
+			},
+		},
+		{name: "invalid opacity", opts: model.ImageOptions{Opacity: &outside}, wantErr: true},
+		{name: "invalid fit", opts: model.ImageOptions{Fit: "stretch"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := planImage(tt.opts, bounds, natural)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("planImage() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("planImage() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("planImage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterImage_NaturalSize(t *testing.T) {
+	// Images keep their natural size of 96 dpi
+	ctx := newTestContext()
+	ctx.Assets = assets.Map{"logo.png": testPNG(t, 96, 48)}
+	_, info, err := registerImage(ctx, "logo.png")
+	if err != nil {
+		t.Fatalf("registerImage() error = %v", err)
+	}
+	if math.Abs(info.Width()-25.4) > 1e-9 || math.Abs(info.Height()-12.7) > 1e-9 {
+		t.Errorf("natural size = %v x %v, want 25.4 x 12.7", info.Width(), info.Height())
+	}
+}
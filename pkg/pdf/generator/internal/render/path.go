@@ -0,0 +1,476 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// kappa places the control points of a cubic Bézier quarter circle
+const kappa = 0.5522847498
+
+// pathOp is the drawing operation of a path segment
+type pathOp int
+
+const (
+	pathMove pathOp = iota
+	pathLine
+	// pathCurve is a cubic Bézier curve through the control points c1 and c2
+	pathCurve
+	pathClose
+)
+
+// pathSegment is one operation of an outline in absolute coordinates
+type pathSegment struct {
+	op     pathOp
+	c1, c2 model.Position
+	to     model.Position
+}
+
+// parsePath reads an outline in SVG path syntax. It supports the M, L, H,
+// V, C, S, Q, T, A and Z commands in absolute and relative form; quadratic
+// curves and elliptical arcs are converted to cubic curves.
+func parsePath(d string) ([]pathSegment, error) {
+	p := &pathParser{src: d}
+	var segments []pathSegment
+	var current, start, lastControl model.Position
+	var command, previous byte
+
+	for {
+		p.skipSeparators()
+		if p.done() {
+			break
+		}
+		if c := p.src[p.pos]; isPathCommand(c) {
+			command = c
+			p.pos++
+		} else if command == 0 {
+			return nil, fmt.Errorf("path must start with a command, got %q", c)
+		}
+
+		relative := command >= 'a'
+		offset := func(pt model.Position) model.Position {
+			if relative {
+				return model.Position{X: current.X + pt.X, Y: current.Y + pt.Y}
+			}
+			return pt
+		}
+		// reflect mirrors the last control point of a matching curve for S and T
+		reflect := func(kinds string) model.Position {
+			if strings.IndexByte(kinds, previous|0x20) < 0 {
+				return current
+			}
+			return model.Position{X: 2*current.X - lastControl.X, Y: 2*current.Y - lastControl.Y}
+		}
+
+		switch command | 0x20 {
+		case 'z':
+			segments = append(segments, pathSegment{op: pathClose, to: start})
+			current = start
+			previous = command
+			continue
+		case 'm':
+			pt, err := p.point()
+			if err != nil {
+				return nil, err
+			}
+			current = offset(pt)
+			start = current
+			segments = append(segments, pathSegment{op: pathMove, to: current})
+			// Further coordinate pairs are lines
+			command = 'L' | command&0x20
+		case 'l':
+			pt, err := p.point()
+			if err != nil {
+				return nil, err
+			}
+			current = offset(pt)
+			segments = append(segments, pathSegment{op: pathLine, to: current})
+		case 'h', 'v':
+			n, err := p.number()
+			if err != nil {
+				return nil, err
+			}
+			to := current
+			switch {
+			case command == 'H':
+				to.X = n
+			case command == 'V':
+				to.Y = n
+			case command == 'h':
+				to.X += n
+			default:
+				to.Y += n
+			}
+			current = to
+			segments = append(segments, pathSegment{op: pathLine, to: current})
+		case 'c', 's':
+			var pts []model.Position
+			if command|0x20 == 'c' {
+				c1, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				pts = append(pts, offset(c1))
+			} else {
+				pts = append(pts, reflect("cs"))
+			}
+			for i := 0; i < 2; i++ {
+				pt, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				pts = append(pts, offset(pt))
+			}
+			segments = append(segments, pathSegment{op: pathCurve, c1: pts[0], c2: pts[1], to: pts[2]})
+			current, lastControl = pts[2], pts[1]
+		case 'q', 't':
+			control := reflect("qt")
+			if command|0x20 == 'q' {
+				pt, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				control = offset(pt)
+			}
+			pt, err := p.point()
+			if err != nil {
+				return nil, err
+			}
+			to := offset(pt)
+			segments = append(segments, quadratic(current, control, to))
+			current, lastControl = to, control
+		case 'a':
+			var radii [3]float64
+			for i := range radii {
+				n, err := p.number()
+				if err != nil {
+					return nil, err
+				}
+				radii[i] = n
+			}
+			large, err := p.flag()
+			if err != nil {
+				return nil, err
+			}
+			sweep, err := p.flag()
+			if err != nil {
+				return nil, err
+			}
+			pt, err := p.point()
+			if err != nil {
+				return nil, err
+			}
+			to := offset(pt)
+			segments = append(segments, endpointArc(current, to, radii[0], radii[1], radii[2], large, sweep)...)
+			current = to
+		default:
+			return nil, fmt.Errorf("unsupported path command %q", command)
+		}
+		if len(segments) == 0 || segments[0].op != pathMove {
+			return nil, fmt.Errorf("path must start with a move")
+		}
+		previous = command
+	}
+	return segments, nil
+}
+
+// quadratic converts a quadratic Bézier curve to a cubic segment
+func quadratic(from, control, to model.Position) pathSegment {
+	return pathSegment{
+		op: pathCurve,
+		c1: model.Position{X: from.X + 2.0/3*(control.X-from.X), Y: from.Y + 2.0/3*(control.Y-from.Y)},
+		c2: model.Position{X: to.X + 2.0/3*(control.X-to.X), Y: to.Y + 2.0/3*(control.Y-to.Y)},
+		to: to,
+	}
+}
+
+func isPathCommand(c byte) bool {
+	return strings.IndexByte("MmLlHhVvCcSsQqTtZzAa", c) >= 0
+}
+
+// pathParser scans the numbers of a path
+type pathParser struct {
+	src string
+	pos int
+}
+
+func (p *pathParser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *pathParser) skipSeparators() {
+	for !p.done() && strings.IndexByte(" \t\r\n,", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// number reads the next number, which may follow the previous one without
+// a separator as in "1.5.5" or "2-3"
+func (p *pathParser) number() (float64, error) {
+	p.skipSeparators()
+	start := p.pos
+	if !p.done() && (p.src[p.pos] == '-' || p.src[p.pos] == '+') {
+		p.pos++
+	}
+	digits, dot := false, false
+	for !p.done() {
+		c := p.src[p.pos]
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+		case c == '.' && !dot:
+			dot = true
+		case (c == 'e' || c == 'E') && digits:
+			// An exponent may carry its own sign
+			if p.pos+1 < len(p.src) && (p.src[p.pos+1] == '-' || p.src[p.pos+1] == '+') {
+				p.pos++
+			}
+			dot = true
+		default:
+			goto end
+		}
+		p.pos++
+	}
+end:
+	if !digits {
+		if p.done() {
+			return 0, fmt.Errorf("path ends before a number")
+		}
+		return 0, fmt.Errorf("expected a number at %q", p.src[start:])
+	}
+	return strconv.ParseFloat(p.src[start:p.pos], 64)
+}
+
+// flag reads an arc flag, a single 0 or 1 that needs no separator
+func (p *pathParser) flag() (bool, error) {
+	p.skipSeparators()
+	if p.done() {
+		return false, fmt.Errorf("path ends before an arc flag")
+	}
+	c := p.src[p.pos]
+	if c != '0' && c != '1' {
+		return false, fmt.Errorf("expected an arc flag at %q", p.src[p.pos:])
+	}
+	p.pos++
+	return c == '1', nil
+}
+
+func (p *pathParser) point() (model.Position, error) {
+	x, err := p.number()
+	if err != nil {
+		return model.Position{}, err
+	}
+	y, err := p.number()
+	if err != nil {
+		return model.Position{}, err
+	}
+	return model.Position{X: x, Y: y}, nil
+}
+
+// tracePath adds the segments to the current PDF path, offset by origin.
+// The caller paints the path with DrawPath.
+func tracePath(pdf *gofpdf.Fpdf, segments []pathSegment, origin model.Position) {
+	at := func(pt model.Position) (float64, float64) {
+		return origin.X + pt.X, origin.Y + pt.Y
+	}
+	for _, s := range segments {
+		switch s.op {
+		case pathMove:
+			pdf.MoveTo(at(s.to))
+		case pathLine:
+			pdf.LineTo(at(s.to))
+		case pathCurve:
+			x1, y1 := at(s.c1)
+			x2, y2 := at(s.c2)
+			x, y := at(s.to)
+			pdf.CurveBezierCubicTo(x1, y1, x2, y2, x, y)
+		case pathClose:
+			pdf.ClosePath()
+		}
+	}
+}
+
+// polylinePath joins points with straight lines, closing the outline when asked
+func polylinePath(points []model.Position, closed bool) []pathSegment {
+	segments := make([]pathSegment, 0, len(points)+1)
+	for i, pt := range points {
+		op := pathLine
+		if i == 0 {
+			op = pathMove
+		}
+		segments = append(segments, pathSegment{op: op, to: pt})
+	}
+	if closed && len(points) > 0 {
+		segments = append(segments, pathSegment{op: pathClose, to: points[0]})
+	}
+	return segments
+}
+
+// rectPath outlines a rectangle whose corners are rounded by the radii
+func rectPath(size model.Size, rx, ry float64) []pathSegment {
+	w, h := size.Width, size.Height
+	rx, ry = max(0, min(rx, w/2)), max(0, min(ry, h/2))
+	if rx == 0 || ry == 0 {
+		return polylinePath([]model.Position{{X: 0, Y: 0}, {X: w, Y: 0}, {X: w, Y: h}, {X: 0, Y: h}}, true)
+	}
+
+	kx, ky := rx*kappa, ry*kappa
+	corner := func(c1x, c1y, c2x, c2y, x, y float64) pathSegment {
+		return pathSegment{op: pathCurve, c1: model.Position{X: c1x, Y: c1y}, c2: model.Position{X: c2x, Y: c2y}, to: model.Position{X: x, Y: y}}
+	}
+	return []pathSegment{
+		{op: pathMove, to: model.Position{X: rx, Y: 0}},
+		{op: pathLine, to: model.Position{X: w - rx, Y: 0}},
+		corner(w-rx+kx, 0, w, ry-ky, w, ry),
+		{op: pathLine, to: model.Position{X: w, Y: h - ry}},
+		corner(w, h-ry+ky, w-rx+kx, h, w-rx, h),
+		{op: pathLine, to: model.Position{X: rx, Y: h}},
+		corner(rx-kx, h, 0, h-ry+ky, 0, h-ry),
+		{op: pathLine, to: model.Position{X: 0, Y: ry}},
+		corner(0, ry-ky, rx-kx, 0, rx, 0),
+		{op: pathClose, to: model.Position{X: rx, Y: 0}},
+	}
+}
+
+// ellipsePath outlines the ellipse inscribed in a box with four quarter curves
+func ellipsePath(size model.Size) []pathSegment {
+	rx, ry := size.Width/2, size.Height/2
+	kx, ky := rx*kappa, ry*kappa
+	curve := func(c1x, c1y, c2x, c2y, x, y float64) pathSegment {
+		return pathSegment{op: pathCurve, c1: model.Position{X: c1x, Y: c1y}, c2: model.Position{X: c2x, Y: c2y}, to: model.Position{X: x, Y: y}}
+	}
+	return []pathSegment{
+		{op: pathMove, to: model.Position{X: 2 * rx, Y: ry}},
+		curve(2*rx, ry+ky, rx+kx, 2*ry, rx, 2*ry),
+		curve(rx-kx, 2*ry, 0, ry+ky, 0, ry),
+		curve(0, ry-ky, rx-kx, 0, rx, 0),
+		curve(rx+kx, 0, 2*rx, ry-ky, 2*rx, ry),
+		{op: pathClose, to: model.Position{X: 2 * rx, Y: ry}},
+	}
+}
+
+// pathEnd returns an end point of an open outline and the direction the
+// outline arrives at it from, as a unit vector. It reports false for
+// outlines without a usable end.
+func pathEnd(segments []pathSegment, atStart bool) (model.Position, model.Position, bool) {
+	if len(segments) < 2 {
+		return model.Position{}, model.Position{}, false
+	}
+
+	var tip model.Position
+	var towards []model.Position
+	if atStart {
+		// The direction runs back from the first drawn segment to the start
+		tip = segments[0].to
+		next := segments[1]
+		towards = []model.Position{next.c1, next.c2, next.to}
+		if next.op != pathCurve {
+			towards = []model.Position{next.to}
+		}
+	} else {
+		last := segments[len(segments)-1]
+		if last.op == pathClose || last.op == pathMove {
+			return model.Position{}, model.Position{}, false
+		}
+		tip = last.to
+		prev := segments[len(segments)-2].to
+		towards = []model.Position{prev}
+		if last.op == pathCurve {
+			towards = []model.Position{last.c2, last.c1, prev}
+		}
+	}
+
+	for _, pt := range towards {
+		dx, dy := tip.X-pt.X, tip.Y-pt.Y
+		if length := math.Hypot(dx, dy); length > 1e-9 {
+			return tip, model.Position{X: dx / length, Y: dy / length}, true
+		}
+	}
+	return model.Position{}, model.Position{}, false
+}
+
+// arcPath returns cubic curves along an elliptical arc about center, from
+// the start angle through sweep, in radians. Angles run from the x axis
+// toward the y axis, which is clockwise on the page. The curves begin at the
+// start of the arc, which the caller moves or draws a line to first.
+func arcPath(center model.Position, rx, ry, start, sweep float64) []pathSegment {
+	n := max(1, int(math.Ceil(math.Abs(sweep)/(math.Pi/2)-1e-9)))
+	step := sweep / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4)
+
+	segments := make([]pathSegment, 0, n)
+	for i := 0; i < n; i++ {
+		a0, a1 := start+float64(i)*step, start+float64(i+1)*step
+		p0, p1 := arcPoint(center, rx, ry, a0), arcPoint(center, rx, ry, a1)
+		segments = append(segments, pathSegment{
+			op: pathCurve,
+			c1: model.Position{X: p0.X - k*rx*math.Sin(a0), Y: p0.Y + k*ry*math.Cos(a0)},
+			c2: model.Position{X: p1.X + k*rx*math.Sin(a1), Y: p1.Y - k*ry*math.Cos(a1)},
+			to: p1,
+		})
+	}
+	return segments
+}
+
+// arcPoint returns the point of an ellipse about center at an angle
+func arcPoint(center model.Position, rx, ry, angle float64) model.Position {
+	return model.Position{X: center.X + rx*math.Cos(angle), Y: center.Y + ry*math.Sin(angle)}
+}
+
+// endpointArc converts an arc given by its end points, as in SVG paths, to
+// cubic curves. The radii grow when they are too small to reach the end
+// point, and an arc without a radius is a straight line.
+func endpointArc(from, to model.Position, rx, ry, rotation float64, large, sweep bool) []pathSegment {
+	if from == to {
+		return nil
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		return []pathSegment{{op: pathLine, to: to}}
+	}
+
+	// Work in the frame of the ellipse axes, centered between the ends
+	phi := rotation * math.Pi / 180
+	cos, sin := math.Cos(phi), math.Sin(phi)
+	dx, dy := (from.X-to.X)/2, (from.Y-to.Y)/2
+	x1, y1 := cos*dx+sin*dy, -sin*dx+cos*dy
+	if scale := x1*x1/(rx*rx) + y1*y1/(ry*ry); scale > 1 {
+		rx, ry = rx*math.Sqrt(scale), ry*math.Sqrt(scale)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(max(0, num/den))
+	if large == sweep {
+		coef = -coef
+	}
+	cx1, cy1 := coef*rx*y1/ry, -coef*ry*x1/rx
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	start := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	// Turn the arc of the axis-aligned ellipse into place
+	center := model.Position{X: cos*cx1 - sin*cy1 + (from.X+to.X)/2, Y: sin*cx1 + cos*cy1 + (from.Y+to.Y)/2}
+	place := func(p model.Position) model.Position {
+		return model.Position{X: center.X + cos*p.X - sin*p.Y, Y: center.Y + sin*p.X + cos*p.Y}
+	}
+	segments := arcPath(model.Position{}, rx, ry, start, delta)
+	for i := range segments {
+		segments[i].c1, segments[i].c2, segments[i].to = place(segments[i].c1), place(segments[i].c2), place(segments[i].to)
+	}
+	segments[len(segments)-1].to = to
+	return segments
+}
@@ -0,0 +1,416 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/generator/internal/markdown"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/internal/color"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// codeFontFamily is the monospaced font of code runs
+const codeFontFamily = "Courier"
+
+var (
+	// codeBackground shades code runs
+	codeBackground = color.Color{R: 240, G: 240, B: 240, A: 1}
+	// linkColor is the color of links without a color of their own
+	linkColor = color.Color{R: 6, G: 69, B: 173, A: 1}
+)
+
+// textContent returns the plain string content of a text element, or its
+// runs when the content is a run list or Markdown
+func textContent(element model.Element) (string, []model.TextRun, error) {
+	switch content := element.Content.(type) {
+	case string:
+		var opts model.TextOptions
+		if err := element.DecodeMetadata(&opts); err != nil {
+			return "", nil, err
+		}
+		switch opts.Format {
+		case "", model.TextFormatPlain:
+			return content, nil, nil
+		case model.TextFormatMarkdown:
+			return "", markdown.Parse(content), nil
+		}
+		return "", nil, fmt.Errorf("unknown text format %q for element %s", opts.Format, element.ID)
+
+	case []interface{}:
+		runs, err := parseRuns(content)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid rich text in element %s: %w", element.ID, err)
+		}
+		return "", runs, nil
+	}
+	return "", nil, fmt.Errorf("invalid content type for text element")
+}
+
+// parseRuns reads a run list such as
+// ["Dear ", {"text": "{{ customer.name }}", "bold": true}, ","]
+// where plain values are unformatted runs
+func parseRuns(items []interface{}) ([]model.TextRun, error) {
+	runs := make([]model.TextRun, 0, len(items))
+	for i, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			runs = append(runs, model.TextRun{Text: cellText(item)})
+			continue
+		}
+
+		var run model.TextRun
+		if text, ok := fields["text"]; ok && text != nil {
+			run.Text = cellText(text)
+		}
+		flags := []struct {
+			key   string
+			value *bool
+		}{
+			{"bold", &run.Bold}, {"italic", &run.Italic}, {"underline", &run.Underline},
+			{"strike", &run.Strike}, {"code", &run.Code},
+		}
+		for _, flag := range flags {
+			if value, ok := fields[flag.key]; ok {
+				if *flag.value, ok = value.(bool); !ok {
+					return nil, fmt.Errorf("run %d: %s must be true or false", i, flag.key)
+				}
+			}
+		}
+		if run.Color, ok = optionalString(fields["color"]); !ok {
+			return nil, fmt.Errorf("run %d: color must be a string", i)
+		}
+		if run.Link, ok = optionalString(fields["link"]); !ok {
+			return nil, fmt.Errorf("run %d: link must be a string", i)
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// optionalString returns a string field that may be missing
+func optionalString(value interface{}) (string, bool) {
+	if value == nil {
+		return "", true
+	}
+	s, ok := value.(string)
+	return s, ok
+}
+
+// richSpan is a stretch of rich text in one typeface and color. The spaces
+// between words are spans of their own, so justification can widen them
+// and decorations and links continue across them.
+type richSpan struct {
+	face  typeface
+	text  string
+	color color.Color
+	link  string
+	code  bool
+	space bool
+}
+
+// richWord is a word made of spans with no space between them, with the
+// space that separates it from the previous word
+type richWord struct {
+	space *richSpan
+	spans []richSpan
+}
+
+// richLine is one wrapped line of rich text
+type richLine struct {
+	spans []richSpan
+	// last marks the final line of a paragraph
+	last bool
+}
+
+// richBlock is rich text wrapped to a width at one font size
+type richBlock struct {
+	lines      []richLine
+	fontHeight float64
+	lineHeight float64
+}
+
+func (b richBlock) height() float64 {
+	return linesHeight(len(b.lines), b.fontHeight, b.lineHeight)
+}
+
+// layoutRich wraps runs to the bounds and applies the overflow policy.
+// Runs without a color are drawn in base.
+func layoutRich(ctx *Context, runs []model.TextRun, style model.Style, bounds model.Bounds, base color.Color) (richBlock, error) {
+	block, err := wrapRich(ctx, runs, style, style.FontSize, base, bounds.Width)
+	if err != nil {
+		return block, err
+	}
+
+	switch style.Overflow {
+	case model.OverflowShrink:
+		for size := style.FontSize - shrinkStep; block.height() > bounds.Height && size >= minShrinkFontSize; size -= shrinkStep {
+			if block, err = wrapRich(ctx, runs, style, size, base, bounds.Width); err != nil {
+				return block, err
+			}
+		}
+
+	case model.OverflowEllipsis:
+		visible := fitLines(bounds.Height, block.fontHeight, block.lineHeight)
+		if visible < len(block.lines) {
+			if visible == 0 {
+				visible = 1
+			}
+			block.lines = block.lines[:visible]
+			last := &block.lines[visible-1]
+			last.spans = truncateRich(last.spans, bounds.Width)
+			last.last = true
+		}
+	}
+
+	return block, nil
+}
+
+// wrapRich builds the spans of runs at the given font size and wraps them
+func wrapRich(ctx *Context, runs []model.TextRun, style model.Style, size float64, base color.Color, width float64) (richBlock, error) {
+	spans := make([]richSpan, 0, len(runs))
+	for _, run := range runs {
+		span, err := runSpan(ctx, run, style, size, base)
+		if err != nil {
+			return richBlock{}, err
+		}
+		spans = append(spans, span)
+	}
+
+	fontHeight := ctx.PDF.PointToUnitConvert(size)
+	return richBlock{
+		lines:      wrapSpans(richWords(spans), width),
+		fontHeight: fontHeight,
+		lineHeight: fontHeight * style.LineHeight,
+	}, nil
+}
+
+// runSpan applies a run's formatting on top of the element style
+func runSpan(ctx *Context, run model.TextRun, style model.Style, size float64, base color.Color) (richSpan, error) {
+	s := style
+	decorations := strings.Fields(string(s.TextDecoration))
+	if run.Bold {
+		s.FontWeight = model.FontWeightBold
+	}
+	if run.Italic {
+		s.FontStyle = model.FontStyleItalic
+	}
+	if run.Underline || run.Link != "" {
+		decorations = append(decorations, string(model.DecorationUnderline))
+	}
+	if run.Strike {
+		decorations = append(decorations, string(model.DecorationLineThrough))
+	}
+	if run.Code {
+		s.FontFamily = codeFontFamily
+	}
+	s.TextDecoration = model.TextDecoration(strings.Join(decorations, " "))
+
+	span := richSpan{face: styleTypeface(ctx, s).withSize(size), text: run.Text, color: base, link: run.Link, code: run.Code}
+	switch {
+	case run.Color != "":
+		c, err := color.Parse(run.Color)
+		if err != nil {
+			return span, fmt.Errorf("invalid run color: %w", err)
+		}
+		span.color = c
+	case run.Link != "":
+		span.color = linkColor
+	}
+	return span, nil
+}
+
+// richWords splits spans into paragraphs of words at whitespace and newlines
+func richWords(spans []richSpan) [][]richWord {
+	paragraphs := [][]richWord{nil}
+	var word richWord
+	var space *richSpan
+
+	endWord := func() {
+		if len(word.spans) > 0 {
+			last := len(paragraphs) - 1
+			paragraphs[last] = append(paragraphs[last], word)
+		}
+		word = richWord{}
+	}
+
+	for _, span := range spans {
+		var text strings.Builder
+		addText := func() {
+			if text.Len() == 0 {
+				return
+			}
+			if len(word.spans) == 0 {
+				word.space, space = space, nil
+			}
+			part := span
+			part.text = text.String()
+			word.spans = append(word.spans, part)
+			text.Reset()
+		}
+
+		for _, r := range span.text {
+			switch {
+			case r == '\n':
+				addText()
+				endWord()
+				paragraphs = append(paragraphs, nil)
+				space = nil
+			case unicode.IsSpace(r):
+				addText()
+				endWord()
+				if space == nil && len(paragraphs[len(paragraphs)-1]) > 0 {
+					gap := span
+					gap.text, gap.space = " ", true
+					space = &gap
+				}
+			default:
+				text.WriteRune(r)
+			}
+		}
+		addText()
+	}
+	endWord()
+	return paragraphs
+}
+
+// wrapSpans fills lines with words no wider than width. A word wider than
+// a whole line is broken between characters when it has a single style.
+// A width of zero disables wrapping.
+func wrapSpans(paragraphs [][]richWord, width float64) []richLine {
+	var lines []richLine
+	for _, paragraph := range paragraphs {
+		var line []richSpan
+		lineWidth := 0.0
+
+		for _, word := range paragraph {
+			wordWidth := spansWidth(word.spans)
+			spaceWidth := 0.0
+			if word.space != nil && len(line) > 0 {
+				spaceWidth = word.space.face.width(word.space.text)
+			}
+
+			if width > 0 && len(line) > 0 && lineWidth+spaceWidth+wordWidth > width {
+				lines = append(lines, richLine{spans: line})
+				line, lineWidth, spaceWidth = nil, 0, 0
+			}
+
+			if width > 0 && wordWidth > width && len(word.spans) == 1 {
+				span := word.spans[0]
+				pieces := breakWord(span.face.width, span.text, width)
+				for _, piece := range pieces[:len(pieces)-1] {
+					part := span
+					part.text = piece
+					lines = append(lines, richLine{spans: []richSpan{part}})
+				}
+				span.text = pieces[len(pieces)-1]
+				word.spans = []richSpan{span}
+				wordWidth = span.face.width(span.text)
+			}
+
+			if spaceWidth > 0 {
+				line = append(line, *word.space)
+				lineWidth += spaceWidth
+			}
+			line = append(line, word.spans...)
+			lineWidth += wordWidth
+		}
+		lines = append(lines, richLine{spans: line, last: true})
+	}
+	return lines
+}
+
+func spansWidth(spans []richSpan) float64 {
+	width := 0.0
+	for _, span := range spans {
+		width += span.face.width(span.text)
+	}
+	return width
+}
+
+// truncateRich shortens a line until it fits the width with an ellipsis
+// appended in the style of the last remaining text
+func truncateRich(spans []richSpan, width float64) []richSpan {
+	if len(spans) == 0 {
+		return spans
+	}
+	first := spans[0]
+	spans = append([]richSpan(nil), spans...)
+
+	for len(spans) > 0 {
+		last := &spans[len(spans)-1]
+		if !last.space {
+			if spansWidth(spans[:len(spans)-1])+last.face.width(last.text+ellipsis) <= width {
+				last.text += ellipsis
+				return spans
+			}
+			if runes := []rune(last.text); len(runes) > 1 {
+				last.text = string(runes[:len(runes)-1])
+				continue
+			}
+		}
+		spans = spans[:len(spans)-1]
+	}
+
+	first.text, first.space = ellipsis, false
+	return []richSpan{first}
+}
+
+// draw writes the lines from the top of bounds with the same alignment
+// rules as plain text
+func (b richBlock) draw(bounds model.Bounds, alignment model.TextAlignment) {
+	for i, line := range b.lines {
+		y := bounds.Y + b.fontHeight + float64(i)*b.lineHeight
+		lineWidth := spansWidth(line.spans)
+
+		x, extra := bounds.X, 0.0
+		switch alignment {
+		case model.AlignCenter:
+			x += (bounds.Width - lineWidth) / 2
+		case model.AlignRight:
+			x += bounds.Width - lineWidth
+		case model.AlignJustify:
+			spaces := 0
+			for _, span := range line.spans {
+				if span.space {
+					spaces++
+				}
+			}
+			if !line.last && spaces > 0 {
+				extra = (bounds.Width - lineWidth) / float64(spaces)
+			}
+		}
+
+		for _, span := range line.spans {
+			width := span.face.width(span.text)
+			if span.space {
+				width += extra
+			}
+			b.drawSpan(span, x, y, width)
+			x += width
+		}
+	}
+}
+
+// drawSpan draws one span with its left end at x and its baseline at y
+func (b richBlock) drawSpan(span richSpan, x, y, width float64) {
+	pdf := span.face.ctx.PDF
+	top, height := y-b.fontHeight, b.fontHeight*(1+descentRatio)
+
+	if span.code {
+		pdf.SetFillColor(codeBackground.R, codeBackground.G, codeBackground.B)
+		pdf.Rect(x, top, width, height, "F")
+	}
+
+	pdf.SetTextColor(span.color.R, span.color.G, span.color.B)
+	withAlpha(pdf, span.color, func() {
+		if !span.space {
+			span.face.write(x, y, span.text)
+		}
+		span.face.decorate(x, y, width)
+	})
+
+	if span.link != "" {
+		pdf.LinkString(x, top, width, height, span.link)
+	}
+}
@@ -0,0 +1,161 @@
+package render
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/internal/color"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+func TestTextContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  interface{}
+		metadata string
+		wantText string
+		wantRuns []model.TextRun
+		wantErr  string
+	}{
+		{name: "plain", content: "**as typed**", wantText: "**as typed**"},
+		{
+			name:     "markdown",
+			content:  "Hello **{{ name }}**",
+			metadata: `{"format": "markdown"}`,
+			wantRuns: []model.TextRun{{Text: "Hello "}, {Text: "{{ name }}", Bold: true}},
+		},
+		{
+			name: "runs",
+			content: []interface{}{
+				"Total: ",
+				map[string]interface{}{"text": 42.5, "bold": true, "color": "red"},
+				map[string]interface{}{"text": " terms", "link": "https://example.com"},
+			},
+			wantRuns: []model.TextRun{
+				{Text: "Total: "},
+				{Text: "42.50", Bold: true, Color: "red"},
+				{Text: " terms", Link: "https://example.com"},
+			},
+		},
+		{name: "unknown format", content: "x", metadata: `{"format": "html"}`, wantErr: `unknown text format "html"`},
+		{
+			name:    "invalid flag",
+			content: []interface{}{map[string]interface{}{"text": "x", "bold": "yes"}},
+			wantErr: "bold must be true or false",
+		},
+		{name: "invalid content", content: 42, wantErr: "invalid content type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			element := model.Element{ID: "letter", Content: tt.content}
+			if tt.metadata != "" {
+				element.Metadata = json.RawMessage(tt.metadata)
+			}
+
+			text, runs, err := textContent(element)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("textContent() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("textContent() error = %v", err)
+			}
+			if text != tt.wantText || !reflect.DeepEqual(runs, tt.wantRuns) {
+				t.Errorf("textContent() = %q, %+v, want %q, %+v", text, runs, tt.wantText, tt.wantRuns)
+			}
+		})
+	}
+}
+
+// lineTexts joins the spans of each line
+func lineTexts(lines []richLine) []string {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		for _, span := range line.spans {
+			texts[i] += span.text
+		}
+	}
+	return texts
+}
+
+func TestWrapRich(t *testing.T) {
+	ctx := newTestContext()
+	style := textStyle(nil)
+	runs := []model.TextRun{
+		{Text: "Your order "},
+		{Text: "ORD-1", Bold: true},
+		{Text: "'s total is "},
+		{Text: "due today", Underline: true},
+		{Text: ".\nThanks!"},
+	}
+	face := styleTypeface(ctx, style)
+	width := face.width("Your order ORD-1's total")
+
+	block, err := wrapRich(ctx, runs, style, style.FontSize, color.Black, width+1)
+	if err != nil {
+		t.Fatalf("wrapRich() error = %v", err)
+	}
+
+	want := []string{"Your order ORD-1's total", "is due today.", "Thanks!"}
+	if got := lineTexts(block.lines); !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+	if !block.lines[1].last || block.lines[0].last {
+		t.Errorf("paragraph ends not marked on the right lines")
+	}
+
+	// The space inside the underlined run is underlined with it
+	spans := block.lines[1].spans
+	if len(spans) != 6 || !spans[3].space || !spans[3].face.underline {
+		t.Errorf("space between underlined words is not underlined: %+v", spans)
+	}
+}
+
+func TestTruncateRich(t *testing.T) {
+	ctx := newTestContext()
+	style := textStyle(nil)
+	runs := []model.TextRun{{Text: "Balance "}, {Text: "outstanding", Bold: true}}
+	block, err := wrapRich(ctx, runs, style, style.FontSize, color.Black, 0)
+	if err != nil {
+		t.Fatalf("wrapRich() error = %v", err)
+	}
+
+	face := styleTypeface(ctx, style)
+	width := face.width("Balance out") + 1
+	got := lineTexts([]richLine{{spans: truncateRich(block.lines[0].spans, width)}})[0]
+	if !strings.HasPrefix(got, "Balance ") || !strings.HasSuffix(got, ellipsis) || len(got) >= len("Balance outstanding...") {
+		t.Errorf("truncateRich() = %q", got)
+	}
+}
+
+func TestTextRenderer_RenderRich(t *testing.T) {
+	ctx := newTestContext()
+	element := model.Element{
+		ID:       "letter",
+		Type:     model.ElementTypeText,
+		Bounds:   model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 150, Height: 20}},
+		Content:  "Read our [terms](https://example.com/terms) **before** paying `INV-7`",
+		Metadata: json.RawMessage(`{"format": "markdown"}`),
+	}
+	if err := (&TextRenderer{}).Render(ctx, element); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	out := output(t, ctx)
+	for _, want := range []string{
+		"/URI (https://example.com/terms)",
+		"/BaseFont /Helvetica-Bold\n",
+		"/BaseFont /Courier\n",
+		"(before) Tj",
+		"(INV-7) Tj",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("document does not contain %q", want)
+		}
+	}
+}
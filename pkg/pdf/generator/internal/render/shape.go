@@ -0,0 +1,205 @@
+package render
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/internal/color"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// defaultShapeStroke outlines shapes that set neither a border nor a background
+var defaultShapeStroke = model.Border{Width: 0.2, Style: model.BorderSolid}
+
+// ShapeRenderer handles rendering of vector shape elements. The metadata
+// selects the outline; the style's border strokes it and its background
+// fills it.
+type ShapeRenderer struct{}
+
+func (r *ShapeRenderer) Render(ctx *Context, element model.Element) error {
+	var opts model.ShapeOptions
+	if err := element.DecodeMetadata(&opts); err != nil {
+		return err
+	}
+	segments, err := shapeOutline(opts, element.Bounds.Size)
+	if err != nil {
+		return fmt.Errorf("invalid shape of element %s: %w", element.ID, err)
+	}
+	for _, d := range opts.Dash {
+		if d < 0 {
+			return fmt.Errorf("dash of element %s must not be negative, got %v", element.ID, d)
+		}
+	}
+
+	fill, border := shapePaint(opts, element.Style)
+
+	pdf := ctx.PDF
+	origin := element.Bounds.Position
+	if fill {
+		background, err := color.Parse(element.Style.Background)
+		if err != nil {
+			return fmt.Errorf("invalid background of element %s: %w", element.ID, err)
+		}
+		if !background.Transparent() {
+			withAlpha(pdf, background, func() {
+				pdf.SetFillColor(background.R, background.G, background.B)
+				tracePath(pdf, segments, origin)
+				pdf.DrawPath("f")
+			})
+		}
+	}
+
+	ok, err := setStroke(pdf, border)
+	if err != nil {
+		return fmt.Errorf("invalid border of element %s: %w", element.ID, err)
+	}
+	if !ok {
+		return nil
+	}
+	defer resetStroke(pdf)
+	if len(opts.Dash) > 0 {
+		pdf.SetLineCapStyle("butt")
+		pdf.SetDashPattern(opts.Dash, 0)
+	}
+
+	segments, heads := arrowheads(segments, opts, border.Width)
+	tracePath(pdf, segments, origin)
+	pdf.DrawPath("D")
+
+	if len(heads) > 0 {
+		c := color.Black
+		if border.Color != "" {
+			// setStroke has validated the color
+			c, _ = color.Parse(border.Color)
+		}
+		pdf.SetDashPattern(nil, 0)
+		pdf.SetFillColor(c.R, c.G, c.B)
+		for _, head := range heads {
+			points := make([]gofpdf.PointType, len(head))
+			for i, pt := range head {
+				points[i] = gofpdf.PointType{X: origin.X + pt.X, Y: origin.Y + pt.Y}
+			}
+			pdf.Polygon(points, "F")
+		}
+	}
+	return nil
+}
+
+// shapePaint returns whether a shape is filled with the style's background
+// and the border it is stroked with, nil for none
+func shapePaint(opts model.ShapeOptions, style *model.Style) (bool, *model.Border) {
+	if style == nil {
+		style = &model.Style{}
+	}
+	// Lines have nothing to fill
+	fill := opts.Shape != model.ShapeLine && style.Background != ""
+	border := style.Border
+	if border == nil && (!fill || opts.Shape == model.ShapeLine || opts.Shape == model.ShapePolyline) {
+		border = &defaultShapeStroke
+	}
+	return fill, border
+}
+
+// shapeOutline returns the outline of a shape in millimetres from the
+// top-left corner of its element
+func shapeOutline(opts model.ShapeOptions, size model.Size) ([]pathSegment, error) {
+	switch opts.Shape {
+	case model.ShapeRect:
+		return rectPath(size, opts.Radius, opts.Radius), nil
+	case model.ShapeEllipse:
+		return ellipsePath(size), nil
+	case model.ShapeLine:
+		points := opts.Points
+		if len(points) == 0 {
+			points = []model.Position{{X: 0, Y: 0}, {X: size.Width, Y: size.Height}}
+		}
+		if len(points) != 2 {
+			return nil, fmt.Errorf("a line needs 2 points, got %d", len(points))
+		}
+		return polylinePath(points, false), nil
+	case model.ShapePolyline, model.ShapePolygon:
+		if len(opts.Points) < 2 {
+			return nil, fmt.Errorf("a %s needs at least 2 points, got %d", opts.Shape, len(opts.Points))
+		}
+		return polylinePath(opts.Points, opts.Shape == model.ShapePolygon), nil
+	case model.ShapePath:
+		if opts.Path == "" {
+			return nil, fmt.Errorf("path is empty")
+		}
+		return parsePath(opts.Path)
+	case "":
+		return nil, fmt.Errorf("shape is required")
+	}
+	return nil, fmt.Errorf("unknown shape %q", opts.Shape)
+}
+
+// shortenPath pulls an end of an outline back by distance when it ends in a
+// straight line long enough to keep a visible part
+func shortenPath(segments []pathSegment, atStart bool, distance float64) []pathSegment {
+	out := append([]pathSegment(nil), segments...)
+	if atStart {
+		if out[1].op != pathLine {
+			return out
+		}
+		from, to := out[0].to, out[1].to
+		if moved, ok := moveToward(from, to, distance); ok {
+			out[0].to = moved
+		}
+		return out
+	}
+
+	last := len(out) - 1
+	if out[last].op != pathLine {
+		return out
+	}
+	from, to := out[last].to, out[last-1].to
+	if moved, ok := moveToward(from, to, distance); ok {
+		out[last].to = moved
+	}
+	return out
+}
+
+// moveToward moves a point the given distance toward another when they are
+// further apart than that
+func moveToward(from, to model.Position, distance float64) (model.Position, bool) {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	length := math.Hypot(dx, dy)
+	if length <= distance {
+		return from, false
+	}
+	return model.Position{X: from.X + dx/length*distance, Y: from.Y + dy/length*distance}, true
+}
+
+// arrowheads returns the triangles, tip first, of the arrowheads the
+// options ask for on an outline stroked with the given width, and the
+// outline stopped inside them so its ends do not show at the tips
+func arrowheads(segments []pathSegment, opts model.ShapeOptions, width float64) ([]pathSegment, [][3]model.Position) {
+	size := opts.ArrowSize
+	if size <= 0 {
+		size = max(4*width, 1.5)
+	}
+	var heads [][3]model.Position
+	for _, end := range []struct {
+		enabled bool
+		atStart bool
+	}{{opts.ArrowStart, true}, {opts.ArrowEnd, false}} {
+		if !end.enabled {
+			continue
+		}
+		tip, direction, ok := pathEnd(segments, end.atStart)
+		if !ok {
+			continue
+		}
+		// The base is 0.8 of the length wide
+		base := model.Position{X: tip.X - direction.X*size, Y: tip.Y - direction.Y*size}
+		nx, ny := -direction.Y*size*0.4, direction.X*size*0.4
+		heads = append(heads, [3]model.Position{
+			tip,
+			{X: base.X + nx, Y: base.Y + ny},
+			{X: base.X - nx, Y: base.Y - ny},
+		})
+		segments = shortenPath(segments, end.atStart, size/2)
+	}
+	return segments, heads
+}
@@ -0,0 +1,372 @@
+package render
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+func TestParsePath(t *testing.T) {
+	pt := func(x, y float64) model.Position { return model.Position{X: x, Y: y} }
+
+	tests := []struct {
+		name    string
+		path    string
+		want    []pathSegment
+		wantErr string
+	}{
+		{
+			name: "absolute lines",
+			path: "M 0 0 L 10,0 H 10 V 5 Z",
+			want: []pathSegment{
+				{op: pathMove, to: pt(0, 0)},
+				{op: pathLine, to: pt(10, 0)},
+				{op: pathLine, to: pt(10, 0)},
+				{op: pathLine, to: pt(10, 5)},
+				{op: pathClose, to: pt(0, 0)},
+			},
+		},
+		{
+			name: "relative with implicit lines",
+			path: "m1 1 2 0 0 2h-2z",
+			want: []pathSegment{
+				{op: pathMove, to: pt(1, 1)},
+				{op: pathLine, to: pt(3, 1)},
+				{op: pathLine, to: pt(3, 3)},
+				{op: pathLine, to: pt(1, 3)},
+				{op: pathClose, to: pt(1, 1)},
+			},
+		},
+		{
+			name: "compact numbers",
+			path: "M1.5.5L-2-3e1",
+			want: []pathSegment{
+				{op: pathMove, to: pt(1.5, 0.5)},
+				{op: pathLine, to: pt(-2, -30)},
+			},
+		},
+		{
+			name: "smooth cubic reflects the control point",
+			path: "M0 0 C0 5 5 5 5 0 S10 -5 10 0",
+			want: []pathSegment{
+				{op: pathMove, to: pt(0, 0)},
+				{op: pathCurve, c1: pt(0, 5), c2: pt(5, 5), to: pt(5, 0)},
+				{op: pathCurve, c1: pt(5, -5), c2: pt(10, -5), to: pt(10, 0)},
+			},
+		},
+		{
+			name: "quadratic becomes cubic",
+			path: "M0 0 Q3 6 6 0",
+			want: []pathSegment{
+				{op: pathMove, to: pt(0, 0)},
+				{op: pathCurve, c1: pt(2, 4), c2: pt(4, 4), to: pt(6, 0)},
+			},
+		},
+		{name: "starts with a line", path: "L 1 1", wantErr: "must start with a move"},
+		{name: "starts with a number", path: "1 1", wantErr: "must start with a command"},
+		{name: "missing coordinate", path: "M 1", wantErr: "ends before a number"},
+		{name: "bad number", path: "M 1 x", wantErr: "expected a number"},
+		{
+			name: "arc",
+			path: "M0 0 A 5 5 0 0 1 10 0",
+			want: []pathSegment{
+				{op: pathMove, to: pt(0, 0)},
+				{op: pathCurve, c1: pt(0, -2.7614237), c2: pt(2.2385763, -5), to: pt(5, -5)},
+				{op: pathCurve, c1: pt(7.7614237, -5), c2: pt(10, -2.7614237), to: pt(10, 0)},
+			},
+		},
+		{
+			name: "compact arc flags",
+			path: "M0 0a5 5 0 1010 0",
+			want: []pathSegment{
+				{op: pathMove, to: pt(0, 0)},
+				{op: pathCurve, c1: pt(0, 2.7614237), c2: pt(2.2385763, 5), to: pt(5, 5)},
+				{op: pathCurve, c1: pt(7.7614237, 5), c2: pt(10, 2.7614237), to: pt(10, 0)},
+			},
+		},
+		{name: "bad arc flag", path: "M0 0 A 5 5 0 2 1 10 0", wantErr: "expected an arc flag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePath(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parsePath() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePath() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parsePath() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if !closeSegment(got[i], tt.want[i]) {
+					t.Errorf("segment %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func closeSegment(a, b pathSegment) bool {
+	near := func(p, q model.Position) bool {
+		dx, dy := p.X-q.X, p.Y-q.Y
+		return dx*dx+dy*dy < 1e-12
+	}
+	if a.op != b.op || !near(a.to, b.to) {
+		return false
+	}
+	return a.op != pathCurve || near(a.c1, b.c1) && near(a.c2, b.c2)
+}
+
+func TestShapeOutline(t *testing.T) {
+	pt := func(x, y float64) model.Position { return model.Position{X: x, Y: y} }
+	size := model.Size{Width: 40, Height: 20}
+
+	tests := []struct {
+		name    string
+		opts    model.ShapeOptions
+		want    []pathSegment
+		wantErr string
+	}{
+		{
+			name: "rect",
+			opts: model.ShapeOptions{Shape: model.ShapeRect},
+			want: []pathSegment{
+				{op: pathMove, to: pt(0, 0)},
+				{op: pathLine, to: pt(40, 0)},
+				{op: pathLine, to: pt(40, 20)},
+				{op: pathLine, to: pt(0, 20)},
+				{op: pathClose, to: pt(0, 0)},
+			},
+		},
+		{
+			// The radius is limited to half of each side
+			name: "rounded rect",
+			opts: model.ShapeOptions{Shape: model.ShapeRect, Radius: 15},
+			want: rectPath(size, 15, 10),
+		},
+		{
+			name: "ellipse",
+			opts: model.ShapeOptions{Shape: model.ShapeEllipse},
+			want: []pathSegment{
+				{op: pathMove, to: pt(40, 10)},
+				{op: pathCurve, c1: pt(40, 10+10*kappa), c2: pt(20+20*kappa, 20), to: pt(20, 20)},
+				{op: pathCurve, c1: pt(20-20*kappa, 20), c2: pt(0, 10+10*kappa), to: pt(0, 10)},
+				{op: pathCurve, c1: pt(0, 10-10*kappa), c2: pt(20-20*kappa, 0), to: pt(20, 0)},
+				{op: pathCurve, c1: pt(20+20*kappa, 0), c2: pt(40, 10-10*kappa), to: pt(40, 10)},
+				{op: pathClose, to: pt(40, 10)},
+			},
+		},
+		{
+			name: "line across the bounds",
+			opts: model.ShapeOptions{Shape: model.ShapeLine},
+			want: []pathSegment{{op: pathMove, to: pt(0, 0)}, {op: pathLine, to: pt(40, 20)}},
+		},
+		{
+			name: "polyline stays open",
+			opts: model.ShapeOptions{Shape: model.ShapePolyline, Points: []model.Position{pt(0, 0), pt(20, 10), pt(40, 0)}},
+			want: []pathSegment{{op: pathMove, to: pt(0, 0)}, {op: pathLine, to: pt(20, 10)}, {op: pathLine, to: pt(40, 0)}},
+		},
+		{
+			name: "polygon closes",
+			opts: model.ShapeOptions{Shape: model.ShapePolygon, Points: []model.Position{pt(0, 20), pt(20, 0), pt(40, 20)}},
+			want: []pathSegment{
+				{op: pathMove, to: pt(0, 20)},
+				{op: pathLine, to: pt(20, 0)},
+				{op: pathLine, to: pt(40, 20)},
+				{op: pathClose, to: pt(0, 20)},
+			},
+		},
+		{
+			name: "path",
+			opts: model.ShapeOptions{Shape: model.ShapePath, Path: "M0 20 L20 0 Z"},
+			want: []pathSegment{{op: pathMove, to: pt(0, 20)}, {op: pathLine, to: pt(20, 0)}, {op: pathClose, to: pt(0, 20)}},
+		},
+		{name: "unknown shape", opts: model.ShapeOptions{Shape: "star"}, wantErr: "unknown shape"},
+		{name: "missing shape", wantErr: "shape is required"},
+		{name: "line with 3 points", opts: model.ShapeOptions{Shape: model.ShapeLine, Points: []model.Position{pt(0, 0), pt(1, 1), pt(2, 2)}}, wantErr: "needs 2 points"},
+		{name: "too few points", opts: model.ShapeOptions{Shape: model.ShapePolygon, Points: []model.Position{pt(0, 0)}}, wantErr: "at least 2 points"},
+		{name: "empty path", opts: model.ShapeOptions{Shape: model.ShapePath}, wantErr: "path is empty"},
+		{name: "invalid path", opts: model.ShapeOptions{Shape: model.ShapePath, Path: "M0 0 X"}, wantErr: "expected a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shapeOutline(tt.opts, size)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("shapeOutline() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("shapeOutline() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("shapeOutline() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if !closeSegment(got[i], tt.want[i]) {
+					t.Errorf("segment %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestShapePaint(t *testing.T) {
+	border := &model.Border{Width: 0.5, Color: "#ff0000"}
+
+	tests := []struct {
+		name       string
+		shape      model.ShapeKind
+		style      *model.Style
+		wantFill   bool
+		wantBorder *model.Border
+	}{
+		{name: "no style strokes thinly", shape: model.ShapeRect, wantBorder: &defaultShapeStroke},
+		{name: "background alone fills", shape: model.ShapeRect, style: &model.Style{Background: "#eeeeee"}, wantFill: true},
+		{name: "background and border", shape: model.ShapeEllipse, style: &model.Style{Background: "#eeeeee", Border: border}, wantFill: true, wantBorder: border},
+		{name: "lines are never filled", shape: model.ShapeLine, style: &model.Style{Background: "#eeeeee"}, wantBorder: &defaultShapeStroke},
+		{name: "filled polylines keep a stroke", shape: model.ShapePolyline, style: &model.Style{Background: "#eeeeee"}, wantFill: true, wantBorder: &defaultShapeStroke},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fill, border := shapePaint(model.ShapeOptions{Shape: tt.shape}, tt.style)
+			if fill != tt.wantFill {
+				t.Errorf("shapePaint() fill = %v, want %v", fill, tt.wantFill)
+			}
+			if border != tt.wantBorder {
+				t.Errorf("shapePaint() border = %+v, want %+v", border, tt.wantBorder)
+			}
+		})
+	}
+}
+
+func TestArrowheads(t *testing.T) {
+	pt := func(x, y float64) model.Position { return model.Position{X: x, Y: y} }
+	line := polylinePath([]model.Position{pt(0, 10), pt(40, 10)}, false)
+
+	tests := []struct {
+		name      string
+		segments  []pathSegment
+		opts      model.ShapeOptions
+		width     float64
+		wantLine  []pathSegment
+		wantHeads [][3]model.Position
+	}{
+		{
+			name:     "none",
+			segments: line,
+			wantLine: line,
+		},
+		{
+			// 4 times the 0.5mm stroke, 2mm long and 1.6mm wide
+			name:     "both ends",
+			segments: line,
+			opts:     model.ShapeOptions{ArrowStart: true, ArrowEnd: true},
+			width:    0.5,
+			wantLine: []pathSegment{{op: pathMove, to: pt(1, 10)}, {op: pathLine, to: pt(39, 10)}},
+			wantHeads: [][3]model.Position{
+				{pt(0, 10), pt(2, 9.2), pt(2, 10.8)},
+				{pt(40, 10), pt(38, 10.8), pt(38, 9.2)},
+			},
+		},
+		{
+			name:      "thin strokes take the smallest size",
+			segments:  line,
+			opts:      model.ShapeOptions{ArrowEnd: true},
+			width:     0.1,
+			wantLine:  []pathSegment{{op: pathMove, to: pt(0, 10)}, {op: pathLine, to: pt(39.25, 10)}},
+			wantHeads: [][3]model.Position{{pt(40, 10), pt(38.5, 10.6), pt(38.5, 9.4)}},
+		},
+		{
+			name:      "set size",
+			segments:  polylinePath([]model.Position{pt(0, 0), pt(0, 10)}, false),
+			opts:      model.ShapeOptions{ArrowEnd: true, ArrowSize: 5},
+			wantLine:  []pathSegment{{op: pathMove, to: pt(0, 0)}, {op: pathLine, to: pt(0, 7.5)}},
+			wantHeads: [][3]model.Position{{pt(0, 10), pt(-2, 5), pt(2, 5)}},
+		},
+		{
+			name:     "closed outlines have no end",
+			segments: rectPath(model.Size{Width: 10, Height: 10}, 0, 0),
+			opts:     model.ShapeOptions{ArrowEnd: true},
+			wantLine: rectPath(model.Size{Width: 10, Height: 10}, 0, 0),
+		},
+	}
+
+	near := func(p, q model.Position) bool { return math.Abs(p.X-q.X) < 1e-9 && math.Abs(p.Y-q.Y) < 1e-9 }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments, heads := arrowheads(tt.segments, tt.opts, tt.width)
+			if len(segments) != len(tt.wantLine) {
+				t.Fatalf("arrowheads() outline = %+v, want %+v", segments, tt.wantLine)
+			}
+			for i := range segments {
+				if !closeSegment(segments[i], tt.wantLine[i]) {
+					t.Errorf("outline segment %d = %+v, want %+v", i, segments[i], tt.wantLine[i])
+				}
+			}
+			if len(heads) != len(tt.wantHeads) {
+				t.Fatalf("arrowheads() heads = %+v, want %+v", heads, tt.wantHeads)
+			}
+			for i, head := range heads {
+				for j := range head {
+					if !near(head[j], tt.wantHeads[i][j]) {
+						t.Errorf("head %d = %+v, want %+v", i, head, tt.wantHeads[i])
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestShapeRenderer_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    string
+		style   *model.Style
+		wantErr string
+	}{
+		{name: "invalid shape", opts: `{"shape": "star"}`, wantErr: "invalid shape of element shape"},
+		{name: "negative dash", opts: `{"shape": "line", "dash": [-1]}`, wantErr: "must not be negative"},
+		{name: "invalid background", opts: `{"shape": "rect"}`, style: &model.Style{Background: "shiny"}, wantErr: "invalid background"},
+		{name: "invalid border", opts: `{"shape": "rect"}`, style: &model.Style{Border: &model.Border{Width: 1, Color: "shiny"}}, wantErr: "invalid border"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			element := model.Element{
+				ID:       "shape",
+				Type:     model.ElementTypeShape,
+				Bounds:   model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 40, Height: 20}},
+				Style:    tt.style,
+				Metadata: json.RawMessage(tt.opts),
+			}
+			err := (&ShapeRenderer{}).Render(newTestContext(), element)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Render() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShortenPath(t *testing.T) {
+	segments := polylinePath([]model.Position{{X: 0, Y: 0}, {X: 10, Y: 0}}, false)
+	got := shortenPath(shortenPath(segments, true, 2), false, 2)
+	if got[0].to.X != 2 || got[1].to.X != 8 {
+		t.Errorf("shortenPath() = %+v, want the line from 2 to 8", got)
+	}
+	if segments[0].to.X != 0 {
+		t.Errorf("shortenPath() changed its input")
+	}
+}
@@ -0,0 +1,1001 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/assets"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/fonts"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/internal/color"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// svgPixel is the size of an SVG user unit in millimetres, a CSS pixel
+const svgPixel = 25.4 / 96
+
+// svgMaxDepth limits the nesting of groups and use references, which could
+// otherwise refer to each other without end
+const svgMaxDepth = 64
+
+// svgMaxNodes limits the elements drawn for one document. Use references
+// that each draw several others multiply, so a small file could otherwise
+// draw millions of elements.
+const svgMaxNodes = 20000
+
+// svgCoreFonts maps font families to the gofpdf core fonts
+var svgCoreFonts = map[string]string{
+	"arial":      "Arial",
+	"helvetica":  "Helvetica",
+	"sans-serif": "Helvetica",
+	"times":      "Times",
+	"serif":      "Times",
+	"courier":    "Courier",
+	"monospace":  "Courier",
+}
+
+// SVGRenderer handles rendering of SVG elements. Content is SVG markup, or
+// a source opened like an image's. Paths, basic shapes, groups, use
+// references, transforms, fills, strokes and simple text are drawn as
+// vectors; gradients are painted in their first stop color, and filters,
+// masks and clip paths are left out. The metadata takes the image options,
+// with the graphic contained in its bounds by default.
+type SVGRenderer struct{}
+
+func (r *SVGRenderer) Render(ctx *Context, element model.Element) error {
+	src, ok := element.Content.(string)
+	if !ok {
+		return fmt.Errorf("invalid content type for svg element")
+	}
+	var opts model.ImageOptions
+	if err := element.DecodeMetadata(&opts); err != nil {
+		return err
+	}
+
+	data := []byte(src)
+	if !strings.HasPrefix(strings.TrimSpace(src), "<") {
+		var err error
+		if data, err = assets.ReadAll(ctx.Assets, src); err != nil {
+			return fmt.Errorf("failed to load svg of element %s: %w", element.ID, err)
+		}
+	}
+	doc, err := parseSVG(data)
+	if err != nil {
+		return fmt.Errorf("invalid svg of element %s: %w", element.ID, err)
+	}
+
+	if opts.Fit == "" {
+		opts.Fit = model.ImageFitContain
+		if doc.aspect.none {
+			opts.Fit = model.ImageFitFill
+		}
+	}
+	return placeImage(ctx, element, opts, doc.size, func(placed model.Bounds, opacity float64) error {
+		defer resetStroke(ctx.PDF)
+
+		m := viewBoxMatrix(doc.viewBox, placed, doc.aspect)
+		if doc.aspect.slice {
+			ctx.PDF.ClipRect(placed.X, placed.Y, placed.Width, placed.Height, false)
+			defer ctx.PDF.ClipEnd()
+		}
+		d := &svgDrawer{ctx: ctx, doc: doc, canvas: svgPage{ctx}, family: textStyle(element.Style).FontFamily}
+		style := defaultSVGStyle
+		style.opacity = opacity
+		for _, child := range doc.root.children {
+			if err := d.draw(child, m, style, 0); err != nil {
+				return fmt.Errorf("failed to draw svg of element %s: %w", element.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// svgNode is an element of an SVG document. Text between elements is kept
+// as children named "#text".
+type svgNode struct {
+	name     string
+	attrs    map[string]string
+	children []*svgNode
+	text     string
+}
+
+// svgDocument is a parsed SVG document with its size and view box
+type svgDocument struct {
+	root *svgNode
+	ids  map[string]*svgNode
+	// rules are the style sheet rules of the document's style elements
+	rules   []cssRule
+	viewBox model.Bounds
+	// size is the natural size of the document in millimetres
+	size model.Size
+	// aspect is how the view box fits the size
+	aspect svgAspect
+}
+
+// svgAspect is how a view box fits the viewport, set by preserveAspectRatio
+type svgAspect struct {
+	// none stretches the view box to fill the viewport on each axis
+	none bool
+	// alignX and alignY place the scaled view box, 0 at the start, 0.5 in
+	// the middle and 1 at the end of each axis
+	alignX, alignY float64
+	// slice covers the viewport, cropping the view box, instead of fitting
+	// all of it
+	slice bool
+}
+
+// parseAspect reads a preserveAspectRatio value, xMidYMid meet by default
+func parseAspect(s string) svgAspect {
+	aspect := svgAspect{alignX: 0.5, alignY: 0.5}
+	fields := strings.Fields(s)
+	if len(fields) > 0 && fields[0] == "defer" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return aspect
+	}
+	if fields[0] == "none" {
+		return svgAspect{none: true}
+	}
+	if align := fields[0]; len(align) == 8 {
+		positions := map[string]float64{"Min": 0, "Mid": 0.5, "Max": 1}
+		if x, ok := positions[align[1:4]]; ok && align[0] == 'x' {
+			aspect.alignX = x
+		}
+		if y, ok := positions[align[5:8]]; ok && align[4] == 'Y' {
+			aspect.alignY = y
+		}
+	}
+	aspect.slice = len(fields) > 1 && fields[1] == "slice"
+	return aspect
+}
+
+// parseSVG reads an SVG document. The parser is lenient about namespaces
+// and HTML entities, as exported graphics often are.
+func parseSVG(data []byte) (*svgDocument, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+
+	doc := &svgDocument{ids: make(map[string]*svgNode)}
+	var stack []*svgNode
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &svgNode{name: t.Name.Local, attrs: make(map[string]string, len(t.Attr))}
+			for _, a := range t.Attr {
+				// xlink:href and href are the same reference
+				n.attrs[a.Name.Local] = a.Value
+			}
+			if id := n.attrs["id"]; id != "" {
+				doc.ids[id] = n
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if doc.root == nil {
+				doc.root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, &svgNode{name: "#text", text: string(t)})
+			}
+		}
+	}
+	if doc.root == nil || doc.root.name != "svg" {
+		return nil, fmt.Errorf("document has no svg root element")
+	}
+
+	var collect func(n *svgNode)
+	collect = func(n *svgNode) {
+		if n.name == "style" {
+			doc.rules = append(doc.rules, parseCSS(nodeText(n))...)
+			return
+		}
+		for _, child := range n.children {
+			collect(child)
+		}
+	}
+	collect(doc.root)
+
+	// A missing size follows the view box, and both default to 300x150
+	root := doc.root.attrs
+	width, hasWidth := svgLength(root["width"], 0)
+	height, hasHeight := svgLength(root["height"], 0)
+	view, err := numberList(root["viewBox"])
+	if err != nil || len(view) != 4 || view[2] <= 0 || view[3] <= 0 {
+		if !hasWidth {
+			width = 300
+		}
+		if !hasHeight {
+			height = 150
+		}
+		view = []float64{0, 0, width, height}
+	}
+	switch {
+	case !hasWidth && !hasHeight:
+		width, height = view[2], view[3]
+	case !hasWidth:
+		width = height * view[2] / view[3]
+	case !hasHeight:
+		height = width * view[3] / view[2]
+	}
+	doc.viewBox = model.Bounds{Position: model.Position{X: view[0], Y: view[1]}, Size: model.Size{Width: view[2], Height: view[3]}}
+	doc.size = model.Size{Width: width * svgPixel, Height: height * svgPixel}
+	doc.aspect = parseAspect(root["preserveAspectRatio"])
+	return doc, nil
+}
+
+// nodeText returns the text inside a node and its descendants
+func nodeText(n *svgNode) string {
+	if n.name == "#text" {
+		return n.text
+	}
+	var sb strings.Builder
+	for _, child := range n.children {
+		sb.WriteString(nodeText(child))
+	}
+	return sb.String()
+}
+
+// properties returns the presentation properties of a node. Style sheet
+// rules override attributes, and the style attribute overrides both.
+func (doc *svgDocument) properties(n *svgNode) map[string]string {
+	props := make(map[string]string, len(n.attrs))
+	for key, value := range n.attrs {
+		props[key] = value
+	}
+	for _, rule := range doc.rules {
+		if rule.matches(n) {
+			for key, value := range rule.declarations {
+				props[key] = value
+			}
+		}
+	}
+	for key, value := range parseDeclarations(n.attrs["style"]) {
+		props[key] = value
+	}
+	return props
+}
+
+// cssRule is a style sheet rule with a single selector
+type cssRule struct {
+	selector     string
+	declarations map[string]string
+}
+
+// parseCSS reads the rules of a style sheet. Selectors are split so that
+// each rule has one; at-rules are skipped.
+func parseCSS(sheet string) []cssRule {
+	for {
+		start := strings.Index(sheet, "/*")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(sheet[start+2:], "*/")
+		if end < 0 {
+			sheet = sheet[:start]
+			break
+		}
+		sheet = sheet[:start] + sheet[start+2+end+2:]
+	}
+
+	var rules []cssRule
+	for _, block := range strings.Split(sheet, "}") {
+		selectors, body, ok := strings.Cut(block, "{")
+		if !ok || strings.HasPrefix(strings.TrimSpace(selectors), "@") {
+			continue
+		}
+		declarations := parseDeclarations(body)
+		for _, selector := range strings.Split(selectors, ",") {
+			rules = append(rules, cssRule{selector: strings.TrimSpace(selector), declarations: declarations})
+		}
+	}
+	return rules
+}
+
+// parseDeclarations reads "name: value" pairs separated by semicolons
+func parseDeclarations(s string) map[string]string {
+	declarations := make(map[string]string)
+	for _, declaration := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+		declarations[strings.ToLower(strings.TrimSpace(name))] = value
+	}
+	return declarations
+}
+
+// matches reports whether the rule selects a node. Only simple class, ID,
+// type and universal selectors are supported.
+func (r cssRule) matches(n *svgNode) bool {
+	switch {
+	case r.selector == "*":
+		return true
+	case strings.ContainsAny(r.selector, " >+~:[") || r.selector == "":
+		return false
+	case strings.HasPrefix(r.selector, "."):
+		for _, class := range strings.Fields(n.attrs["class"]) {
+			if class == r.selector[1:] {
+				return true
+			}
+		}
+		return false
+	case strings.HasPrefix(r.selector, "#"):
+		return n.attrs["id"] == r.selector[1:]
+	}
+	return n.name == r.selector
+}
+
+// svgStyle holds the presentation properties a node draws with
+type svgStyle struct {
+	fill, stroke  string
+	fillOpacity   float64
+	strokeOpacity float64
+	opacity       float64
+	strokeWidth   float64
+	fillRule      string
+	lineCap       string
+	lineJoin      string
+	dash          []float64
+	currentColor  string
+	fontSize      float64
+	fontFamily    string
+	bold, italic  bool
+	textAnchor    string
+}
+
+// defaultSVGStyle holds the initial values of the SVG properties
+var defaultSVGStyle = svgStyle{
+	fill:          "black",
+	stroke:        "none",
+	fillOpacity:   1,
+	strokeOpacity: 1,
+	opacity:       1,
+	strokeWidth:   1,
+	currentColor:  "black",
+	fontSize:      16,
+}
+
+// with returns the style of a node with the given properties, inheriting
+// from s. Opacity is not inherited in SVG; multiplying it into the
+// children gives the same result unless they overlap. It reports false
+// for nodes that are not displayed.
+func (s svgStyle) with(props map[string]string) (svgStyle, bool, error) {
+	if props["display"] == "none" || props["visibility"] == "hidden" {
+		return s, false, nil
+	}
+
+	var err error
+	opacity := func(value string) float64 {
+		o, ok := svgLength(value, 1)
+		if !ok && err == nil {
+			err = fmt.Errorf("invalid opacity %q", value)
+		}
+		return max(0, min(o, 1))
+	}
+	for key, value := range props {
+		if value == "" || value == "inherit" {
+			continue
+		}
+		switch key {
+		case "fill":
+			s.fill = value
+		case "stroke":
+			s.stroke = value
+		case "color":
+			s.currentColor = value
+		case "fill-opacity":
+			s.fillOpacity = opacity(value)
+		case "stroke-opacity":
+			s.strokeOpacity = opacity(value)
+		case "opacity":
+			s.opacity *= opacity(value)
+		case "stroke-width":
+			if width, ok := svgLength(value, 0); ok {
+				s.strokeWidth = max(0, width)
+			}
+		case "fill-rule":
+			s.fillRule = value
+		case "stroke-linecap":
+			s.lineCap = value
+		case "stroke-linejoin":
+			s.lineJoin = value
+		case "stroke-dasharray":
+			s.dash = nil
+			if value != "none" {
+				if s.dash, err = numberList(strings.ReplaceAll(value, "px", "")); err != nil {
+					return s, false, fmt.Errorf("invalid stroke-dasharray %q", value)
+				}
+			}
+		case "font-size":
+			if size, ok := svgLength(value, s.fontSize); ok && size > 0 {
+				s.fontSize = size
+			}
+		case "font-family":
+			s.fontFamily = value
+		case "font-weight":
+			weight, _ := strconv.Atoi(value)
+			s.bold = value == "bold" || value == "bolder" || weight >= 600
+		case "font-style":
+			s.italic = value == "italic" || value == "oblique"
+		case "text-anchor":
+			s.textAnchor = value
+		}
+	}
+	return s, true, err
+}
+
+// viewBoxMatrix returns the transform scaling a view box onto the placed
+// bounds. Unless the aspect is none, both axes scale alike so the view box
+// fits or, for slice, covers the bounds, placed by the alignments.
+func viewBoxMatrix(view, placed model.Bounds, aspect svgAspect) svgMatrix {
+	sx, sy := placed.Width/view.Width, placed.Height/view.Height
+	if !aspect.none {
+		if aspect.slice {
+			sx = max(sx, sy)
+		} else {
+			sx = min(sx, sy)
+		}
+		sy = sx
+	}
+	return svgMatrix{
+		sx, 0, 0, sy,
+		placed.X + (placed.Width-view.Width*sx)*aspect.alignX - view.X*sx,
+		placed.Y + (placed.Height-view.Height*sy)*aspect.alignY - view.Y*sy,
+	}
+}
+
+// svgMatrix is an affine transform [a b c d e f] taking x, y to
+// a*x + c*y + e, b*x + d*y + f
+type svgMatrix [6]float64
+
+var identityMatrix = svgMatrix{1, 0, 0, 1, 0, 0}
+
+func translateMatrix(x, y float64) svgMatrix {
+	return svgMatrix{1, 0, 0, 1, x, y}
+}
+
+// mul returns the transform applying n first and then m
+func (m svgMatrix) mul(n svgMatrix) svgMatrix {
+	return svgMatrix{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+func (m svgMatrix) apply(p model.Position) model.Position {
+	return model.Position{X: m[0]*p.X + m[2]*p.Y + m[4], Y: m[1]*p.X + m[3]*p.Y + m[5]}
+}
+
+// scale returns the factor lengths such as line widths grow by, exact for
+// transforms that keep the aspect ratio
+func (m svgMatrix) scale() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+// transformPath applies a transform to every point of an outline. Affine
+// transforms keep Bézier curves, so only their points need moving.
+func transformPath(segments []pathSegment, m svgMatrix) []pathSegment {
+	out := make([]pathSegment, len(segments))
+	for i, s := range segments {
+		out[i] = pathSegment{op: s.op, c1: m.apply(s.c1), c2: m.apply(s.c2), to: m.apply(s.to)}
+	}
+	return out
+}
+
+// parseTransform reads an SVG transform list such as
+// "translate(10 5) rotate(45)"
+func parseTransform(s string) (svgMatrix, error) {
+	m := identityMatrix
+	rest := s
+	for {
+		rest = strings.TrimLeft(rest, " \t\r\n,")
+		if rest == "" {
+			return m, nil
+		}
+		name, after, ok := strings.Cut(rest, "(")
+		args, tail, closed := strings.Cut(after, ")")
+		if !ok || !closed {
+			return m, fmt.Errorf("invalid transform %q", s)
+		}
+		rest = tail
+		name = strings.TrimSpace(name)
+		v, err := numberList(args)
+		if err != nil {
+			return m, fmt.Errorf("invalid transform %q: %w", s, err)
+		}
+		arg := func(i int, fallback float64) float64 {
+			if i < len(v) {
+				return v[i]
+			}
+			return fallback
+		}
+
+		var t svgMatrix
+		switch {
+		case name == "matrix" && len(v) == 6:
+			copy(t[:], v)
+		case name == "translate" && len(v) >= 1:
+			t = translateMatrix(v[0], arg(1, 0))
+		case name == "scale" && len(v) >= 1:
+			t = svgMatrix{v[0], 0, 0, arg(1, v[0]), 0, 0}
+		case name == "rotate" && len(v) >= 1:
+			a := v[0] * math.Pi / 180
+			cx, cy := arg(1, 0), arg(2, 0)
+			rotation := svgMatrix{math.Cos(a), math.Sin(a), -math.Sin(a), math.Cos(a), 0, 0}
+			t = translateMatrix(cx, cy).mul(rotation).mul(translateMatrix(-cx, -cy))
+		case name == "skewX" && len(v) == 1:
+			t = svgMatrix{1, 0, math.Tan(v[0] * math.Pi / 180), 1, 0, 0}
+		case name == "skewY" && len(v) == 1:
+			t = svgMatrix{1, math.Tan(v[0] * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			return m, fmt.Errorf("invalid transform %q", s)
+		}
+		m = m.mul(t)
+	}
+}
+
+// numberList reads numbers separated by spaces or commas
+func numberList(s string) ([]float64, error) {
+	p := &pathParser{src: s}
+	var numbers []float64
+	for {
+		p.skipSeparators()
+		if p.done() {
+			return numbers, nil
+		}
+		n, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, n)
+	}
+}
+
+// svgLength converts a length to user units. Percentages are taken of the
+// reference, and absolute units are converted at 96 user units per inch.
+func svgLength(s string, reference float64) (float64, bool) {
+	s = strings.TrimSpace(s)
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"%", reference / 100}, {"px", 1}, {"pt", 96.0 / 72}, {"pc", 16}, {"mm", 96 / 25.4},
+		{"cm", 96 / 2.54}, {"in", 96}, {"em", 16},
+	}
+	scale := 1.0
+	for _, unit := range units {
+		if strings.HasSuffix(s, unit.suffix) {
+			if unit.suffix == "%" && reference == 0 {
+				return 0, false
+			}
+			s, scale = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), unit.scale
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n * scale, true
+}
+
+// svgDrawer draws the nodes of a document
+type svgDrawer struct {
+	ctx    *Context
+	doc    *svgDocument
+	canvas svgCanvas
+	// family is the font for text in families that are not available
+	family string
+	// drawn counts the elements drawn so far against svgMaxNodes
+	drawn int
+}
+
+// length reads a length attribute of a node, with percentages of the view
+// box width or height
+func (d *svgDrawer) length(n *svgNode, name string, vertical bool) float64 {
+	reference := d.doc.viewBox.Width
+	if vertical {
+		reference = d.doc.viewBox.Height
+	}
+	v, _ := svgLength(n.attrs[name], reference)
+	return v
+}
+
+// draw draws a node and its children under the transform m
+func (d *svgDrawer) draw(n *svgNode, m svgMatrix, parent svgStyle, depth int) error {
+	if n.name == "#text" {
+		return nil
+	}
+	if depth > svgMaxDepth {
+		return fmt.Errorf("svg elements nest more than %d deep", svgMaxDepth)
+	}
+	if d.drawn++; d.drawn > svgMaxNodes {
+		return fmt.Errorf("svg draws more than %d elements", svgMaxNodes)
+	}
+	props := d.doc.properties(n)
+	style, visible, err := parent.with(props)
+	if err != nil {
+		return fmt.Errorf("invalid %s element: %w", n.name, err)
+	}
+	if !visible {
+		return nil
+	}
+	if transform := props["transform"]; transform != "" {
+		local, err := parseTransform(transform)
+		if err != nil {
+			return err
+		}
+		m = m.mul(local)
+	}
+
+	switch n.name {
+	case "g", "a", "switch", "svg":
+		if n.name == "svg" {
+			m = m.mul(translateMatrix(d.length(n, "x", false), d.length(n, "y", true)))
+		}
+		return d.drawChildren(n, m, style, depth)
+
+	case "use":
+		target := d.doc.ids[strings.TrimPrefix(n.attrs["href"], "#")]
+		if target == nil {
+			return nil
+		}
+		m = m.mul(translateMatrix(d.length(n, "x", false), d.length(n, "y", true)))
+		if target.name == "symbol" {
+			return d.drawChildren(target, m, style, depth)
+		}
+		return d.draw(target, m, style, depth+1)
+
+	case "path":
+		segments, err := parsePath(n.attrs["d"])
+		if err != nil {
+			return fmt.Errorf("invalid path: %w", err)
+		}
+		return d.paint(segments, m, style)
+
+	case "rect":
+		w, h := d.length(n, "width", false), d.length(n, "height", true)
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+		rx, hasRx := svgLength(n.attrs["rx"], d.doc.viewBox.Width)
+		ry, hasRy := svgLength(n.attrs["ry"], d.doc.viewBox.Height)
+		if !hasRx {
+			rx = ry
+		}
+		if !hasRy {
+			ry = rx
+		}
+		m = m.mul(translateMatrix(d.length(n, "x", false), d.length(n, "y", true)))
+		return d.paint(rectPath(model.Size{Width: w, Height: h}, rx, ry), m, style)
+
+	case "circle", "ellipse":
+		rx, ry := d.length(n, "r", false), d.length(n, "r", true)
+		if n.name == "ellipse" {
+			rx, ry = d.length(n, "rx", false), d.length(n, "ry", true)
+		}
+		if rx <= 0 || ry <= 0 {
+			return nil
+		}
+		m = m.mul(translateMatrix(d.length(n, "cx", false)-rx, d.length(n, "cy", true)-ry))
+		return d.paint(ellipsePath(model.Size{Width: 2 * rx, Height: 2 * ry}), m, style)
+
+	case "line":
+		from := model.Position{X: d.length(n, "x1", false), Y: d.length(n, "y1", true)}
+		to := model.Position{X: d.length(n, "x2", false), Y: d.length(n, "y2", true)}
+		style.fill = "none"
+		return d.paint(polylinePath([]model.Position{from, to}, false), m, style)
+
+	case "polyline", "polygon":
+		v, err := numberList(n.attrs["points"])
+		if err != nil {
+			return fmt.Errorf("invalid points: %w", err)
+		}
+		var points []model.Position
+		for i := 0; i+1 < len(v); i += 2 {
+			points = append(points, model.Position{X: v[i], Y: v[i+1]})
+		}
+		if len(points) < 2 {
+			return nil
+		}
+		return d.paint(polylinePath(points, n.name == "polygon"), m, style)
+
+	case "text":
+		return d.text(n, m, style)
+	}
+	// Definitions, metadata and unsupported elements draw nothing
+	return nil
+}
+
+func (d *svgDrawer) drawChildren(n *svgNode, m svgMatrix, style svgStyle, depth int) error {
+	for _, child := range n.children {
+		if err := d.draw(child, m, style, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// svgPaint is an outline in page coordinates and how it is filled and
+// stroked
+type svgPaint struct {
+	segments []pathSegment
+	// fill and stroke are nil for outlines that are not filled or stroked
+	fill, stroke               *color.Color
+	fillOpacity, strokeOpacity float64
+	evenOdd                    bool
+	// lineWidth and dash are scaled with the transform
+	lineWidth         float64
+	lineCap, lineJoin string
+	dash              []float64
+}
+
+// svgText is a line of text placed on the page
+type svgText struct {
+	content string
+	face    typeface
+	// at is where the line starts, and anchor the point it turns about by
+	// angle degrees clockwise
+	at, anchor model.Position
+	angle      float64
+	fill       color.Color
+	opacity    float64
+}
+
+// svgCanvas receives the outlines and text an SVG document draws
+type svgCanvas interface {
+	paint(p svgPaint)
+	text(t svgText)
+}
+
+// paint resolves the fill and stroke of an outline given in user units and
+// hands it to the canvas
+func (d *svgDrawer) paint(segments []pathSegment, m svgMatrix, style svgStyle) error {
+	p := svgPaint{segments: transformPath(segments, m), evenOdd: style.fillRule == "evenodd"}
+
+	fill, ok, err := d.paintColor(style.fill, style)
+	if err != nil {
+		return fmt.Errorf("invalid fill: %w", err)
+	}
+	if ok {
+		p.fill = &fill
+		p.fillOpacity = fill.A * style.fillOpacity * style.opacity
+	}
+
+	stroke, ok, err := d.paintColor(style.stroke, style)
+	if err != nil {
+		return fmt.Errorf("invalid stroke: %w", err)
+	}
+	if ok && style.strokeWidth > 0 {
+		scale := m.scale()
+		p.stroke = &stroke
+		p.strokeOpacity = stroke.A * style.strokeOpacity * style.opacity
+		p.lineWidth = style.strokeWidth * scale
+		p.lineCap, p.lineJoin = "butt", "miter"
+		if style.lineCap == "round" || style.lineCap == "square" {
+			p.lineCap = style.lineCap
+		}
+		if style.lineJoin == "round" || style.lineJoin == "bevel" {
+			p.lineJoin = style.lineJoin
+		}
+		for _, length := range style.dash {
+			if length > 0 {
+				// All-zero patterns draw a solid line
+				p.dash = make([]float64, len(style.dash))
+				for i, l := range style.dash {
+					p.dash[i] = max(0, l) * scale
+				}
+				break
+			}
+		}
+	}
+
+	if p.fill != nil || p.stroke != nil {
+		d.canvas.paint(p)
+	}
+	return nil
+}
+
+// svgPage draws SVG outlines and text on the page of a context
+type svgPage struct {
+	ctx *Context
+}
+
+func (page svgPage) paint(p svgPaint) {
+	pdf := page.ctx.PDF
+	if fill := p.fill; fill != nil {
+		page.withOpacity(p.fillOpacity, func() {
+			pdf.SetFillColor(fill.R, fill.G, fill.B)
+			tracePath(pdf, p.segments, model.Position{})
+			if p.evenOdd {
+				pdf.DrawPath("f*")
+			} else {
+				pdf.DrawPath("f")
+			}
+		})
+	}
+
+	if stroke := p.stroke; stroke != nil {
+		pdf.SetLineWidth(p.lineWidth)
+		pdf.SetDrawColor(stroke.R, stroke.G, stroke.B)
+		pdf.SetLineCapStyle(p.lineCap)
+		pdf.SetLineJoinStyle(p.lineJoin)
+		pdf.SetDashPattern(p.dash, 0)
+		page.withOpacity(p.strokeOpacity, func() {
+			tracePath(pdf, p.segments, model.Position{})
+			pdf.DrawPath("D")
+		})
+		pdf.SetLineJoinStyle("miter")
+	}
+}
+
+func (page svgPage) text(t svgText) {
+	pdf := page.ctx.PDF
+	if math.Abs(t.angle) > 1e-9 {
+		pdf.TransformBegin()
+		// gofpdf turns counter-clockwise
+		pdf.TransformRotate(-t.angle, t.anchor.X, t.anchor.Y)
+		defer pdf.TransformEnd()
+	}
+	pdf.SetTextColor(t.fill.R, t.fill.G, t.fill.B)
+	page.withOpacity(t.opacity, func() {
+		t.face.text(t.at.X, t.at.Y, t.content)
+	})
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// withOpacity runs draw at the given opacity
+func (page svgPage) withOpacity(opacity float64, draw func()) {
+	if opacity >= 1 {
+		draw()
+		return
+	}
+	page.ctx.PDF.SetAlpha(opacity, "Normal")
+	draw()
+	page.ctx.PDF.SetAlpha(1, "Normal")
+}
+
+// paintColor resolves a fill or stroke value to a color. It reports false
+// for "none" and for references to paint servers that cannot be found.
+// Gradients are painted in their first stop color.
+func (d *svgDrawer) paintColor(value string, style svgStyle) (color.Color, bool, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "url(") {
+		ref, fallback, _ := strings.Cut(value[4:], ")")
+		ref = strings.Trim(strings.TrimSpace(ref), `"'`)
+		if stop, ok := d.gradientStop(strings.TrimPrefix(ref, "#"), 0); ok {
+			return d.paintColor(stop, style)
+		}
+		value = strings.TrimSpace(fallback)
+	}
+	switch strings.ToLower(value) {
+	case "", "none", "transparent":
+		return color.Color{}, false, nil
+	case "currentcolor":
+		value = style.currentColor
+	}
+	c, err := color.Parse(value)
+	if err != nil {
+		return c, false, err
+	}
+	return c, !c.Transparent(), nil
+}
+
+// gradientStop returns the color of the first stop of a gradient, following
+// the gradients it takes its stops from
+func (d *svgDrawer) gradientStop(id string, depth int) (string, bool) {
+	gradient := d.doc.ids[id]
+	if gradient == nil || depth > svgMaxDepth {
+		return "", false
+	}
+	for _, child := range gradient.children {
+		if child.name == "stop" {
+			props := d.doc.properties(child)
+			stop := props["stop-color"]
+			if stop == "" {
+				stop = "black"
+			}
+			if o, ok := svgLength(props["stop-opacity"], 1); ok && o < 1 {
+				if c, err := color.Parse(stop); err == nil {
+					return fmt.Sprintf("rgba(%d, %d, %d, %g)", c.R, c.G, c.B, c.A*max(0, o)), true
+				}
+			}
+			return stop, true
+		}
+	}
+	return d.gradientStop(strings.TrimPrefix(gradient.attrs["href"], "#"), depth+1)
+}
+
+// text places a text element on one line from its first x and y position,
+// with the text of its tspan children
+func (d *svgDrawer) text(n *svgNode, m svgMatrix, style svgStyle) error {
+	content := strings.Join(strings.Fields(nodeText(n)), " ")
+	if content == "" {
+		return nil
+	}
+	fill, ok, err := d.paintColor(style.fill, style)
+	if err != nil {
+		return fmt.Errorf("invalid fill: %w", err)
+	}
+	if !ok {
+		return nil
+	}
+	xs, _ := numberList(n.attrs["x"])
+	ys, _ := numberList(n.attrs["y"])
+	var at model.Position
+	if len(xs) > 0 {
+		at.X = xs[0]
+	}
+	if len(ys) > 0 {
+		at.Y = ys[0]
+	}
+
+	variant := fonts.Regular
+	switch {
+	case style.bold && style.italic:
+		variant = fonts.BoldItalic
+	case style.bold:
+		variant = fonts.Bold
+	case style.italic:
+		variant = fonts.Italic
+	}
+	size := d.ctx.PDF.UnitToPointConvert(style.fontSize * m.scale())
+	t := svgText{
+		content: content,
+		face:    newTypeface(d.ctx, d.fontFamily(style.fontFamily, variant), variant, size),
+		anchor:  m.apply(at),
+		// Text turns with the transform about its anchor
+		angle:   math.Atan2(m[1], m[0]) * 180 / math.Pi,
+		fill:    fill,
+		opacity: fill.A * style.fillOpacity * style.opacity,
+	}
+	t.at = t.anchor
+	switch style.textAnchor {
+	case "middle":
+		t.at.X -= t.face.width(content) / 2
+	case "end":
+		t.at.X -= t.face.width(content)
+	}
+	d.canvas.text(t)
+	return nil
+}
+
+// fontFamily picks the first family of a font-family list that is
+// registered or a core font, or the element's family
+func (d *svgDrawer) fontFamily(list string, variant fonts.Style) string {
+	for _, family := range strings.Split(list, ",") {
+		family = strings.Trim(strings.TrimSpace(family), `"'`)
+		if _, ok := d.ctx.Fonts.Lookup(family, variant); ok {
+			return family
+		}
+		if core, ok := svgCoreFonts[strings.ToLower(family)]; ok {
+			return core
+		}
+	}
+	return d.family
+}
@@ -0,0 +1,468 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/assets"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/fonts"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/internal/color"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+func TestParseSVG_Size(t *testing.T) {
+	tests := []struct {
+		name        string
+		svg         string
+		wantSize    model.Size
+		wantViewBox model.Bounds
+		wantStretch bool
+		wantErr     bool
+	}{
+		{
+			name:        "pixels and view box",
+			svg:         `<svg xmlns="http://www.w3.org/2000/svg" width="96" height="48px" viewBox="0 0 10 5"/>`,
+			wantSize:    model.Size{Width: 25.4, Height: 12.7},
+			wantViewBox: model.Bounds{Size: model.Size{Width: 10, Height: 5}},
+		},
+		{
+			name:        "view box only",
+			svg:         `<svg viewBox="-5 -5 192 96"/>`,
+			wantSize:    model.Size{Width: 50.8, Height: 25.4},
+			wantViewBox: model.Bounds{Position: model.Position{X: -5, Y: -5}, Size: model.Size{Width: 192, Height: 96}},
+		},
+		{
+			name:        "width from the aspect ratio",
+			svg:         `<svg height="1in" viewBox="0 0 20 10" preserveAspectRatio="none"/>`,
+			wantSize:    model.Size{Width: 50.8, Height: 25.4},
+			wantViewBox: model.Bounds{Size: model.Size{Width: 20, Height: 10}},
+			wantStretch: true,
+		},
+		{
+			name:        "default size",
+			svg:         `<svg/>`,
+			wantSize:    model.Size{Width: 300 * svgPixel, Height: 150 * svgPixel},
+			wantViewBox: model.Bounds{Size: model.Size{Width: 300, Height: 150}},
+		},
+		{name: "not svg", svg: `<html><body/></html>`, wantErr: true},
+		{name: "not xml", svg: `logo.png`, wantErr: true},
+	}
+
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseSVG([]byte(tt.svg))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseSVG() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSVG() error = %v", err)
+			}
+			if !near(doc.size.Width, tt.wantSize.Width) || !near(doc.size.Height, tt.wantSize.Height) {
+				t.Errorf("size = %+v, want %+v", doc.size, tt.wantSize)
+			}
+			if doc.viewBox != tt.wantViewBox {
+				t.Errorf("view box = %+v, want %+v", doc.viewBox, tt.wantViewBox)
+			}
+			if doc.aspect.none != tt.wantStretch {
+				t.Errorf("stretch = %v, want %v", doc.aspect.none, tt.wantStretch)
+			}
+		})
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform string
+		point     model.Position
+		want      model.Position
+		wantErr   bool
+	}{
+		{name: "translate", transform: "translate(10)", point: model.Position{X: 1, Y: 1}, want: model.Position{X: 11, Y: 1}},
+		{name: "scale", transform: "scale(2, 3)", point: model.Position{X: 1, Y: 1}, want: model.Position{X: 2, Y: 3}},
+		{name: "rotate about a point", transform: "rotate(90 5 5)", point: model.Position{X: 10, Y: 5}, want: model.Position{X: 5, Y: 10}},
+		{name: "matrix", transform: "matrix(1 0 0 1 3 4)", point: model.Position{}, want: model.Position{X: 3, Y: 4}},
+		{name: "list applies right to left", transform: "translate(10, 0) scale(2)", point: model.Position{X: 1, Y: 1}, want: model.Position{X: 12, Y: 2}},
+		{name: "skew", transform: "skewX(45)", point: model.Position{X: 0, Y: 2}, want: model.Position{X: 2, Y: 2}},
+		{name: "unknown", transform: "perspective(2)", wantErr: true},
+		{name: "unclosed", transform: "translate(1 2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := parseTransform(tt.transform)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseTransform() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTransform() error = %v", err)
+			}
+			got := m.apply(tt.point)
+			if math.Abs(got.X-tt.want.X) > 1e-9 || math.Abs(got.Y-tt.want.Y) > 1e-9 {
+				t.Errorf("transformed point = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func svgElement(content, metadata string) model.Element {
+	element := model.Element{
+		ID:      "logo",
+		Type:    model.ElementTypeSVG,
+		Bounds:  model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 40, Height: 20}},
+		Content: content,
+	}
+	if metadata != "" {
+		element.Metadata = json.RawMessage(metadata)
+	}
+	return element
+}
+
+func TestParseAspect(t *testing.T) {
+	tests := []struct {
+		value string
+		want  svgAspect
+	}{
+		{value: "", want: svgAspect{alignX: 0.5, alignY: 0.5}},
+		{value: "none", want: svgAspect{none: true}},
+		{value: "xMinYMax", want: svgAspect{alignX: 0, alignY: 1}},
+		{value: "defer xMaxYMin slice", want: svgAspect{alignX: 1, alignY: 0, slice: true}},
+		{value: "xMidYMid meet", want: svgAspect{alignX: 0.5, alignY: 0.5}},
+		{value: "sideways", want: svgAspect{alignX: 0.5, alignY: 0.5}},
+	}
+	for _, tt := range tests {
+		if got := parseAspect(tt.value); got != tt.want {
+			t.Errorf("parseAspect(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestViewBoxMatrix(t *testing.T) {
+	pt := func(x, y float64) model.Position { return model.Position{X: x, Y: y} }
+	mid := svgAspect{alignX: 0.5, alignY: 0.5}
+
+	tests := []struct {
+		name   string
+		view   model.Bounds
+		placed model.Bounds
+		aspect svgAspect
+		// corners are where the top-left and bottom-right corners of the
+		// view box go
+		corners [2]model.Position
+	}{
+		{
+			name:    "same ratio",
+			view:    model.Bounds{Position: pt(-5, -5), Size: model.Size{Width: 10, Height: 10}},
+			placed:  model.Bounds{Position: pt(20, 10), Size: model.Size{Width: 20, Height: 20}},
+			aspect:  mid,
+			corners: [2]model.Position{pt(20, 10), pt(40, 30)},
+		},
+		{
+			// A square view box in a 2:1 viewport is centered, not squashed
+			name:    "meet centers",
+			view:    model.Bounds{Size: model.Size{Width: 100, Height: 100}},
+			placed:  model.Bounds{Position: pt(10, 10), Size: model.Size{Width: 40, Height: 20}},
+			aspect:  mid,
+			corners: [2]model.Position{pt(20, 10), pt(40, 30)},
+		},
+		{
+			name:    "meet at the start",
+			view:    model.Bounds{Size: model.Size{Width: 100, Height: 100}},
+			placed:  model.Bounds{Position: pt(10, 10), Size: model.Size{Width: 40, Height: 20}},
+			aspect:  svgAspect{alignX: 0, alignY: 0},
+			corners: [2]model.Position{pt(10, 10), pt(30, 30)},
+		},
+		{
+			name:    "meet at the end",
+			view:    model.Bounds{Size: model.Size{Width: 100, Height: 100}},
+			placed:  model.Bounds{Position: pt(10, 10), Size: model.Size{Width: 40, Height: 20}},
+			aspect:  svgAspect{alignX: 1, alignY: 1},
+			corners: [2]model.Position{pt(30, 10), pt(50, 30)},
+		},
+		{
+			// Slice covers the viewport, overflowing it vertically
+			name:    "slice",
+			view:    model.Bounds{Size: model.Size{Width: 100, Height: 100}},
+			placed:  model.Bounds{Position: pt(10, 10), Size: model.Size{Width: 40, Height: 20}},
+			aspect:  svgAspect{alignX: 0.5, alignY: 0, slice: true},
+			corners: [2]model.Position{pt(10, 10), pt(50, 50)},
+		},
+		{
+			name:    "none stretches",
+			view:    model.Bounds{Size: model.Size{Width: 100, Height: 100}},
+			placed:  model.Bounds{Position: pt(10, 10), Size: model.Size{Width: 40, Height: 20}},
+			aspect:  svgAspect{none: true},
+			corners: [2]model.Position{pt(10, 10), pt(50, 30)},
+		},
+	}
+
+	near := func(p, q model.Position) bool { return math.Abs(p.X-q.X) < 1e-9 && math.Abs(p.Y-q.Y) < 1e-9 }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := viewBoxMatrix(tt.view, tt.placed, tt.aspect)
+			start := m.apply(tt.view.Position)
+			end := m.apply(pt(tt.view.X+tt.view.Width, tt.view.Y+tt.view.Height))
+			if !near(start, tt.corners[0]) || !near(end, tt.corners[1]) {
+				t.Errorf("view box goes from %+v to %+v, want %+v to %+v", start, end, tt.corners[0], tt.corners[1])
+			}
+		})
+	}
+}
+
+// svgRecorder keeps what an SVG document draws
+type svgRecorder struct {
+	paints []svgPaint
+	texts  []svgText
+}
+
+func (r *svgRecorder) paint(p svgPaint) { r.paints = append(r.paints, p) }
+func (r *svgRecorder) text(t svgText)   { r.texts = append(r.texts, t) }
+
+// recordSVG draws a document in user units, which are millimetres here
+func recordSVG(t *testing.T, svg string) (*svgRecorder, error) {
+	t.Helper()
+	doc, err := parseSVG([]byte(svg))
+	if err != nil {
+		t.Fatalf("parseSVG() error = %v", err)
+	}
+	rec := &svgRecorder{}
+	d := &svgDrawer{ctx: newTestContext(), doc: doc, canvas: rec, family: "Times"}
+	for _, child := range doc.root.children {
+		if err := d.draw(child, identityMatrix, defaultSVGStyle, 0); err != nil {
+			return rec, err
+		}
+	}
+	return rec, nil
+}
+
+func TestSVGDrawer_Paint(t *testing.T) {
+	pt := func(x, y float64) model.Position { return model.Position{X: x, Y: y} }
+	red, green, blue := color.Color{R: 255, A: 1}, color.Color{G: 255, A: 1}, color.Color{B: 255, A: 1}
+	yellow := color.Color{R: 255, G: 255, A: 1}
+
+	tests := []struct {
+		name    string
+		svg     string
+		want    []svgPaint
+		wantErr string
+	}{
+		{
+			name: "rect",
+			svg:  `<svg><rect x="1" y="2" width="3" height="4" fill="red"/></svg>`,
+			want: []svgPaint{{
+				segments:    polylinePath([]model.Position{pt(1, 2), pt(4, 2), pt(4, 6), pt(1, 6)}, true),
+				fill:        &red,
+				fillOpacity: 1,
+			}},
+		},
+		{
+			// The width and dashes grow with the scale
+			name: "stroke",
+			svg: `<svg><path transform="scale(2)" d="M0 0 L10 10" fill="none" stroke="#0000ff" stroke-width="0.5"
+				stroke-linecap="round" stroke-linejoin="arcs" stroke-dasharray="1 1"/></svg>`,
+			want: []svgPaint{{
+				segments:      polylinePath([]model.Position{pt(0, 0), pt(20, 20)}, false),
+				stroke:        &blue,
+				strokeOpacity: 1,
+				lineWidth:     1,
+				lineCap:       "round",
+				lineJoin:      "miter",
+				dash:          []float64{2, 2},
+			}},
+		},
+		{
+			name: "all-zero dashes are solid",
+			svg:  `<svg><line x2="5" stroke="red" stroke-dasharray="0 0"/></svg>`,
+			want: []svgPaint{{
+				segments:      polylinePath([]model.Position{pt(0, 0), pt(5, 0)}, false),
+				stroke:        &red,
+				strokeOpacity: 1,
+				lineWidth:     1,
+				lineCap:       "butt",
+				lineJoin:      "miter",
+			}},
+		},
+		{
+			name: "groups, transforms and style sheets",
+			svg: `<svg><style>.brand { fill: #00ff00 } #hidden { display: none }</style>
+				<g transform="translate(5 0)" opacity="0.5"><circle class="brand" cx="2" cy="2" r="2"/></g>
+				<rect id="hidden" width="1" height="1" fill="#123456"/></svg>`,
+			want: []svgPaint{{
+				segments:    transformPath(ellipsePath(model.Size{Width: 4, Height: 4}), translateMatrix(5, 0)),
+				fill:        &green,
+				fillOpacity: 0.5,
+			}},
+		},
+		{
+			name: "inline style and even-odd fill",
+			svg:  `<svg><path style="fill: rgb(0, 0, 255); fill-rule: evenodd" d="M0 0h10v10H0z"/></svg>`,
+			want: []svgPaint{{
+				segments:    polylinePath([]model.Position{pt(0, 0), pt(10, 0), pt(10, 10), pt(0, 10)}, true),
+				fill:        &blue,
+				fillOpacity: 1,
+				evenOdd:     true,
+			}},
+		},
+		{
+			name: "gradients use their first stop",
+			svg: `<svg><defs><linearGradient id="fade"><stop offset="0" stop-color="#ff0000"/><stop offset="1" stop-color="#0000ff"/></linearGradient>
+				<linearGradient id="copy" href="#fade"/></defs><rect width="1" height="1" fill="url(#copy)"/></svg>`,
+			want: []svgPaint{{
+				segments:    polylinePath([]model.Position{pt(0, 0), pt(1, 0), pt(1, 1), pt(0, 1)}, true),
+				fill:        &red,
+				fillOpacity: 1,
+			}},
+		},
+		{
+			name: "use and symbols",
+			svg: `<svg xmlns:xlink="http://www.w3.org/1999/xlink"><defs><symbol id="dot"><polygon points="0,0 2,0 1,2" fill="#ffff00"/></symbol></defs>
+				<use xlink:href="#dot" x="4" y="4"/></svg>`,
+			want: []svgPaint{{
+				segments:    polylinePath([]model.Position{pt(4, 4), pt(6, 4), pt(5, 6)}, true),
+				fill:        &yellow,
+				fillOpacity: 1,
+			}},
+		},
+		{
+			name: "nothing to paint",
+			svg:  `<svg><rect width="0" height="5"/><rect width="5" height="5" fill="none"/><circle r="1" visibility="hidden"/></svg>`,
+		},
+		{name: "invalid path", svg: `<svg><path d="M0 0 L"/></svg>`, wantErr: "invalid path"},
+		{name: "invalid fill", svg: `<svg><rect width="1" height="1" fill="shiny"/></svg>`, wantErr: "invalid fill"},
+		{name: "invalid stroke", svg: `<svg><rect width="1" height="1" stroke="shiny"/></svg>`, wantErr: "invalid stroke"},
+		{name: "invalid transform", svg: `<svg><g transform="spin(1)"/></svg>`, wantErr: "invalid transform"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := recordSVG(t, tt.svg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("draw() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("draw() error = %v", err)
+			}
+			if len(rec.paints) != len(tt.want) {
+				t.Fatalf("painted %d outlines, want %d", len(rec.paints), len(tt.want))
+			}
+			for i, got := range rec.paints {
+				want := tt.want[i]
+				if len(got.segments) != len(want.segments) {
+					t.Fatalf("outline %d = %+v, want %+v", i, got.segments, want.segments)
+				}
+				for j := range got.segments {
+					if !closeSegment(got.segments[j], want.segments[j]) {
+						t.Errorf("outline %d segment %d = %+v, want %+v", i, j, got.segments[j], want.segments[j])
+					}
+				}
+				got.segments, want.segments = nil, nil
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("paint %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSVGDrawer_Text(t *testing.T) {
+	rec, err := recordSVG(t, `<svg><text x="50" y="25" font-family="Nope, sans-serif" font-weight="bold" text-anchor="middle" fill="#333">ACME <tspan>Corp</tspan></text>
+		<text x="10" y="10" font-family="Nope" font-size="8" transform="rotate(90 10 10)" opacity="0.5">Side</text></svg>`)
+	if err != nil {
+		t.Fatalf("draw() error = %v", err)
+	}
+	if len(rec.texts) != 2 {
+		t.Fatalf("drew %d texts, want 2", len(rec.texts))
+	}
+
+	title := rec.texts[0]
+	if title.content != "ACME Corp" {
+		t.Errorf("content = %q, want %q", title.content, "ACME Corp")
+	}
+	if title.face.family != "Helvetica" || title.face.style != fonts.Bold {
+		t.Errorf("font = %s %v, want bold Helvetica", title.face.family, title.face.style)
+	}
+	// 16 user units of a millimetre each
+	if want := 16 * 72 / 25.4; math.Abs(title.face.size-want) > 1e-9 {
+		t.Errorf("font size = %v, want %v", title.face.size, want)
+	}
+	if want := 50 - title.face.width("ACME Corp")/2; math.Abs(title.at.X-want) > 1e-9 || title.at.Y != 25 {
+		t.Errorf("text starts at %+v, want it centered on 50, 25", title.at)
+	}
+	if title.fill != (color.Color{R: 51, G: 51, B: 51, A: 1}) || title.opacity != 1 || title.angle != 0 {
+		t.Errorf("text paint = %+v at %v opacity turned %v degrees", title.fill, title.opacity, title.angle)
+	}
+
+	// Unknown families take the element's, and text turns about its anchor
+	side := rec.texts[1]
+	if side.face.family != "Times" {
+		t.Errorf("font = %s, want the element's Times", side.face.family)
+	}
+	if math.Abs(side.angle-90) > 1e-9 || math.Abs(side.anchor.X-10) > 1e-9 || math.Abs(side.anchor.Y-10) > 1e-9 || side.opacity != 0.5 {
+		t.Errorf("text turned %v degrees about %+v with opacity %v, want 90 about 10, 10 with 0.5", side.angle, side.anchor, side.opacity)
+	}
+}
+
+func TestSVGRenderer(t *testing.T) {
+	square := `<svg viewBox="0 0 10 10"><rect width="10" height="10" fill="red"/></svg>`
+
+	tests := []struct {
+		name     string
+		svg      string
+		metadata string
+		assets   assets.Resolver
+		wantErr  string
+	}{
+		{name: "markup", svg: square, metadata: `{"rotation": 45, "opacity": 0.25, "fit": "cover"}`},
+		{name: "asset source", svg: "brand/logo.svg", assets: assets.Map{"brand/logo.svg": []byte(square)}},
+		{name: "invalid path", svg: `<svg><path d="M0 0 L"/></svg>`, wantErr: "invalid path"},
+		{name: "missing asset", svg: "logo.svg", assets: assets.Map{}, wantErr: "failed to load svg"},
+		{name: "invalid svg", svg: "<html/>", wantErr: "invalid svg"},
+		{name: "invalid opacity", svg: square, metadata: `{"opacity": 3}`, wantErr: "must be between 0 and 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext()
+			ctx.Assets = tt.assets
+			err := (&SVGRenderer{}).Render(ctx, svgElement(tt.svg, tt.metadata))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Render() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Render() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSVGRenderer_ElementBudget(t *testing.T) {
+	// Each level draws the one below twice, 2^20 elements in all
+	var sb strings.Builder
+	sb.WriteString(`<svg viewBox="0 0 10 10"><defs><rect id="l0" width="1" height="1"/>`)
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&sb, `<g id="l%d"><use href="#l%d"/><use href="#l%d" x="0.1"/></g>`, i, i-1, i-1)
+	}
+	sb.WriteString(`</defs><use href="#l20"/></svg>`)
+
+	err := (&SVGRenderer{}).Render(newTestContext(), svgElement(sb.String(), ""))
+	if err == nil || !strings.Contains(err.Error(), "more than 20000 elements") {
+		t.Errorf("Render() error = %v, want the element budget to be exceeded", err)
+	}
+}
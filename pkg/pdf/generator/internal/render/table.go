@@ -0,0 +1,882 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/generator/internal/binding"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/internal/color"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// Table defaults, smaller text on taller rows than text elements
+const (
+	tableFontSize   = 10.0
+	tableLineHeight = 2.0
+)
+
+// cellPadding is the default space between cell text and the column lines
+var cellPadding = model.Padding{Left: 2, Right: 2}
+
+// TableRenderer handles rendering of table elements. Content is a list of
+// rows, each a list of cells or a data object read through the column keys,
+// and the metadata configures the columns, header rows and striping.
+type TableRenderer struct{}
+
+func (r *TableRenderer) Render(ctx *Context, element model.Element) error {
+	table, err := layoutTable(ctx, element, insetPadding(element.Bounds, element.Style).Width)
+	if err != nil {
+		return err
+	}
+
+	bounds, err := drawBox(ctx, element)
+	if err != nil {
+		return err
+	}
+	if err := table.draw(ctx, bounds.X, bounds.Y); err != nil {
+		return fmt.Errorf("failed to draw table %s: %w", element.ID, err)
+	}
+	return nil
+}
+
+// Measure returns the height of all rows, including the padding
+func (r *TableRenderer) Measure(ctx *Context, element model.Element) (float64, error) {
+	bounds := insetPadding(element.Bounds, element.Style)
+	table, err := layoutTable(ctx, element, bounds.Width)
+	if err != nil {
+		return 0, err
+	}
+	return table.height() + element.Bounds.Height - bounds.Height, nil
+}
+
+// Split breaks a table between rows so that the first part fits the
+// height. The rest repeats the header rows, and both parts get the break
+// rows and striping of their place in the whole table.
+func (r *TableRenderer) Split(ctx *Context, element model.Element, height, pageHeight float64) ([]model.Element, error) {
+	var opts model.TableOptions
+	if err := element.DecodeMetadata(&opts); err != nil {
+		return nil, err
+	}
+	rows, ok := element.Content.([]interface{})
+	if !ok || opts.KeepTogether && element.Bounds.Height <= pageHeight {
+		return nil, nil
+	}
+	split := tablePartOf(element)
+
+	// Lay the table out as a first part to find the rows that fit
+	first := split
+	first.continues = true
+	head := element
+	head.SetLayoutState(first)
+	bounds := insetPadding(element.Bounds, element.Style)
+	table, err := layoutTable(ctx, head, bounds.Width)
+	if err != nil {
+		return nil, err
+	}
+
+	used := element.Bounds.Height - bounds.Height
+	var body []tableRow
+	for _, row := range table.rows {
+		if row.kind == bodyRow {
+			body = append(body, row)
+		} else {
+			used += row.height
+		}
+	}
+	n := 0
+	for n < len(body) && used+body[n].height <= height {
+		used += body[n].height
+		n++
+	}
+	for n > 0 && body[n-1].keepWithNext {
+		n--
+	}
+	if n == 0 || n == len(body) {
+		return nil, nil
+	}
+
+	headers := min(opts.HeaderRows, len(rows))
+	tail := element
+	tail.SetLayoutState(tablePart{
+		offset:    split.offset + n,
+		totals:    sumTotals(split.totals, (&tableLayout{rows: body[:n]}).totals()),
+		continued: true,
+		continues: split.continues,
+	})
+	head.Content = append(append([]interface{}(nil), rows[:headers]...), rows[headers:headers+n]...)
+	tail.Content = append(append([]interface{}(nil), rows[:headers]...), rows[headers+n:]...)
+
+	parts := []model.Element{head, tail}
+	for i := range parts {
+		height, err := r.Measure(ctx, parts[i])
+		if err != nil {
+			return nil, err
+		}
+		parts[i].Bounds.Height = height
+	}
+	return parts, nil
+}
+
+// tablePart is the layout state of one part of a table broken across pages
+type tablePart struct {
+	// offset is the number of body rows on earlier pages
+	offset int
+	// totals are the column totals of the body rows on earlier pages
+	totals []float64
+	// continued marks a part that follows a break and continues a part
+	// that is followed by one
+	continued bool
+	continues bool
+}
+
+// tablePartOf returns the part of a split table an element holds, which is
+// the zero part for a table that was not split
+func tablePartOf(element model.Element) tablePart {
+	part, _ := element.LayoutState().(tablePart)
+	return part
+}
+
+// tableStyle returns a copy of style with the table defaults filled in
+func tableStyle(style *model.Style) model.Style {
+	s := textStyle(style)
+	if style == nil || style.FontSize <= 0 {
+		s.FontSize = tableFontSize
+	}
+	if style == nil || style.LineHeight <= 0 {
+		s.LineHeight = tableLineHeight
+	}
+	if s.Alignment == "" {
+		s.Alignment = model.AlignCenter
+	}
+	if s.VerticalAlignment == "" {
+		s.VerticalAlignment = model.AlignMiddle
+	}
+	return s
+}
+
+// overlayStyle returns base with the fields set in override replacing its own
+func overlayStyle(base model.Style, override *model.Style) model.Style {
+	if override == nil {
+		return base
+	}
+	b, o := reflect.ValueOf(&base).Elem(), reflect.ValueOf(override).Elem()
+	for i := 0; i < o.NumField(); i++ {
+		if field := o.Field(i); !field.IsZero() {
+			b.Field(i).Set(field)
+		}
+	}
+	return base
+}
+
+// tableCell is one cell with its final text and style
+type tableCell struct {
+	// value is the cell value before formatting, summed for subtotals
+	value interface{}
+	text  string
+	style model.Style
+	face  typeface
+	// column is the first grid column of the cell, after the columns taken
+	// by cells spanning down from the rows above
+	column  int
+	colSpan int
+	rowSpan int
+	// block is the text wrapped to the width of the spanned columns
+	block textBlock
+}
+
+// padding returns the space around the cell text
+func (c tableCell) padding() model.Padding {
+	if c.style.Padding != nil {
+		return *c.style.Padding
+	}
+	return cellPadding
+}
+
+// height returns the height the wrapped cell text needs, at least one line
+func (c tableCell) height() float64 {
+	padding := c.padding()
+	lines := max(1, len(c.block.lines))
+	return padding.Top + float64(lines)*c.face.height()*c.style.LineHeight + padding.Bottom
+}
+
+// width returns the width the cell text needs without wrapping, that of
+// its longest line
+func (c tableCell) width() float64 {
+	widest := 0.0
+	for _, line := range strings.Split(c.text, "\n") {
+		widest = max(widest, c.face.width(line))
+	}
+	padding := c.padding()
+	return padding.Left + widest + padding.Right
+}
+
+// rowKind tells the rows of a table apart
+type rowKind int
+
+const (
+	headerRow rowKind = iota
+	bodyRow
+	// breakRow is a subtotal or carried forward row added at a page break
+	breakRow
+)
+
+type tableRow struct {
+	cells  []tableCell
+	height float64
+	kind   rowKind
+	// keepWithNext prevents a page break after the row
+	keepWithNext bool
+}
+
+// tableLayout is a table element resolved into styled cells and sized columns
+type tableLayout struct {
+	widths []float64
+	rows   []tableRow
+}
+
+func (t *tableLayout) height() float64 {
+	height := 0.0
+	for _, row := range t.rows {
+		height += row.height
+	}
+	return height
+}
+
+// totals returns the column totals of the body rows; cells that are not
+// numbers count as zero
+func (t *tableLayout) totals() []float64 {
+	var totals []float64
+	for _, row := range t.rows {
+		if row.kind != bodyRow {
+			continue
+		}
+		for _, cell := range row.cells {
+			for len(totals) <= cell.column {
+				totals = append(totals, 0)
+			}
+			if n, ok := binding.ToNumber(cell.value); ok {
+				totals[cell.column] += n
+			}
+		}
+	}
+	return totals
+}
+
+// layoutTable reads the rows of a table element and sizes its columns to
+// the given width. Parts of a split table get their break rows here.
+func layoutTable(ctx *Context, element model.Element, width float64) (*tableLayout, error) {
+	reader, err := newTableReader(ctx, element)
+	if err != nil {
+		return nil, err
+	}
+	// Content bound to a missing array has no rows
+	rows, ok := element.Content.([]interface{})
+	if !ok && element.Content != nil {
+		return nil, fmt.Errorf("invalid content type for table element: expected []interface{}, got %T", element.Content)
+	}
+	opts := reader.opts
+	split := tablePartOf(element)
+
+	table := &tableLayout{}
+	add := func(raw interface{}, name string, kind rowKind, striped bool) error {
+		row, err := reader.row(raw, name, kind, striped)
+		if err != nil {
+			return err
+		}
+		table.rows = append(table.rows, row)
+		return nil
+	}
+	// Row spans end with their section: the header, each break row or the body
+	start := 0
+	section := func() {
+		spanRows(table.rows[start:])
+		start = len(table.rows)
+		reader.occupied = nil
+	}
+
+	if labels := columnHeaders(opts.Columns); labels != nil {
+		if err := add(labels, "column headers", headerRow, false); err != nil {
+			return nil, err
+		}
+	}
+	headers := min(opts.HeaderRows, len(rows))
+	for i := 0; i < headers; i++ {
+		if err := add(rows[i], fmt.Sprintf("row %d", i), headerRow, false); err != nil {
+			return nil, err
+		}
+	}
+	section()
+	if split.continued && len(opts.CarriedForward) > 0 {
+		if err := add(breakCells(opts.CarriedForward, split.totals, nil), "carried forward row", breakRow, false); err != nil {
+			return nil, err
+		}
+		section()
+	}
+	for i := headers; i < len(rows); i++ {
+		striped := opts.Stripe != "" && (split.offset+i-headers)%2 == 1
+		if err := add(rows[i], fmt.Sprintf("row %d", i), bodyRow, striped); err != nil {
+			return nil, err
+		}
+	}
+	section()
+	if split.continues && len(opts.Subtotal) > 0 {
+		page := table.totals()
+		if err := add(breakCells(opts.Subtotal, sumTotals(split.totals, page), page), "subtotal row", breakRow, false); err != nil {
+			return nil, err
+		}
+		section()
+	}
+
+	columns := 0
+	for _, row := range table.rows {
+		for _, cell := range row.cells {
+			columns = max(columns, cell.column+cell.colSpan)
+		}
+	}
+	widths, err := columnWidths(opts.Columns, table.rows, columns, width)
+	if err != nil {
+		return nil, fmt.Errorf("invalid columns in table %s: %w", element.ID, err)
+	}
+	table.widths = widths
+	table.wrap(ctx)
+	return table, nil
+}
+
+// spanRows limits the row spans of cells to the given rows and keeps the
+// rows a span covers on one page
+func spanRows(rows []tableRow) {
+	for i := range rows {
+		for j := range rows[i].cells {
+			cell := &rows[i].cells[j]
+			cell.rowSpan = min(cell.rowSpan, len(rows)-i)
+			for k := i; k < i+cell.rowSpan-1; k++ {
+				rows[k].keepWithNext = true
+			}
+		}
+	}
+}
+
+// wrap breaks the cell text to the width of its columns and sizes each row
+// to its tallest cell. Cells spanning several rows share out the height
+// they need beyond that of their rows.
+func (t *tableLayout) wrap(ctx *Context) {
+	for i := range t.rows {
+		row := &t.rows[i]
+		row.height = 0
+		for j := range row.cells {
+			cell := &row.cells[j]
+			padding := cell.padding()
+			width := t.span(cell.column, cell.colSpan) - padding.Left - padding.Right
+			cell.block = wrapBlock(ctx, cell.face, cell.text, cell.style, max(width, 0))
+			if cell.rowSpan == 1 {
+				row.height = max(row.height, cell.height())
+			}
+		}
+	}
+
+	for i, row := range t.rows {
+		for _, cell := range row.cells {
+			if cell.rowSpan == 1 {
+				continue
+			}
+			rows := t.rows[i : i+cell.rowSpan]
+			have := 0.0
+			for _, spanned := range rows {
+				have += spanned.height
+			}
+			if need := cell.height(); need > have {
+				for k := range rows {
+					rows[k].height += (need - have) / float64(len(rows))
+				}
+			}
+		}
+	}
+}
+
+// span returns the width of n columns from the given one
+func (t *tableLayout) span(column, n int) float64 {
+	width := 0.0
+	for _, w := range t.widths[column : column+n] {
+		width += w
+	}
+	return width
+}
+
+// tableReader turns the rows of a table element into styled cells
+type tableReader struct {
+	ctx     *Context
+	id      string
+	opts    model.TableOptions
+	keys    []*binding.Expression
+	formats []*binding.Expression
+	scope   *binding.Scope
+	body    model.Style
+	header  model.Style
+	// occupied counts, for each column, the rows still covered by a cell
+	// spanning down from a row already read
+	occupied []int
+}
+
+func newTableReader(ctx *Context, element model.Element) (*tableReader, error) {
+	r := &tableReader{ctx: ctx, id: element.ID}
+	if err := element.DecodeMetadata(&r.opts); err != nil {
+		return nil, err
+	}
+
+	r.keys = make([]*binding.Expression, len(r.opts.Columns))
+	r.formats = make([]*binding.Expression, len(r.opts.Columns))
+	for i, column := range r.opts.Columns {
+		if column.Key != "" {
+			expr, err := binding.Parse(column.Key)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key of column %d in table %s: %w", i, element.ID, err)
+			}
+			r.keys[i] = expr
+		}
+		if column.Format == "" {
+			continue
+		}
+		expr, err := binding.Parse("value | " + column.Format)
+		if err != nil {
+			return nil, fmt.Errorf("invalid format of column %d in table %s: %w", i, element.ID, err)
+		}
+		r.formats[i] = expr
+	}
+	scope, err := binding.NewScope(nil)
+	if err != nil {
+		return nil, err
+	}
+	r.scope = scope
+
+	// The box properties belong to the table, the rest are inherited by cells
+	r.body = tableStyle(element.Style)
+	r.body.Background, r.body.Padding = "", nil
+	r.body.BorderTop, r.body.BorderRight, r.body.BorderBottom, r.body.BorderLeft = nil, nil, nil, nil
+	r.header = overlayStyle(r.body, &model.Style{FontWeight: model.FontWeightBold})
+	r.header = overlayStyle(r.header, r.opts.HeaderStyle)
+	return r, nil
+}
+
+// row styles and formats the cells of one row. Header rows take the header
+// style and skip the column styles and formats.
+func (r *tableReader) row(raw interface{}, name string, kind rowKind, striped bool) (tableRow, error) {
+	if fields, ok := raw.(map[string]interface{}); ok {
+		if _, ok := fields["cells"]; !ok {
+			cells, err := r.dataRow(fields)
+			if err != nil {
+				return tableRow{}, fmt.Errorf("invalid %s in table %s: %w", name, r.id, err)
+			}
+			raw = cells
+		}
+	}
+	cells, rowStyle, keep, err := parseRow(raw)
+	if err != nil {
+		return tableRow{}, fmt.Errorf("invalid %s in table %s: %w", name, r.id, err)
+	}
+
+	row := tableRow{kind: kind, keepWithNext: keep}
+	column := 0
+	for j, raw := range cells {
+		value, override, colSpan, rowSpan, err := parseCell(raw)
+		if err != nil {
+			return row, fmt.Errorf("invalid cell %d of %s in table %s: %w", j, name, r.id, err)
+		}
+		for column < len(r.occupied) && r.occupied[column] > 0 {
+			column++
+		}
+
+		style := r.body
+		if kind == headerRow {
+			style = r.header
+		} else if striped {
+			style.Background = r.opts.Stripe
+		}
+		text := value
+		if column < len(r.opts.Columns) {
+			spec := r.opts.Columns[column]
+			if spec.Alignment != "" {
+				style.Alignment = spec.Alignment
+			}
+			if kind != headerRow {
+				style = overlayStyle(style, spec.Style)
+				if r.formats[column] != nil && value != nil && value != "" {
+					if text, err = r.formats[column].Eval(r.scope.Child(map[string]interface{}{"value": value})); err != nil {
+						return row, fmt.Errorf("failed to format cell %d of %s in table %s: %w", j, name, r.id, err)
+					}
+				}
+			}
+		}
+		style = overlayStyle(style, rowStyle)
+		style = overlayStyle(style, override)
+
+		row.cells = append(row.cells, tableCell{
+			value:   value,
+			text:    cellText(text),
+			style:   style,
+			face:    styleTypeface(r.ctx, style),
+			column:  column,
+			colSpan: colSpan,
+			rowSpan: rowSpan,
+		})
+		for len(r.occupied) < column+colSpan {
+			r.occupied = append(r.occupied, 0)
+		}
+		for k := column; k < column+colSpan; k++ {
+			r.occupied[k] = rowSpan
+		}
+		column += colSpan
+	}
+
+	for k := range r.occupied {
+		if r.occupied[k] > 0 {
+			r.occupied[k]--
+		}
+	}
+	return row, nil
+}
+
+// dataRow reads the cells of a data object row through the column keys.
+// Columns without a key are left empty.
+func (r *tableReader) dataRow(fields map[string]interface{}) ([]interface{}, error) {
+	cells := make([]interface{}, len(r.keys))
+	found := false
+	for i, key := range r.keys {
+		if key == nil {
+			continue
+		}
+		value, err := key.Eval(r.scope.Child(fields))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read column %d: %w", i, err)
+		}
+		cells[i], found = value, true
+	}
+	if !found {
+		return nil, fmt.Errorf("data rows need columns with a key")
+	}
+	return cells, nil
+}
+
+// columnHeaders returns the row of column header labels, or nil when no
+// column has one
+func columnHeaders(columns []model.TableColumn) []interface{} {
+	labels := make([]interface{}, len(columns))
+	found := false
+	for i, column := range columns {
+		labels[i] = column.Header
+		found = found || column.Header != ""
+	}
+	if !found {
+		return nil
+	}
+	return labels
+}
+
+// parseRow splits a row into its cells, style and keepWithNext flag. Rows
+// are lists of cells or objects with "cells", "style" and "keepWithNext".
+func parseRow(row interface{}) ([]interface{}, *model.Style, bool, error) {
+	switch v := row.(type) {
+	case []interface{}:
+		return v, nil, false, nil
+	case map[string]interface{}:
+		cells, ok := v["cells"].([]interface{})
+		if !ok {
+			return nil, nil, false, fmt.Errorf("row cells must be a list, got %T", v["cells"])
+		}
+		style, err := decodeStyle(v["style"])
+		if err != nil {
+			return nil, nil, false, err
+		}
+		keep, ok := v["keepWithNext"].(bool)
+		if _, set := v["keepWithNext"]; set && !ok {
+			return nil, nil, false, fmt.Errorf("keepWithNext must be true or false")
+		}
+		return cells, style, keep, nil
+	}
+	return nil, nil, false, fmt.Errorf("expected []interface{}, got %T", row)
+}
+
+// parseCell splits a cell into its value, style override and the columns
+// and rows it spans. Cells are plain values or objects with "value",
+// "style", "colSpan" and "rowSpan".
+func parseCell(cell interface{}) (interface{}, *model.Style, int, int, error) {
+	fields, ok := cell.(map[string]interface{})
+	if !ok || !isCellObject(fields) {
+		return cell, nil, 1, 1, nil
+	}
+
+	style, err := decodeStyle(fields["style"])
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	colSpan, err := cellSpan(fields, "colSpan")
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	rowSpan, err := cellSpan(fields, "rowSpan")
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	return fields["value"], style, colSpan, rowSpan, nil
+}
+
+// isCellObject reports whether a map is a cell object rather than a value
+func isCellObject(fields map[string]interface{}) bool {
+	for _, key := range []string{"value", "style", "colSpan", "rowSpan"} {
+		if _, ok := fields[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// cellSpan reads the colSpan or rowSpan of a cell object, 1 when unset
+func cellSpan(fields map[string]interface{}, key string) (int, error) {
+	value, ok := fields[key]
+	if !ok {
+		return 1, nil
+	}
+	n, ok := binding.ToNumber(value)
+	if !ok || n < 1 || n != float64(int(n)) {
+		return 0, fmt.Errorf("%s must be a whole number of at least 1, got %v", key, value)
+	}
+	return int(n), nil
+}
+
+// decodeStyle reads a style given as a JSON object in table content
+func decodeStyle(value interface{}) (*model.Style, error) {
+	if value == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var style model.Style
+	if err := json.Unmarshal(raw, &style); err != nil {
+		return nil, fmt.Errorf("invalid style: %w", err)
+	}
+	return &style, nil
+}
+
+// breakCells replaces the {total} and {pageTotal} tokens in the cells of a
+// subtotal or carried forward row with the totals of their columns
+func breakCells(cells []interface{}, total, page []float64) []interface{} {
+	out := make([]interface{}, len(cells))
+	column := 0
+	for j, cell := range cells {
+		i := column
+		if _, _, colSpan, _, err := parseCell(cell); err == nil {
+			column += colSpan
+		}
+		replace := func(value interface{}) interface{} {
+			var totals []float64
+			switch value {
+			case "{total}":
+				totals = total
+			case "{pageTotal}":
+				totals = page
+			default:
+				return value
+			}
+			if i < len(totals) {
+				return totals[i]
+			}
+			return 0.0
+		}
+
+		fields, ok := cell.(map[string]interface{})
+		if !ok {
+			out[j] = replace(cell)
+			continue
+		}
+		copied := make(map[string]interface{}, len(fields))
+		for key, value := range fields {
+			copied[key] = value
+		}
+		copied["value"] = replace(fields["value"])
+		out[j] = copied
+	}
+	return out
+}
+
+// sumTotals adds two lists of column totals
+func sumTotals(a, b []float64) []float64 {
+	sum := make([]float64, max(len(a), len(b)))
+	for i := range sum {
+		if i < len(a) {
+			sum[i] += a[i]
+		}
+		if i < len(b) {
+			sum[i] += b[i]
+		}
+	}
+	return sum
+}
+
+// columnWidths sizes n columns to fill the width. Fixed and percentage
+// widths are taken as given, auto columns fit their widest cell and the
+// columns without a width share what is left. Auto columns shrink when the
+// table would otherwise be too wide.
+func columnWidths(columns []model.TableColumn, rows []tableRow, n int, width float64) ([]float64, error) {
+	widths := make([]float64, n)
+	var shared, auto []int
+	used, autoWidth := 0.0, 0.0
+
+	for i := range widths {
+		var spec model.ColumnWidth
+		if i < len(columns) {
+			spec = columns[i].Width
+		}
+
+		switch value := strings.TrimSpace(string(spec)); {
+		case value == "":
+			shared = append(shared, i)
+			continue
+		case spec == model.ColumnWidthAuto:
+			// Cells spanning several columns wrap to whatever width they get
+			for _, row := range rows {
+				for _, cell := range row.cells {
+					if cell.column == i && cell.colSpan == 1 {
+						widths[i] = max(widths[i], cell.width())
+					}
+				}
+			}
+			auto = append(auto, i)
+			autoWidth += widths[i]
+		case strings.HasSuffix(value, "%"):
+			percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+			if err != nil || percent < 0 {
+				return nil, fmt.Errorf("invalid width %q of column %d", spec, i)
+			}
+			widths[i] = width * percent / 100
+		default:
+			fixed, err := strconv.ParseFloat(value, 64)
+			if err != nil || fixed < 0 {
+				return nil, fmt.Errorf("invalid width %q of column %d", spec, i)
+			}
+			widths[i] = fixed
+		}
+		used += widths[i]
+	}
+
+	rest := width - used
+	switch {
+	case rest > 0 && len(shared) > 0:
+		for _, i := range shared {
+			widths[i] = rest / float64(len(shared))
+		}
+	case rest < 0 && autoWidth > 0:
+		scale := (autoWidth + rest) / autoWidth
+		if scale < 0 {
+			scale = 0
+		}
+		for _, i := range auto {
+			widths[i] *= scale
+		}
+	}
+	return widths, nil
+}
+
+// draw paints the cell backgrounds, then the borders and text, so that no
+// background covers the border of a neighbouring cell
+func (t *tableLayout) draw(ctx *Context, x, y float64) error {
+	pdf := ctx.PDF
+	err := t.each(x, y, func(cell tableCell, bounds model.Bounds) error {
+		if cell.style.Background == "" {
+			return nil
+		}
+		background, err := color.Parse(cell.style.Background)
+		if err != nil {
+			return fmt.Errorf("invalid cell background: %w", err)
+		}
+		if !background.Transparent() {
+			withAlpha(pdf, background, func() {
+				pdf.SetFillColor(background.R, background.G, background.B)
+				pdf.Rect(bounds.X, bounds.Y, bounds.Width, bounds.Height, "F")
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = t.each(x, y, func(cell tableCell, bounds model.Bounds) error {
+		ok, err := setStroke(pdf, cell.style.Border)
+		if err != nil {
+			return fmt.Errorf("invalid cell border: %w", err)
+		}
+		if ok {
+			pdf.Rect(bounds.X, bounds.Y, bounds.Width, bounds.Height, "D")
+		}
+		return cell.drawText(bounds)
+	})
+	resetStroke(pdf)
+	return err
+}
+
+// each calls fn with every cell and its bounds across the columns and rows
+// it spans, row by row
+func (t *tableLayout) each(x, y float64, fn func(cell tableCell, bounds model.Bounds) error) error {
+	for i, row := range t.rows {
+		for _, cell := range row.cells {
+			height := 0.0
+			for _, spanned := range t.rows[i : i+cell.rowSpan] {
+				height += spanned.height
+			}
+			bounds := model.Bounds{
+				Position: model.Position{X: x + t.span(0, cell.column), Y: y},
+				Size:     model.Size{Width: t.span(cell.column, cell.colSpan), Height: height},
+			}
+			if err := fn(cell, bounds); err != nil {
+				return err
+			}
+		}
+		y += row.height
+	}
+	return nil
+}
+
+// drawText writes the wrapped cell text inside its padding at the vertical
+// alignment of the cell
+func (c tableCell) drawText(bounds model.Bounds) error {
+	if c.text == "" {
+		return nil
+	}
+	pdf := c.face.ctx.PDF
+	textColor, err := setTextColor(pdf, &c.style)
+	if err != nil {
+		return err
+	}
+
+	padding := c.padding()
+	inner := insetPadding(bounds, &model.Style{Padding: &padding})
+	block := c.block
+	// Each line sits in the middle of its line height, as a lone line does in its row
+	inner.Y += (block.lineHeight-block.fontHeight)/2 +
+		verticalOffset(c.style.VerticalAlignment, inner.Height, float64(len(block.lines))*block.lineHeight)
+
+	withAlpha(pdf, textColor, func() {
+		block.draw(inner, c.style.Alignment)
+	})
+	return nil
+}
+
+// cellText converts a bound content value to display text
+func cellText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return fmt.Sprintf("%.2f", v)
+	case int:
+		return fmt.Sprintf("%d", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,459 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+func tableElement(metadata string, rows ...[]interface{}) model.Element {
+	content := make([]interface{}, len(rows))
+	for i, row := range rows {
+		content[i] = row
+	}
+	element := model.Element{
+		ID:      "items",
+		Type:    model.ElementTypeTable,
+		Bounds:  model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 190, Height: 30}},
+		Content: content,
+		Style:   &model.Style{Border: &model.Border{Width: 0.2}},
+	}
+	if metadata != "" {
+		element.Metadata = json.RawMessage(metadata)
+	}
+	return element
+}
+
+func TestColumnWidths(t *testing.T) {
+	ctx := newTestContext()
+	style := tableStyle(nil)
+	face := styleTypeface(ctx, style)
+	rows := []tableRow{{cells: []tableCell{
+		{text: "A", style: style, face: face, column: 0, colSpan: 1},
+		{text: "Quantity", style: style, face: face, column: 1, colSpan: 1},
+		{text: "C", style: style, face: face, column: 2, colSpan: 1},
+	}}}
+	autoWidth := face.width("Quantity") + cellPadding.Left + cellPadding.Right
+
+	tests := []struct {
+		name    string
+		columns string
+		want    []float64
+		wantErr bool
+	}{
+		{name: "even", want: []float64{60, 60, 60}},
+		{name: "fixed and shared", columns: `[{"width": 40}, {}, {"width": "20"}]`, want: []float64{40, 120, 20}},
+		{name: "percent", columns: `[{"width": "50%"}]`, want: []float64{90, 45, 45}},
+		{name: "auto", columns: `[{}, {"width": "auto"}]`, want: []float64{(180 - autoWidth) / 2, autoWidth, (180 - autoWidth) / 2}},
+		{name: "auto shrinks to fit", columns: `[{"width": 100}, {"width": "auto"}, {"width": 80 }]`, want: []float64{100, 0, 80}},
+		{name: "invalid", columns: `[{"width": "wide"}]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var columns []model.TableColumn
+			if tt.columns != "" {
+				if err := json.Unmarshal([]byte(tt.columns), &columns); err != nil {
+					t.Fatalf("Unmarshal() error = %v", err)
+				}
+			}
+
+			got, err := columnWidths(columns, rows, 3, 180)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("columnWidths() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("columnWidths() error = %v", err)
+			}
+			for i := range tt.want {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("columnWidths() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestLayoutTable(t *testing.T) {
+	ctx := newTestContext()
+	element := tableElement(`{
+		"headerRows": 1,
+		"headerStyle": {"background": "#eeeeee"},
+		"stripe": "#f9f9f9",
+		"columns": [{}, {"alignment": "right", "format": "currency \"USD\"", "style": {"fontColor": "#333333"}}]
+	}`,
+		[]interface{}{"Item", "Amount"},
+		[]interface{}{"Rent", 1200.0},
+		[]interface{}{"Deposit", map[string]interface{}{"value": -250.0, "style": map[string]interface{}{"fontColor": "red"}}},
+		[]interface{}{"Fees", ""},
+	)
+
+	table, err := layoutTable(ctx, element, 190)
+	if err != nil {
+		t.Fatalf("layoutTable() error = %v", err)
+	}
+
+	header, amount := table.rows[0].cells[1], table.rows[1].cells[1]
+	if header.text != "Amount" || !header.style.FontWeight.Bold() || header.style.Background != "#eeeeee" {
+		t.Errorf("header cell = %q %+v, want bold Amount on #eeeeee", header.text, header.style)
+	}
+	if header.style.Alignment != model.AlignRight || amount.style.Alignment != model.AlignRight {
+		t.Errorf("amount column alignment = %s, %s, want right", header.style.Alignment, amount.style.Alignment)
+	}
+	if amount.text != "$1,200.00" || amount.style.FontColor != "#333333" || amount.style.FontWeight.Bold() {
+		t.Errorf("amount cell = %q %+v, want $1,200.00 in the column color", amount.text, amount.style)
+	}
+	if deposit := table.rows[2].cells[1]; deposit.text != "-$250.00" || deposit.style.FontColor != "red" {
+		t.Errorf("deposit cell = %q %+v, want -$250.00 in red", deposit.text, deposit.style)
+	}
+	if fees := table.rows[3].cells[1]; fees.text != "" {
+		t.Errorf("empty cell formatted as %q", fees.text)
+	}
+
+	stripes := []string{table.rows[1].cells[0].style.Background, table.rows[2].cells[0].style.Background, table.rows[3].cells[0].style.Background}
+	if stripes[0] != "" || stripes[1] != "#f9f9f9" || stripes[2] != "" {
+		t.Errorf("body row backgrounds = %q, want every second row striped", stripes)
+	}
+}
+
+func TestLayoutTable_InvalidFormat(t *testing.T) {
+	element := tableElement(`{"columns": [{"format": "currency"}]}`, []interface{}{"not a number"})
+	if _, err := layoutTable(newTestContext(), element, 190); err == nil || !strings.Contains(err.Error(), "failed to format cell 0 of row 0") {
+		t.Errorf("layoutTable() error = %v, want a format error", err)
+	}
+}
+
+func TestLayoutTable_DataRows(t *testing.T) {
+	element := tableElement(`{"columns": [
+		{"key": "product.name", "header": "Item"},
+		{"key": "quantity", "header": "Qty", "format": "number 0", "alignment": "right"},
+		{"header": "Notes"}
+	]}`)
+	element.Content = []interface{}{
+		map[string]interface{}{"product": map[string]interface{}{"name": "Widget"}, "quantity": 3.0},
+		map[string]interface{}{"product": map[string]interface{}{"name": "Gadget"}},
+	}
+
+	table, err := layoutTable(newTestContext(), element, 190)
+	if err != nil {
+		t.Fatalf("layoutTable() error = %v", err)
+	}
+	if len(table.rows) != 3 {
+		t.Fatalf("table has %d rows, want the column headers and 2 rows", len(table.rows))
+	}
+
+	want := [][]string{{"Item", "Qty", "Notes"}, {"Widget", "3", ""}, {"Gadget", "", ""}}
+	for i, row := range table.rows {
+		var got []string
+		for _, cell := range row.cells {
+			got = append(got, cell.text)
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("row %d = %q, want %q", i, got, want[i])
+		}
+	}
+	if header := table.rows[0]; header.kind != headerRow || !header.cells[0].style.FontWeight.Bold() {
+		t.Errorf("column headers = %+v, want a bold header row", header)
+	}
+	if got := table.rows[1].cells[1].style.Alignment; got != model.AlignRight {
+		t.Errorf("quantity alignment = %s, want right", got)
+	}
+
+	// A missing array leaves only the column headers
+	element.Content = nil
+	if table, err := layoutTable(newTestContext(), element, 190); err != nil || len(table.rows) != 1 {
+		t.Errorf("layoutTable() = %v, %v, want the column headers only", table, err)
+	}
+
+	bad := tableElement("")
+	bad.Content = []interface{}{map[string]interface{}{"name": "Widget"}}
+	if _, err := layoutTable(newTestContext(), bad, 190); err == nil || !strings.Contains(err.Error(), "need columns with a key") {
+		t.Errorf("layoutTable() error = %v, want a missing key error", err)
+	}
+}
+
+func TestLayoutTable_Spans(t *testing.T) {
+	element := tableElement(`{"headerRows": 1, "columns": [{}, {"format": "number 0"}, {}]}`,
+		[]interface{}{"Item", map[string]interface{}{"value": "Details", "colSpan": 2.0}},
+		[]interface{}{map[string]interface{}{"value": "Rent", "rowSpan": 2.0}, 1200.0, "monthly"},
+		[]interface{}{300.0, "deposit"},
+		[]interface{}{map[string]interface{}{"value": "Fees", "rowSpan": 3.0}, 25.0, "once"},
+	)
+
+	table, err := layoutTable(newTestContext(), element, 190)
+	if err != nil {
+		t.Fatalf("layoutTable() error = %v", err)
+	}
+	if len(table.widths) != 3 {
+		t.Fatalf("table has %d columns, want 3", len(table.widths))
+	}
+
+	details := table.rows[0].cells[1]
+	if details.column != 1 || details.colSpan != 2 || math.Abs(table.span(details.column, details.colSpan)-190*2/3.0) > 1e-9 {
+		t.Errorf("details cell at column %d spanning %d, want column 1 spanning 2", details.column, details.colSpan)
+	}
+	// The deposit row starts after the column the rent cell spans down into
+	deposit := table.rows[2].cells
+	if deposit[0].column != 1 || deposit[0].text != "300" || deposit[1].column != 2 {
+		t.Errorf("deposit row = %+v, want 300 formatted in column 1", deposit)
+	}
+	if !table.rows[1].keepWithNext || table.rows[2].keepWithNext {
+		t.Errorf("keepWithNext = %v, %v, want the rows of the span kept together", table.rows[1].keepWithNext, table.rows[2].keepWithNext)
+	}
+	if got := table.rows[3].cells[0].rowSpan; got != 1 {
+		t.Errorf("last row span = %d, want it limited to the end of the table", got)
+	}
+	if totals := table.totals(); len(totals) < 2 || totals[1] != 1525 {
+		t.Errorf("totals() = %v, want 1525 in column 1", totals)
+	}
+
+	bad := tableElement("", []interface{}{map[string]interface{}{"value": "A", "colSpan": 0.0}})
+	if _, err := layoutTable(newTestContext(), bad, 190); err == nil || !strings.Contains(err.Error(), "colSpan") {
+		t.Errorf("layoutTable() error = %v, want a span error", err)
+	}
+}
+
+func TestLayoutTable_Wrap(t *testing.T) {
+	ctx := newTestContext()
+	long := "Monthly rent for the apartment on the third floor"
+	element := tableElement(`{"columns": [{"width": 30}, {}]}`,
+		[]interface{}{long, "1,200.00"},
+		[]interface{}{map[string]interface{}{"value": long, "rowSpan": 2.0}, "A"},
+		[]interface{}{"B"},
+	)
+
+	table, err := layoutTable(ctx, element, 190)
+	if err != nil {
+		t.Fatalf("layoutTable() error = %v", err)
+	}
+	lineHeight := ctx.PDF.PointToUnitConvert(tableFontSize) * tableLineHeight
+
+	first := table.rows[0]
+	lines := len(first.cells[0].block.lines)
+	if lines < 2 || len(first.cells[1].block.lines) != 1 {
+		t.Fatalf("wrapped to %d and %d lines, want the long cell on several lines", lines, len(first.cells[1].block.lines))
+	}
+	if math.Abs(first.height-float64(lines)*lineHeight) > 1e-9 {
+		t.Errorf("row height = %v, want %d lines of %v", first.height, lines, lineHeight)
+	}
+	// The spanning cell's extra height is shared by its rows
+	spanned := table.rows[1].height + table.rows[2].height
+	if math.Abs(spanned-first.height) > 1e-9 || math.Abs(table.rows[1].height-table.rows[2].height) > 1e-9 {
+		t.Errorf("spanned row heights = %v, %v, want %v shared evenly", table.rows[1].height, table.rows[2].height, first.height)
+	}
+}
+
+func TestTableRenderer_VerticalAlignment(t *testing.T) {
+	ctx := newTestContext()
+	element := tableElement(`{"columns": [{"width": 30}, {}, {}, {}]}`, []interface{}{
+		"Monthly rent for the apartment on the third floor",
+		map[string]interface{}{"value": "Top", "style": map[string]interface{}{"verticalAlignment": "top"}},
+		"Middle",
+		map[string]interface{}{"value": "Bottom", "style": map[string]interface{}{"verticalAlignment": "bottom"}},
+	})
+	element.Style = nil
+	if err := (&TableRenderer{}).Render(ctx, element); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	table, err := layoutTable(ctx, element, 190)
+	if err != nil {
+		t.Fatalf("layoutTable() error = %v", err)
+	}
+
+	// Baselines are written in points from the bottom of the page
+	k := ctx.PDF.GetConversionRatio()
+	baselines := make(map[string]float64)
+	for _, m := range regexp.MustCompile(`BT [0-9.]+ ([0-9.]+) Td \((\w+)\) Tj`).FindAllStringSubmatch(output(t, ctx), -1) {
+		y, _ := strconv.ParseFloat(m[1], 64)
+		baselines[m[2]] = 297 - y/k
+	}
+
+	fontHeight := ctx.PDF.PointToUnitConvert(tableFontSize)
+	lineHeight := fontHeight * tableLineHeight
+	top := 10 + (lineHeight-fontHeight)/2 + fontHeight
+	free := table.rows[0].height - lineHeight
+	tests := []struct {
+		word string
+		want float64
+	}{
+		{word: "Top", want: top},
+		{word: "Middle", want: top + free/2},
+		{word: "Bottom", want: top + free},
+	}
+	for _, tt := range tests {
+		got, ok := baselines[tt.word]
+		if !ok || math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("%q drawn at y=%.2f, want %.2f", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestTableRenderer_Render(t *testing.T) {
+	ctx := newTestContext()
+	element := tableElement(`{"headerRows": 1, "stripe": "#f0f0f0", "columns": [{"width": "auto"}, {"alignment": "right"}]}`,
+		[]interface{}{"Item", "Total"},
+		[]interface{}{"Rent", "1,200.00"},
+		[]interface{}{"Deposit", "250.00"},
+	)
+	if err := (&TableRenderer{}).Render(ctx, element); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	out := output(t, ctx)
+	for _, want := range []string{"/BaseFont /Helvetica-Bold\n", "(Item) Tj", "(1,200.00) Tj", "0.941 g\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("document does not contain %q", want)
+		}
+	}
+	// Six bordered cells, with the two of the second body row striped
+	if got := strings.Count(out, " re S"); got != 6 {
+		t.Errorf("drew %d cell borders, want 6", got)
+	}
+	if got := strings.Count(out, " re f"); got != 2 {
+		t.Errorf("drew %d cell backgrounds, want 2", got)
+	}
+
+	height, err := (&TableRenderer{}).Measure(ctx, element)
+	if err != nil {
+		t.Fatalf("Measure() error = %v", err)
+	}
+	if want := 3 * ctx.PDF.PointToUnitConvert(tableFontSize) * tableLineHeight; math.Abs(height-want) > 1e-9 {
+		t.Errorf("Measure() = %v, want %v", height, want)
+	}
+}
+
+func TestTableRenderer_Split(t *testing.T) {
+	ctx := newTestContext()
+	rows := [][]interface{}{{"Date", "Amount"}}
+	for i := 1; i <= 10; i++ {
+		rows = append(rows, []interface{}{fmt.Sprintf("2024-03-%02d", i), float64(i * 10)})
+	}
+	element := tableElement(`{
+		"headerRows": 1,
+		"stripe": "#f9f9f9",
+		"columns": [{}, {"format": "number 0"}],
+		"subtotal": ["Carried forward", "{total}"],
+		"carriedForward": ["Brought forward", "{total}"]
+	}`, rows...)
+	rowHeight := ctx.PDF.PointToUnitConvert(tableFontSize) * tableLineHeight
+
+	// Room for the header, four rows and the subtotal
+	parts, err := (&TableRenderer{}).Split(ctx, element, 6.5*rowHeight, 200)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("Split() = %d parts, want 2", len(parts))
+	}
+	head, tail := parts[0], parts[1]
+	// The place of each part is layout state, not template metadata
+	if string(head.Metadata) != string(element.Metadata) || string(tail.Metadata) != string(element.Metadata) {
+		t.Errorf("Split() changed the metadata to %s and %s", head.Metadata, tail.Metadata)
+	}
+	if got := len(head.Content.([]interface{})); got != 5 {
+		t.Errorf("first part has %d rows, want the header and 4 rows", got)
+	}
+	if got := len(tail.Content.([]interface{})); got != 7 {
+		t.Errorf("second part has %d rows, want the header and 6 rows", got)
+	}
+	if math.Abs(head.Bounds.Height-6*rowHeight) > 1e-9 || math.Abs(tail.Bounds.Height-8*rowHeight) > 1e-9 {
+		t.Errorf("part heights = %v, %v, want 6 and 8 rows", head.Bounds.Height/rowHeight, tail.Bounds.Height/rowHeight)
+	}
+
+	first, err := layoutTable(ctx, head, 190)
+	if err != nil {
+		t.Fatalf("layoutTable() error = %v", err)
+	}
+	subtotal := first.rows[len(first.rows)-1]
+	if subtotal.kind != breakRow || subtotal.cells[0].text != "Carried forward" || subtotal.cells[1].text != "100" {
+		t.Errorf("subtotal row = %+v, want the total of the first four rows", subtotal.cells)
+	}
+
+	rest, err := layoutTable(ctx, tail, 190)
+	if err != nil {
+		t.Fatalf("layoutTable() error = %v", err)
+	}
+	if rest.rows[0].kind != headerRow || rest.rows[0].cells[0].text != "Date" {
+		t.Errorf("second part does not start with the header: %+v", rest.rows[0].cells)
+	}
+	if carried := rest.rows[1]; carried.kind != breakRow || carried.cells[1].text != "100" {
+		t.Errorf("carried forward row = %+v, want 100", carried.cells)
+	}
+	// The fifth body row keeps its place in the striping
+	if got := rest.rows[2].cells[0]; got.text != "2024-03-05" || got.style.Background != "" {
+		t.Errorf("first continued row = %q on %q, want 2024-03-05 unstriped", got.text, got.style.Background)
+	}
+	if got := rest.rows[3].cells[0].style.Background; got != "#f9f9f9" {
+		t.Errorf("second continued row background = %q, want striped", got)
+	}
+
+	// Splitting the rest again carries the running total on
+	more, err := (&TableRenderer{}).Split(ctx, tail, 6.5*rowHeight, 200)
+	if err != nil || len(more) != 2 {
+		t.Fatalf("Split() = %d parts, %v, want 2 parts", len(more), err)
+	}
+	second, err := layoutTable(ctx, more[0], 190)
+	if err != nil {
+		t.Fatalf("layoutTable() error = %v", err)
+	}
+	// Rows 5 to 7 follow the header and carried forward rows
+	if got := second.rows[len(second.rows)-1].cells[1].text; got != "280" {
+		t.Errorf("second subtotal = %q, want 280", got)
+	}
+}
+
+func TestTableRenderer_SplitKeep(t *testing.T) {
+	ctx := newTestContext()
+	rowHeight := ctx.PDF.PointToUnitConvert(tableFontSize) * tableLineHeight
+	rows := []interface{}{
+		[]interface{}{"Group A"},
+		map[string]interface{}{"cells": []interface{}{"Group B"}, "keepWithNext": true},
+		[]interface{}{"B1"},
+		[]interface{}{"B2"},
+	}
+
+	element := tableElement("")
+	element.Content = rows
+	parts, err := (&TableRenderer{}).Split(ctx, element, 2.5*rowHeight, 200)
+	if err != nil || len(parts) != 2 {
+		t.Fatalf("Split() = %d parts, %v, want 2 parts", len(parts), err)
+	}
+	if got := len(parts[0].Content.([]interface{})); got != 1 {
+		t.Errorf("first part has %d rows, want 1 as Group B stays with B1", got)
+	}
+
+	// Rows joined by a cell spanning down stay together
+	element.Content = []interface{}{
+		[]interface{}{"A"},
+		[]interface{}{map[string]interface{}{"value": "B", "rowSpan": 2.0}, "B1"},
+		[]interface{}{"B2"},
+	}
+	parts, err = (&TableRenderer{}).Split(ctx, element, 2.5*rowHeight, 200)
+	if err != nil || len(parts) != 2 {
+		t.Fatalf("Split() = %d parts, %v, want 2 parts", len(parts), err)
+	}
+	if got := len(parts[0].Content.([]interface{})); got != 1 {
+		t.Errorf("first part has %d rows, want 1 as the B rows share a cell", got)
+	}
+
+	element.Content = rows
+	element.Metadata = json.RawMessage(`{"keepTogether": true}`)
+	element.Bounds.Height = 4 * rowHeight
+	if parts, err := (&TableRenderer{}).Split(ctx, element, 2.5*rowHeight, 200); err != nil || parts != nil {
+		t.Errorf("Split() = %v, %v, want no split for a table kept together", parts, err)
+	}
+	if parts, err := (&TableRenderer{}).Split(ctx, element, 2.5*rowHeight, 3*rowHeight); err != nil || len(parts) != 2 {
+		t.Errorf("Split() = %v, %v, want a split for a table taller than a page", parts, err)
+	}
+}
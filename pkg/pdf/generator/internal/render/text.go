@@ -0,0 +1,364 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/internal/color"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// Text defaults for elements without a style
+const (
+	defaultFontFamily = "Arial"
+	defaultFontSize   = 12.0
+	// defaultLineHeight matches the baseline spacing text had before wrapping
+	defaultLineHeight = 1.5
+)
+
+const (
+	// descentRatio is the share of the font size drawn below the baseline
+	descentRatio = 0.25
+	// minShrinkFontSize is the smallest size the shrink policy reduces text to
+	minShrinkFontSize = 4.0
+	shrinkStep        = 0.5
+	ellipsis          = "..."
+)
+
+// TextRenderer handles rendering of text elements. Text is wrapped at word
+// boundaries to the element width and the style's overflow policy decides
+// what happens to lines that do not fit the height. Content is a string,
+// Markdown when the metadata format says so, or a list of styled runs.
+type TextRenderer struct{}
+
+func (r *TextRenderer) Render(ctx *Context, element model.Element) error {
+	content, runs, err := textContent(element)
+	if err != nil {
+		return err
+	}
+
+	pdf := ctx.PDF
+	bounds, err := drawBox(ctx, element)
+	if err != nil {
+		return err
+	}
+	textColor, err := setTextColor(pdf, element.Style)
+	if err != nil {
+		return err
+	}
+
+	style := textStyle(element.Style)
+	if style.Overflow == model.OverflowClip {
+		pdf.ClipRect(bounds.X, bounds.Y, bounds.Width, bounds.Height, false)
+		defer pdf.ClipEnd()
+	}
+
+	if runs != nil {
+		block, err := layoutRich(ctx, runs, style, bounds, textColor)
+		if err != nil {
+			return fmt.Errorf("failed to lay out element %s: %w", element.ID, err)
+		}
+		bounds.Y += verticalOffset(style.VerticalAlignment, bounds.Height, block.height())
+		block.draw(bounds, style.Alignment)
+		return nil
+	}
+
+	block := layoutText(ctx, content, style, bounds)
+	bounds.Y += verticalOffset(style.VerticalAlignment, bounds.Height, block.height())
+	withAlpha(pdf, textColor, func() {
+		block.draw(bounds, style.Alignment)
+	})
+	return nil
+}
+
+// verticalOffset returns how far below the top of a space of the given
+// height content is placed. Content taller than the space stays at the top.
+func verticalOffset(alignment model.VerticalAlignment, space, content float64) float64 {
+	free := max(space-content, 0)
+	switch alignment {
+	case model.AlignMiddle:
+		return free / 2
+	case model.AlignBottom:
+		return free
+	}
+	return 0
+}
+
+// Measure returns the height the wrapped text needs at the element width,
+// including the padding
+func (r *TextRenderer) Measure(ctx *Context, element model.Element) (float64, error) {
+	content, runs, err := textContent(element)
+	if err != nil {
+		return 0, err
+	}
+
+	style := textStyle(element.Style)
+	bounds := insetPadding(element.Bounds, element.Style)
+	padding := element.Bounds.Height - bounds.Height
+
+	if runs != nil {
+		block, err := wrapRich(ctx, runs, style, style.FontSize, color.Black, bounds.Width)
+		if err != nil {
+			return 0, fmt.Errorf("failed to lay out element %s: %w", element.ID, err)
+		}
+		return block.height() + padding, nil
+	}
+
+	block := wrapBlock(ctx, styleTypeface(ctx, style), content, style, bounds.Width)
+	return block.height() + padding, nil
+}
+
+// textStyle returns a copy of style with the text defaults filled in
+func textStyle(style *model.Style) model.Style {
+	var s model.Style
+	if style != nil {
+		s = *style
+	}
+	if s.FontFamily == "" {
+		s.FontFamily = defaultFontFamily
+	}
+	if s.FontSize <= 0 {
+		s.FontSize = defaultFontSize
+	}
+	if s.LineHeight <= 0 {
+		s.LineHeight = defaultLineHeight
+	}
+	return s
+}
+
+// styleTypeface returns the typeface of a style with the text defaults
+// applied, including its weight, slant, decoration and letter spacing
+func styleTypeface(ctx *Context, style model.Style) typeface {
+	face := newTypeface(ctx, style.FontFamily, styleVariant(&style), style.FontSize)
+	face.spacing = ctx.PDF.PointToUnitConvert(style.LetterSpacing)
+	face.underline = style.TextDecoration.Has(model.DecorationUnderline)
+	face.strike = style.TextDecoration.Has(model.DecorationLineThrough)
+	return face
+}
+
+// textLine is one wrapped line of text
+type textLine struct {
+	text string
+	// last marks the final line of a paragraph
+	last bool
+}
+
+// textBlock is text wrapped to a width with a fixed font size
+type textBlock struct {
+	face       typeface
+	lines      []textLine
+	fontHeight float64
+	lineHeight float64
+}
+
+// height returns the vertical space taken by all lines
+func (b textBlock) height() float64 {
+	return linesHeight(len(b.lines), b.fontHeight, b.lineHeight)
+}
+
+// maxLines returns how many lines fit in the given height
+func (b textBlock) maxLines(height float64) int {
+	return fitLines(height, b.fontHeight, b.lineHeight)
+}
+
+// linesHeight returns the vertical space taken by n lines of text
+func linesHeight(n int, fontHeight, lineHeight float64) float64 {
+	if n == 0 {
+		return 0
+	}
+	return fontHeight*(1+descentRatio) + float64(n-1)*lineHeight
+}
+
+// fitLines returns how many lines of text fit in the given height
+func fitLines(height, fontHeight, lineHeight float64) int {
+	first := fontHeight * (1 + descentRatio)
+	if height < first {
+		return 0
+	}
+	return 1 + int((height-first)/lineHeight+1e-9)
+}
+
+// draw writes the lines from the top of bounds, aligning each within the
+// width. Justified lines spread their words across the width, except for
+// the last line of each paragraph.
+func (b textBlock) draw(bounds model.Bounds, alignment model.TextAlignment) {
+	face := b.face
+	face.set()
+	for i, line := range b.lines {
+		textY := bounds.Y + b.fontHeight + float64(i)*b.lineHeight
+
+		textX := bounds.X
+		switch alignment {
+		case model.AlignCenter:
+			textX += (bounds.Width - face.width(line.text)) / 2
+		case model.AlignRight:
+			textX += bounds.Width - face.width(line.text)
+		case model.AlignJustify:
+			if words := strings.Split(line.text, " "); !line.last && len(words) > 1 {
+				gap := face.width(" ") + (bounds.Width-face.width(line.text))/float64(len(words)-1)
+				for _, word := range words {
+					face.write(textX, textY, word)
+					textX += face.width(word) + gap
+				}
+				// Decoration runs under the gaps too
+				face.decorate(bounds.X, textY, bounds.Width)
+				continue
+			}
+		}
+
+		face.text(textX, textY, line.text)
+	}
+}
+
+// layoutText wraps content to the bounds and applies the overflow policy
+func layoutText(ctx *Context, content string, style model.Style, bounds model.Bounds) textBlock {
+	face := styleTypeface(ctx, style)
+	block := wrapBlock(ctx, face, content, style, bounds.Width)
+
+	switch style.Overflow {
+	case model.OverflowShrink:
+		for size := style.FontSize - shrinkStep; block.height() > bounds.Height && size >= minShrinkFontSize; size -= shrinkStep {
+			block = wrapBlock(ctx, face.withSize(size), content, style, bounds.Width)
+		}
+
+	case model.OverflowEllipsis:
+		visible := block.maxLines(bounds.Height)
+		if visible < len(block.lines) {
+			if visible == 0 {
+				visible = 1
+			}
+			block.lines = block.lines[:visible]
+			last := &block.lines[visible-1]
+			last.text = truncate(face.width, last.text, bounds.Width)
+			last.last = true
+		}
+	}
+
+	return block
+}
+
+// wrapBlock wraps content in the given typeface, hyphenating words when
+// the style asks for it and the context has a hyphenator
+func wrapBlock(ctx *Context, face typeface, content string, style model.Style, width float64) textBlock {
+	var hyphenate func(string) []string
+	if style.Hyphenate && ctx.Hyphenator != nil {
+		hyphenate = ctx.Hyphenator.Hyphenate
+	}
+
+	fontHeight := face.height()
+	return textBlock{
+		face:       face,
+		lines:      wrapText(face.width, hyphenate, content, width),
+		fontHeight: fontHeight,
+		lineHeight: fontHeight * style.LineHeight,
+	}
+}
+
+// wrapText breaks text into lines no wider than width. Explicit newlines
+// start new paragraphs and lines break between words. Words that do not
+// fit are hyphenated when hyphenate is set, and words wider than a whole
+// line are broken between characters. A width of zero disables wrapping.
+func wrapText(measure func(string) float64, hyphenate func(string) []string, text string, width float64) []textLine {
+	var lines []textLine
+	for _, paragraph := range strings.Split(text, "\n") {
+		words := strings.Fields(paragraph)
+		if width <= 0 || len(words) == 0 {
+			lines = append(lines, textLine{text: strings.Join(words, " "), last: true})
+			continue
+		}
+
+		current := ""
+		for _, word := range words {
+			for word != "" {
+				if candidate := joinWords(current, word); measure(candidate) <= width {
+					current, word = candidate, ""
+					break
+				}
+
+				if head, tail, ok := splitHyphenated(measure, hyphenate, current, word, width); ok {
+					lines = append(lines, textLine{text: joinWords(current, head)})
+					current, word = "", tail
+					continue
+				}
+
+				if current != "" {
+					lines = append(lines, textLine{text: current})
+					current = ""
+					continue
+				}
+
+				// The word alone is wider than the line
+				pieces := breakWord(measure, word, width)
+				for _, piece := range pieces[:len(pieces)-1] {
+					lines = append(lines, textLine{text: piece})
+				}
+				current, word = pieces[len(pieces)-1], ""
+			}
+		}
+		lines = append(lines, textLine{text: current, last: true})
+	}
+	return lines
+}
+
+// splitHyphenated returns the longest hyphenated start of word that still
+// fits on the current line, and the rest of the word
+func splitHyphenated(measure func(string) float64, hyphenate func(string) []string, current, word string, width float64) (string, string, bool) {
+	if hyphenate == nil {
+		return "", "", false
+	}
+
+	// Punctuation around the word is kept out of the hyphenator
+	core := strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) })
+	if core == "" {
+		return "", "", false
+	}
+	start := strings.Index(word, core)
+	lead, trail := word[:start], word[start+len(core):]
+
+	pieces := hyphenate(core)
+	for k := len(pieces) - 1; k >= 1; k-- {
+		head := lead + strings.Join(pieces[:k], "") + "-"
+		if measure(joinWords(current, head)) <= width {
+			return head, strings.Join(pieces[k:], "") + trail, true
+		}
+	}
+	return "", "", false
+}
+
+// joinWords appends a word to a line with a separating space
+func joinWords(line, word string) string {
+	if line == "" {
+		return word
+	}
+	return line + " " + word
+}
+
+// breakWord splits a word into pieces no wider than width, keeping at
+// least one character per piece
+func breakWord(measure func(string) float64, word string, width float64) []string {
+	if measure(word) <= width {
+		return []string{word}
+	}
+
+	var pieces []string
+	runes := []rune(word)
+	for len(runes) > 0 {
+		n := 1
+		for n < len(runes) && measure(string(runes[:n+1])) <= width {
+			n++
+		}
+		pieces = append(pieces, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return pieces
+}
+
+// truncate shortens text until it fits the width with an ellipsis appended
+func truncate(measure func(string) float64, text string, width float64) string {
+	runes := []rune(strings.TrimRight(text, " "))
+	for len(runes) > 0 && measure(string(runes)+ellipsis) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimRight(string(runes), " ") + ellipsis
+}
@@ -0,0 +1,299 @@
+package render
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/fonts"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// charWidth measures text as one unit per character
+func charWidth(s string) float64 {
+	return float64(len([]rune(s)))
+}
+
+func TestWrapText(t *testing.T) {
+	// hyphenate knows a single word
+	hyphenate := func(word string) []string {
+		if word == "agreement" {
+			return []string{"agree", "ment"}
+		}
+		return []string{word}
+	}
+
+	tests := []struct {
+		name      string
+		text      string
+		width     float64
+		hyphenate func(string) []string
+		want      []textLine
+	}{
+		{
+			name:  "fits on one line",
+			text:  "Lilongwe",
+			width: 10,
+			want:  []textLine{{text: "Lilongwe", last: true}},
+		},
+		{
+			name:  "breaks between words",
+			text:  "Plot 12 Area 47 Lilongwe Malawi",
+			width: 10,
+			want: []textLine{
+				{text: "Plot 12"}, {text: "Area 47"}, {text: "Lilongwe"}, {text: "Malawi", last: true},
+			},
+		},
+		{
+			name:  "keeps paragraphs and collapses spaces",
+			text:  "Dear  customer,\n\nThank you",
+			width: 20,
+			want: []textLine{
+				{text: "Dear customer,", last: true}, {text: "", last: true}, {text: "Thank you", last: true},
+			},
+		},
+		{
+			name:  "breaks long words",
+			text:  "ref ABCDEFGHIJKLM",
+			width: 5,
+			want:  []textLine{{text: "ref"}, {text: "ABCDE"}, {text: "FGHIJ"}, {text: "KLM", last: true}},
+		},
+		{
+			name:      "hyphenates words that do not fit",
+			text:      "the agreement, signed",
+			width:     12,
+			hyphenate: hyphenate,
+			want:      []textLine{{text: "the agree-"}, {text: "ment, signed", last: true}},
+		},
+		{
+			name:  "zero width disables wrapping",
+			text:  "one two three",
+			width: 0,
+			want:  []textLine{{text: "one two three", last: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapText(charWidth, tt.hyphenate, tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapText() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayoutText_Overflow(t *testing.T) {
+	content := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 8)
+	bounds := model.Bounds{Size: model.Size{Width: 60, Height: 15}}
+
+	tests := []struct {
+		name     string
+		overflow model.Overflow
+		check    func(t *testing.T, block textBlock)
+	}{
+		{
+			name:     "visible keeps every line",
+			overflow: model.OverflowVisible,
+			check: func(t *testing.T, block textBlock) {
+				if block.height() <= bounds.Height {
+					t.Errorf("height = %.2f, want more than %.2f", block.height(), bounds.Height)
+				}
+			},
+		},
+		{
+			name:     "ellipsis drops lines that do not fit",
+			overflow: model.OverflowEllipsis,
+			check: func(t *testing.T, block textBlock) {
+				if block.height() > bounds.Height {
+					t.Errorf("height = %.2f, want at most %.2f", block.height(), bounds.Height)
+				}
+				if last := block.lines[len(block.lines)-1].text; !strings.HasSuffix(last, ellipsis) {
+					t.Errorf("last line = %q, want ellipsis", last)
+				}
+			},
+		},
+		{
+			name:     "shrink reduces the font size",
+			overflow: model.OverflowShrink,
+			check: func(t *testing.T, block textBlock) {
+				if block.height() > bounds.Height {
+					t.Errorf("height = %.2f, want at most %.2f", block.height(), bounds.Height)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext()
+			style := textStyle(&model.Style{FontSize: 10, Overflow: tt.overflow})
+			block := layoutText(ctx, content, style, bounds)
+			for _, line := range block.lines {
+				if width := ctx.PDF.GetStringWidth(line.text); width > bounds.Width {
+					t.Errorf("line %q is %.2f wide, want at most %.2f", line.text, width, bounds.Width)
+				}
+			}
+			tt.check(t, block)
+		})
+	}
+}
+
+func TestTextRenderer_Measure(t *testing.T) {
+	element := model.Element{
+		ID:      "notes",
+		Type:    model.ElementTypeText,
+		Bounds:  model.Bounds{Size: model.Size{Width: 40, Height: 5}},
+		Content: strings.Repeat("word ", 40),
+		Style:   &model.Style{Padding: &model.Padding{Top: 2, Bottom: 3}},
+	}
+
+	ctx := newTestContext()
+	height, err := (&TextRenderer{}).Measure(ctx, element)
+	if err != nil {
+		t.Fatalf("Measure() error = %v", err)
+	}
+
+	// Without a style the renderer falls back to 12pt text
+	lines := wrapText(ctx.PDF.GetStringWidth, nil, element.Content.(string), 40)
+	fontHeight := ctx.PDF.PointToUnitConvert(defaultFontSize)
+	want := fontHeight*(1+descentRatio) + float64(len(lines)-1)*fontHeight*defaultLineHeight + 5
+	if len(lines) < 2 || height != want {
+		t.Errorf("Measure() = %.2f for %d lines, want %.2f", height, len(lines), want)
+	}
+}
+
+func TestTextRenderer_VerticalAlignment(t *testing.T) {
+	fontHeight := newTestContext().PDF.PointToUnitConvert(defaultFontSize)
+	free := 40 - fontHeight*(1+descentRatio)
+	tests := []struct {
+		alignment model.VerticalAlignment
+		want      float64
+	}{
+		{alignment: "", want: 10 + fontHeight},
+		{alignment: model.AlignTop, want: 10 + fontHeight},
+		{alignment: model.AlignMiddle, want: 10 + fontHeight + free/2},
+		{alignment: model.AlignBottom, want: 10 + fontHeight + free},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.alignment), func(t *testing.T) {
+			ctx := newTestContext()
+			element := model.Element{
+				ID:      "note",
+				Type:    model.ElementTypeText,
+				Bounds:  model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 100, Height: 40}},
+				Content: "Paid",
+				Style:   &model.Style{VerticalAlignment: tt.alignment},
+			}
+			if err := (&TextRenderer{}).Render(ctx, element); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+
+			// Baselines are written in points from the bottom of the page
+			m := regexp.MustCompile(`BT [0-9.]+ ([0-9.]+) Td \(Paid\) Tj`).FindStringSubmatch(output(t, ctx))
+			if m == nil {
+				t.Fatal("document does not contain the text")
+			}
+			y, _ := strconv.ParseFloat(m[1], 64)
+			if got := 297 - y/ctx.PDF.GetConversionRatio(); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("baseline at y=%.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextBlock_DrawJustify(t *testing.T) {
+	ctx := newTestContext()
+	pdf := ctx.PDF
+	face := newTypeface(ctx, "Arial", fonts.Regular, 12)
+	fontHeight := face.height()
+	block := textBlock{
+		face:       face,
+		lines:      []textLine{{text: "Terms of sale"}, {text: "apply", last: true}},
+		fontHeight: fontHeight,
+		lineHeight: fontHeight * defaultLineHeight,
+	}
+	bounds := model.Bounds{Position: model.Position{X: 20, Y: 20}, Size: model.Size{Width: 100, Height: 20}}
+	block.draw(bounds, model.AlignJustify)
+
+	// Text positions are written in points
+	k := pdf.GetConversionRatio()
+	positions := make(map[string]float64)
+	for _, m := range regexp.MustCompile(`BT ([0-9.]+) [0-9.]+ Td \((\w+)\) Tj`).FindAllStringSubmatch(output(t, ctx), -1) {
+		x, _ := strconv.ParseFloat(m[1], 64)
+		positions[m[2]] = x / k
+	}
+
+	tests := []struct {
+		word string
+		want float64
+	}{
+		{word: "Terms", want: 20},
+		{word: "sale", want: 120 - pdf.GetStringWidth("sale")},
+		{word: "apply", want: 20},
+	}
+	for _, tt := range tests {
+		got, ok := positions[tt.word]
+		if !ok || math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("%q drawn at x=%.2f, want %.2f", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestTextRenderer_FontStyle(t *testing.T) {
+	tests := []struct {
+		name      string
+		style     model.Style
+		wantFont  string
+		wantLines int
+	}{
+		{name: "regular", wantFont: "Helvetica"},
+		{name: "bold", style: model.Style{FontWeight: model.FontWeightBold}, wantFont: "Helvetica-Bold"},
+		{name: "numeric weight", style: model.Style{FontWeight: "700"}, wantFont: "Helvetica-Bold"},
+		{name: "italic", style: model.Style{FontStyle: model.FontStyleItalic}, wantFont: "Helvetica-Oblique"},
+		{name: "bold italic", style: model.Style{FontWeight: model.FontWeightBold, FontStyle: model.FontStyleItalic}, wantFont: "Helvetica-BoldOblique"},
+		{name: "underline", style: model.Style{TextDecoration: model.DecorationUnderline}, wantFont: "Helvetica", wantLines: 1},
+		{name: "underline and strike", style: model.Style{TextDecoration: "underline line-through"}, wantFont: "Helvetica", wantLines: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext()
+			element := model.Element{
+				ID:      "total",
+				Type:    model.ElementTypeText,
+				Bounds:  model.Bounds{Position: model.Position{X: 10, Y: 10}, Size: model.Size{Width: 100, Height: 10}},
+				Content: "Total due",
+				Style:   &tt.style,
+			}
+			if err := (&TextRenderer{}).Render(ctx, element); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+
+			out := output(t, ctx)
+			if !strings.Contains(out, "/BaseFont /"+tt.wantFont+"\n") {
+				t.Errorf("document does not use font %s", tt.wantFont)
+			}
+			if got := strings.Count(out, " re f"); got != tt.wantLines {
+				t.Errorf("drew %d decoration lines, want %d", got, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestTypeface_LetterSpacing(t *testing.T) {
+	ctx := newTestContext()
+	face := newTypeface(ctx, "Arial", fonts.Regular, 12)
+	spaced := face
+	spaced.spacing = 1
+
+	if got, want := spaced.width("Zoë"), face.width("Zoë")+3; math.Abs(got-want) > 1e-9 {
+		t.Errorf("width() = %.3f, want %.3f", got, want)
+	}
+	if end := spaced.write(10, 20, "Zoë"); math.Abs(end-10-spaced.width("Zoë")) > 1e-9 {
+		t.Errorf("write() ended at %.3f, want %.3f", end, 10+spaced.width("Zoë"))
+	}
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/generator/internal/binding"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/generator/internal/render"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// dateLayout formats the {date} token
+const dateLayout = "2006-01-02"
+
+// renderRegions draws the header and footer of a page. Region content is
+// bound to the data like any other element, after which the {page},
+// {pages} and {date} tokens are replaced.
+func (g *Generator) renderRegions(ctx *render.Context, scope *binding.Scope, page, pages int) error {
+	tokens := strings.NewReplacer(
+		"{page}", strconv.Itoa(page),
+		"{pages}", strconv.Itoa(pages),
+		"{date}", g.now().Format(dateLayout),
+	)
+	width := ctx.PageSize.Width - g.margins.Left - g.margins.Right
+
+	if header := g.template.Header.ForPage(page); header != nil {
+		origin := model.Position{X: g.margins.Left, Y: g.margins.Top}
+		if err := g.renderRegion(ctx, header, origin, width, scope, tokens); err != nil {
+			return fmt.Errorf("failed to render header: %w", err)
+		}
+	}
+	if footer := g.template.Footer.ForPage(page); footer != nil {
+		origin := model.Position{X: g.margins.Left, Y: ctx.PageSize.Height - g.margins.Bottom - footer.Height}
+		if err := g.renderRegion(ctx, footer, origin, width, scope, tokens); err != nil {
+			return fmt.Errorf("failed to render footer: %w", err)
+		}
+	}
+	return nil
+}
+
+// renderRegion resolves and draws the elements of a region placed at origin
+func (g *Generator) renderRegion(ctx *render.Context, region *model.Region, origin model.Position, width float64, scope *binding.Scope, tokens *strings.Replacer) error {
+	elements, err := binding.ResolveElements(region.Elements, scope)
+	if err != nil {
+		return fmt.Errorf("data binding failed: %w", err)
+	}
+
+	for i := range elements {
+		bounds := &elements[i].Bounds
+		if bounds.Width == 0 {
+			bounds.Width = width - bounds.X
+		}
+		if bounds.Height == 0 {
+			bounds.Height = region.Height - bounds.Y
+		}
+		bounds.X += origin.X
+		bounds.Y += origin.Y
+		elements[i].Content = replaceTokens(elements[i].Content, tokens)
+	}
+
+	return g.renderElements(ctx, elements)
+}
+
+// replaceTokens substitutes page tokens in strings, including table cells
+func replaceTokens(content interface{}, tokens *strings.Replacer) interface{} {
+	switch v := content.(type) {
+	case string:
+		return tokens.Replace(v)
+	case []interface{}:
+		replaced := make([]interface{}, len(v))
+		for i, item := range v {
+			replaced[i] = replaceTokens(item, tokens)
+		}
+		return replaced
+	case map[string]interface{}:
+		replaced := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			replaced[key] = replaceTokens(item, tokens)
+		}
+		return replaced
+	}
+	return content
+}
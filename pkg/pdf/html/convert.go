@@ -0,0 +1,767 @@
+// Package html converts a restricted subset of HTML with inline CSS into
+// template elements, so documents written as email-style HTML render
+// through the same layout and render pipeline as JSON templates.
+//
+// Supported elements are div, p, h1 to h6, blockquote, pre, hr, ul, ol and li,
+// table rows and cells, img, and the inline elements span, strong, b, em,
+// i, u, s, del, code, a and br. Formatting comes from style attributes;
+// style sheets, classes and floats are ignored. As the layout stacks
+// elements vertically, horizontal margins become padding and a styled div
+// repeats its background and side borders on each element inside it.
+//
+// Data bindings such as {{ customer.name }} pass through unchanged, and the
+// attributes data-repeat, data-as and data-if on a block or table row map
+// to the element's repeat, as and if fields.
+package html
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"  // decode GIF sizes
+	_ "image/jpeg" // decode JPEG sizes
+	_ "image/png"  // decode PNG sizes
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/assets"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/errors"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/fonts"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+	_ "golang.org/x/image/webp" // decode WebP sizes
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Options configures a conversion
+type Options struct {
+	// Width is the content width in millimetres, 190 by default to fit A4
+	// inside the generator's 10mm margins
+	Width float64
+	// FontFamily and FontSize are the body text style, 12pt Arial by default
+	FontFamily string
+	FontSize   float64
+	// Assets opens img sources to read their size, as the generator's asset
+	// resolver does to draw them; nil reads local files
+	Assets assets.Resolver
+	// Fonts holds the TrueType families font-family may name, usually the
+	// generator's registry. Other families use the standard PDF fonts, and
+	// lists naming none of them use FontFamily.
+	Fonts *fonts.Registry
+}
+
+const (
+	defaultWidth      = 190.0
+	defaultFontFamily = "Arial"
+	defaultFontSize   = 12.0
+	// listIndent is the browser default padding of lists and blockquotes
+	listIndent = 40 * mmPerPixel
+)
+
+// headingSizes are the h1 to h6 font sizes relative to the parent, as in browsers
+var headingSizes = map[atom.Atom]float64{
+	atom.H1: 2, atom.H2: 1.5, atom.H3: 1.17, atom.H4: 1, atom.H5: 0.83, atom.H6: 0.67,
+}
+
+// blockMargins are the browser default vertical margins in em
+var blockMargins = map[atom.Atom]float64{
+	atom.P: 1, atom.Blockquote: 1, atom.Pre: 1, atom.Ul: 1, atom.Ol: 1, atom.Hr: 0.5,
+	atom.H1: 0.67, atom.H2: 0.83, atom.H3: 1, atom.H4: 1.33, atom.H5: 1.67, atom.H6: 2.33,
+}
+
+// Convert reads an HTML document or fragment and returns the elements of
+// its body in document order
+func Convert(r io.Reader, opts Options) ([]model.Element, error) {
+	if opts.Width <= 0 {
+		opts.Width = defaultWidth
+	}
+	if opts.FontFamily == "" {
+		opts.FontFamily = defaultFontFamily
+	}
+	if opts.FontSize <= 0 {
+		opts.FontSize = defaultFontSize
+	}
+
+	doc, err := xhtml.Parse(r)
+	if err != nil {
+		return nil, errors.NewPDFError(errors.ErrInvalidTemplate, "failed to parse HTML", err)
+	}
+	body := find(doc, atom.Body)
+	if body == nil {
+		return nil, nil
+	}
+
+	c := &converter{opts: opts, counts: make(map[string]int), spacers: make(map[string]bool)}
+	root := state{
+		style: model.Style{FontFamily: opts.FontFamily, FontSize: opts.FontSize, LineHeight: 1.2},
+		tag:   "text",
+	}
+	elements, err := c.blocks(body, root)
+	if err != nil {
+		return nil, errors.NewPDFError(errors.ErrInvalidTemplate, "failed to convert HTML", err)
+	}
+	return elements, nil
+}
+
+// state is the formatting a node inherits from its ancestors
+type state struct {
+	// style holds the inherited text properties
+	style model.Style
+	// tag names the elements created for the nearest block
+	tag  string
+	code bool
+	// pre keeps the line breaks of text
+	pre  bool
+	link string
+	// marker is the pending list marker for the first paragraph of an item
+	marker *string
+	// depth is the list nesting level
+	depth int
+}
+
+type converter struct {
+	opts    Options
+	counts  map[string]int
+	spacers map[string]bool
+}
+
+// id returns a unique element ID with the given prefix
+func (c *converter) id(prefix string) string {
+	c.counts[prefix]++
+	return fmt.Sprintf("%s-%d", prefix, c.counts[prefix])
+}
+
+// blocks converts the children of n, gathering inline content between
+// block children into paragraphs
+func (c *converter) blocks(n *xhtml.Node, st state) ([]model.Element, error) {
+	var out []model.Element
+	p := &paragraph{space: true}
+	flush := func() {
+		if !p.empty() {
+			out = c.add(out, c.text(p, st))
+		}
+		p = &paragraph{space: true}
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == xhtml.ElementNode && isBlock(child.DataAtom) {
+			flush()
+			elements, err := c.block(child, st)
+			if err != nil {
+				return nil, err
+			}
+			out = c.add(out, elements...)
+			continue
+		}
+		c.inline(child, st, p)
+	}
+	flush()
+	return out, nil
+}
+
+// block converts a block element with its box and control attributes
+func (c *converter) block(n *xhtml.Node, parent state) ([]model.Element, error) {
+	st, props := c.inherit(n, parent)
+	st.tag = n.Data
+
+	var elements []model.Element
+	var err error
+	switch n.DataAtom {
+	case atom.Head, atom.Script, atom.Style, atom.Title, atom.Template:
+		return nil, nil
+	case atom.Table:
+		elements, err = c.table(n, st, props)
+	case atom.Img:
+		elements, err = c.image(n, st, props)
+	case atom.Hr:
+		elements = []model.Element{c.rule(props)}
+	case atom.Ul, atom.Ol:
+		elements, err = c.list(n, st)
+	default:
+		elements, err = c.blocks(n, st)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c.control(n, c.box(n, elements, st, props)), nil
+}
+
+// inherit applies the tag defaults and the style attribute of n to the
+// inherited formatting
+func (c *converter) inherit(n *xhtml.Node, parent state) (state, map[string]string) {
+	st := parent
+	s := &st.style
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		s.FontSize *= headingSizes[n.DataAtom]
+		s.FontWeight = model.FontWeightBold
+	case atom.Strong, atom.B, atom.Th:
+		s.FontWeight = model.FontWeightBold
+	case atom.Em, atom.I, atom.Cite:
+		s.FontStyle = model.FontStyleItalic
+	case atom.U, atom.Ins:
+		s.TextDecoration = addDecoration(s.TextDecoration, model.DecorationUnderline)
+	case atom.S, atom.Del, atom.Strike:
+		s.TextDecoration = addDecoration(s.TextDecoration, model.DecorationLineThrough)
+	case atom.Code, atom.Kbd, atom.Samp:
+		st.code = true
+	case atom.Pre:
+		s.FontFamily = "Courier"
+		st.pre = true
+	case atom.A:
+		if href := attr(n, "href"); href != "" {
+			st.link = href
+		}
+	}
+	if align := attr(n, "align"); align != "" && n.DataAtom != atom.Img {
+		s.Alignment = model.TextAlignment(strings.ToLower(align))
+	}
+
+	props := declarations(attr(n, "style"))
+	if value, ok := props["font-size"]; ok {
+		if size, ok := fontSize(value, s.FontSize); ok {
+			s.FontSize = size
+		}
+	}
+	if value, ok := props["font-family"]; ok {
+		family, ok := fontFamily(value, c.opts.Fonts)
+		if !ok {
+			family = c.opts.FontFamily
+		}
+		s.FontFamily = family
+	}
+	if value, ok := props["color"]; ok {
+		if value, ok := cssColor(value); ok {
+			s.FontColor = value
+		}
+	}
+	if value, ok := props["font-weight"]; ok {
+		s.FontWeight = model.FontWeight(strings.ToLower(value))
+	}
+	if value, ok := props["font-style"]; ok {
+		s.FontStyle = model.FontStyle(strings.ToLower(value))
+	}
+	if value, ok := props["text-decoration"]; ok {
+		switch value = strings.ToLower(value); {
+		case strings.Contains(value, "none"):
+			s.TextDecoration = ""
+		default:
+			for _, line := range []model.TextDecoration{model.DecorationUnderline, model.DecorationLineThrough} {
+				if strings.Contains(value, string(line)) {
+					s.TextDecoration = addDecoration(s.TextDecoration, line)
+				}
+			}
+		}
+	}
+	if value, ok := props["text-align"]; ok {
+		s.Alignment = model.TextAlignment(strings.ToLower(value))
+	}
+	if value, ok := props["line-height"]; ok {
+		if height, ok := lineHeight(value, s.FontSize); ok {
+			s.LineHeight = height
+		}
+	}
+	if value, ok := props["letter-spacing"]; ok {
+		if mm, ok := length(value, s.FontSize, 0); ok {
+			s.LetterSpacing = mm / mmPerPoint
+		}
+	}
+	return st, props
+}
+
+// inline adds the text of an inline node to a paragraph
+func (c *converter) inline(n *xhtml.Node, parent state, p *paragraph) {
+	switch n.Type {
+	case xhtml.TextNode:
+		p.add(n.Data, parent, parent.pre)
+	case xhtml.ElementNode:
+		if n.DataAtom == atom.Br {
+			p.add("\n", parent, true)
+			return
+		}
+		st, _ := c.inherit(n, parent)
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			c.inline(child, st, p)
+		}
+	}
+}
+
+// text creates a text element for a paragraph in the style of its block
+func (c *converter) text(p *paragraph, st state) model.Element {
+	if st.marker != nil && *st.marker != "" {
+		p.runs = append([]model.TextRun{{Text: *st.marker}}, p.runs...)
+		*st.marker = ""
+	}
+
+	style := st.style
+	style.Overflow = model.OverflowGrow
+	return model.Element{
+		ID:      c.id(st.tag),
+		Type:    model.ElementTypeText,
+		Bounds:  model.Bounds{Size: model.Size{Width: c.opts.Width}},
+		Content: p.content(st.style),
+		Style:   &style,
+	}
+}
+
+// list converts the items of ul and ol elements, prefixing the first
+// paragraph of each with its bullet or number
+func (c *converter) list(n *xhtml.Node, st state) ([]model.Element, error) {
+	number := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		number = start
+	}
+	bullet := "•"
+	if st.depth > 0 {
+		bullet = "–"
+	}
+	st.depth++
+
+	var out []model.Element
+	for item := n.FirstChild; item != nil; item = item.NextSibling {
+		if item.Type != xhtml.ElementNode || item.DataAtom != atom.Li {
+			continue
+		}
+
+		marker := bullet + " "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		itemState := st
+		itemState.marker = &marker
+
+		elements, err := c.block(item, itemState)
+		if err != nil {
+			return nil, err
+		}
+		out = c.add(out, elements...)
+	}
+	return out, nil
+}
+
+// table converts a table to a table element with one row per tr. Cells
+// hold their text and spans; inline formatting inside cells is dropped.
+func (c *converter) table(n *xhtml.Node, st state, props map[string]string) ([]model.Element, error) {
+	var rows []interface{}
+	headerRows := 0
+	var walk func(*xhtml.Node)
+	walk = func(parent *xhtml.Node) {
+		for child := parent.FirstChild; child != nil; child = child.NextSibling {
+			switch child.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				walk(child)
+			case atom.Tr:
+				// Leading rows of th cells are the header
+				if headerRows == len(rows) && isHeaderRow(child) {
+					headerRows++
+				}
+				rows = append(rows, c.row(child))
+			}
+		}
+	}
+	walk(n)
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	style := st.style
+	style.Overflow = model.OverflowGrow
+	// Rows keep the table line height unless the table sets its own
+	if _, ok := props["line-height"]; !ok {
+		style.LineHeight = 0
+	}
+	if style.Alignment == "" {
+		style.Alignment = model.AlignLeft
+	}
+	if width, err := strconv.Atoi(attr(n, "border")); err == nil && width > 0 {
+		style.Border = &model.Border{Width: float64(width) * mmPerPixel, Style: model.BorderSolid}
+	}
+	if b := border(props["border"], style.FontSize); b != nil {
+		style.Border = b
+	}
+	// The table border outlines every cell rather than the box around the table
+	delete(props, "border")
+
+	element := model.Element{
+		ID:      c.id("table"),
+		Type:    model.ElementTypeTable,
+		Bounds:  model.Bounds{Size: model.Size{Width: c.width(n, props, style.FontSize)}},
+		Content: rows,
+		Style:   &style,
+	}
+	if headerRows > 0 {
+		metadata, err := json.Marshal(model.TableOptions{HeaderRows: headerRows})
+		if err != nil {
+			return nil, err
+		}
+		element.Metadata = metadata
+	}
+	return []model.Element{element}, nil
+}
+
+// isHeaderRow reports whether all cells of a table row are th
+func isHeaderRow(tr *xhtml.Node) bool {
+	cells := 0
+	for cell := tr.FirstChild; cell != nil; cell = cell.NextSibling {
+		switch cell.DataAtom {
+		case atom.Th:
+			cells++
+		case atom.Td:
+			return false
+		}
+	}
+	return cells > 0
+}
+
+// row returns the cell texts of a table row, or a row template when the
+// row carries data-repeat or data-if. Cells with colspan or rowspan become
+// cell objects.
+func (c *converter) row(tr *xhtml.Node) interface{} {
+	var cells []interface{}
+	for cell := tr.FirstChild; cell != nil; cell = cell.NextSibling {
+		if cell.DataAtom != atom.Td && cell.DataAtom != atom.Th {
+			continue
+		}
+		text := collapse(textOf(cell))
+		object := map[string]interface{}{"value": text}
+		for name, key := range map[string]string{"colspan": "colSpan", "rowspan": "rowSpan"} {
+			if n, err := strconv.Atoi(attr(cell, name)); err == nil && n > 1 {
+				object[key] = float64(n)
+			}
+		}
+		if len(object) > 1 {
+			cells = append(cells, object)
+		} else {
+			cells = append(cells, text)
+		}
+	}
+
+	repeat, condition := attr(tr, "data-repeat"), attr(tr, "data-if")
+	if repeat == "" && condition == "" {
+		return cells
+	}
+	row := map[string]interface{}{"cells": cells}
+	if repeat != "" {
+		row["repeat"] = repeat
+		if as := attr(tr, "data-as"); as != "" {
+			row["as"] = as
+		}
+	}
+	if condition != "" {
+		row["if"] = condition
+	}
+	return row
+}
+
+// image converts an img element. A missing width or height is taken from
+// the image file, keeping its aspect ratio, and object-fit and opacity
+// become image options.
+func (c *converter) image(n *xhtml.Node, st state, props map[string]string) ([]model.Element, error) {
+	src := attr(n, "src")
+	if src == "" {
+		return nil, nil
+	}
+
+	size := st.style.FontSize
+	width, hasWidth := dimension(n, props, "width", size, c.opts.Width)
+	height, hasHeight := dimension(n, props, "height", size, 0)
+	if !hasWidth || !hasHeight {
+		natural, err := imageSize(c.opts.Assets, src)
+		if err != nil {
+			return nil, fmt.Errorf("image %s needs a width and height: %w", src, err)
+		}
+		switch {
+		case hasWidth:
+			height = width * natural.Height / natural.Width
+		case hasHeight:
+			width = height * natural.Width / natural.Height
+		default:
+			width, height = natural.Width, natural.Height
+		}
+	}
+	if width > c.opts.Width {
+		width, height = c.opts.Width, height*c.opts.Width/width
+	}
+
+	element := model.Element{
+		ID:      c.id("image"),
+		Type:    model.ElementTypeImage,
+		Bounds:  model.Bounds{Size: model.Size{Width: width, Height: height}},
+		Content: src,
+	}
+	var opts model.ImageOptions
+	switch fit := model.ImageFit(strings.ToLower(props["object-fit"])); fit {
+	case model.ImageFitContain, model.ImageFitCover, model.ImageFitNone:
+		opts.Fit = fit
+	}
+	if opacity, err := strconv.ParseFloat(props["opacity"], 64); err == nil && opacity >= 0 && opacity < 1 {
+		opts.Opacity = &opacity
+	}
+	if opts != (model.ImageOptions{}) {
+		metadata, err := json.Marshal(opts)
+		if err != nil {
+			return nil, err
+		}
+		element.Metadata = metadata
+	}
+	return []model.Element{element}, nil
+}
+
+// rule converts hr to an empty element with a top border
+func (c *converter) rule(props map[string]string) model.Element {
+	line := &model.Border{Width: mmPerPixel, Color: "#cccccc", Style: model.BorderSolid}
+	if b := border(props["border-top"], defaultFontSize); b != nil {
+		line = b
+	}
+	delete(props, "border-top")
+
+	return model.Element{
+		ID:      c.id("hr"),
+		Type:    model.ElementTypeText,
+		Bounds:  model.Bounds{Size: model.Size{Width: c.opts.Width, Height: line.Width}},
+		Content: "",
+		Style:   &model.Style{BorderTop: line},
+	}
+}
+
+// box applies the margins, padding, background and borders of a block to
+// the elements inside it
+func (c *converter) box(n *xhtml.Node, elements []model.Element, st state, props map[string]string) []model.Element {
+	size := st.style.FontSize
+	top, bottom := margins(props, size, c.opts.Width, blockMargins[n.DataAtom]*size*mmPerPoint, blockMargins[n.DataAtom]*size*mmPerPoint)
+
+	pad := padding(props, size, c.opts.Width)
+	if n.DataAtom == atom.Ul || n.DataAtom == atom.Ol || n.DataAtom == atom.Blockquote {
+		if pad == nil {
+			pad = &model.Padding{}
+		}
+		if _, ok := props["padding-left"]; !ok && props["padding"] == "" {
+			pad.Left = listIndent
+		}
+	}
+
+	background := props["background-color"]
+	if background == "" {
+		background = backgroundColor(props["background"])
+	}
+	background, _ = cssColor(background)
+	sides := map[string]*model.Border{}
+	for _, side := range []string{"top", "right", "bottom", "left"} {
+		if b := border(props["border-"+side], size); b != nil {
+			sides[side] = b
+		} else if b := border(props["border"], size); b != nil {
+			sides[side] = b
+		}
+	}
+
+	if pad != nil || background != "" || len(sides) > 0 {
+		if pad == nil {
+			pad = &model.Padding{}
+		}
+		if len(elements) == 0 {
+			height, _ := length(props["height"], size, 0)
+			elements = []model.Element{c.spacer(height)}
+		}
+
+		var boxed []model.Element
+		boxed = c.add(boxed, c.spacer(pad.Top))
+		boxed = c.add(boxed, elements...)
+		boxed = c.add(boxed, c.spacer(pad.Bottom))
+		elements = boxed
+
+		for i := range elements {
+			element := &elements[i]
+			style := model.Style{}
+			if element.Style != nil {
+				style = *element.Style
+			}
+			if style.Background == "" {
+				style.Background = background
+			}
+			inner := model.Padding{}
+			if style.Padding != nil {
+				inner = *style.Padding
+			}
+			inner.Left += pad.Left
+			inner.Right += pad.Right
+			style.Padding = &inner
+			if element.Type == model.ElementTypeImage {
+				element.Bounds.Width += pad.Left + pad.Right
+			}
+
+			setSide(&style.BorderLeft, sides["left"])
+			setSide(&style.BorderRight, sides["right"])
+			if i == 0 {
+				setSide(&style.BorderTop, sides["top"])
+			}
+			if i == len(elements)-1 {
+				setSide(&style.BorderBottom, sides["bottom"])
+			}
+			element.Style = &style
+		}
+	}
+
+	var out []model.Element
+	out = c.add(out, c.spacer(top))
+	out = c.add(out, elements...)
+	return c.add(out, c.spacer(bottom))
+}
+
+// control wraps the elements of a block carrying data-repeat or data-if in a group
+func (c *converter) control(n *xhtml.Node, elements []model.Element) []model.Element {
+	repeat, condition := attr(n, "data-repeat"), attr(n, "data-if")
+	if repeat == "" && condition == "" {
+		return elements
+	}
+	return []model.Element{{
+		ID:       c.id("group"),
+		Type:     model.ElementTypeGroup,
+		Repeat:   repeat,
+		RepeatAs: attr(n, "data-as"),
+		If:       condition,
+		Children: elements,
+	}}
+}
+
+// spacer returns an empty element that takes vertical space
+func (c *converter) spacer(height float64) model.Element {
+	id := c.id("space")
+	c.spacers[id] = true
+	return model.Element{
+		ID:      id,
+		Type:    model.ElementTypeText,
+		Bounds:  model.Bounds{Size: model.Size{Width: c.opts.Width, Height: height}},
+		Content: "",
+	}
+}
+
+// add appends elements, collapsing adjacent spacers of the same style into
+// the taller one like CSS margins, and dropping empty spacers
+func (c *converter) add(out []model.Element, elements ...model.Element) []model.Element {
+	for _, element := range elements {
+		if !c.spacers[element.ID] {
+			out = append(out, element)
+			continue
+		}
+		if element.Bounds.Height <= 0 {
+			continue
+		}
+		if n := len(out); n > 0 && c.spacers[out[n-1].ID] && reflect.DeepEqual(out[n-1].Style, element.Style) {
+			if element.Bounds.Height > out[n-1].Bounds.Height {
+				out[n-1].Bounds.Height = element.Bounds.Height
+			}
+			continue
+		}
+		out = append(out, element)
+	}
+	return out
+}
+
+// width returns the CSS or attribute width of n, limited to the content width
+func (c *converter) width(n *xhtml.Node, props map[string]string, size float64) float64 {
+	if width, ok := dimension(n, props, "width", size, c.opts.Width); ok && width < c.opts.Width {
+		return width
+	}
+	return c.opts.Width
+}
+
+// setSide sets a border side unless the element has its own
+func setSide(side **model.Border, b *model.Border) {
+	if b != nil && *side == nil {
+		*side = b
+	}
+}
+
+// dimension reads a width or height from the style or the HTML attribute
+func dimension(n *xhtml.Node, props map[string]string, name string, size, base float64) (float64, bool) {
+	value, ok := props[name]
+	if !ok {
+		value = attr(n, name)
+	}
+	mm, ok := length(value, size, base)
+	return mm, ok && mm > 0
+}
+
+// imageSize returns the size of an image source in millimetres at 96 dpi
+func imageSize(resolver assets.Resolver, src string) (model.Size, error) {
+	file, err := assets.Open(resolver, src)
+	if err != nil {
+		return model.Size{}, err
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return model.Size{}, err
+	}
+	if config.Width == 0 || config.Height == 0 {
+		return model.Size{}, fmt.Errorf("image has no size")
+	}
+	return model.Size{Width: float64(config.Width) * mmPerPixel, Height: float64(config.Height) * mmPerPixel}, nil
+}
+
+// isBlock reports whether an element starts a new block in the flow.
+// Images are blocks here, as the layout cannot place them inside text.
+func isBlock(a atom.Atom) bool {
+	switch a {
+	case atom.Address, atom.Article, atom.Aside, atom.Blockquote, atom.Center, atom.Div, atom.Dl,
+		atom.Fieldset, atom.Figure, atom.Footer, atom.Form, atom.H1, atom.H2, atom.H3, atom.H4,
+		atom.H5, atom.H6, atom.Header, atom.Hr, atom.Img, atom.Li, atom.Main, atom.Nav, atom.Ol,
+		atom.P, atom.Pre, atom.Section, atom.Table, atom.Ul, atom.Head, atom.Script, atom.Style,
+		atom.Title, atom.Template:
+		return true
+	}
+	return false
+}
+
+func addDecoration(d, line model.TextDecoration) model.TextDecoration {
+	if d.Has(line) {
+		return d
+	}
+	return model.TextDecoration(strings.TrimSpace(string(d) + " " + string(line)))
+}
+
+func attr(n *xhtml.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// find returns the first element of a type in document order
+func find(n *xhtml.Node, a atom.Atom) *xhtml.Node {
+	if n.Type == xhtml.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := find(child, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// textOf returns the text inside n, with br as a space
+func textOf(n *xhtml.Node) string {
+	var text strings.Builder
+	var walk func(*xhtml.Node)
+	walk = func(n *xhtml.Node) {
+		switch {
+		case n.Type == xhtml.TextNode:
+			text.WriteString(n.Data)
+		case n.DataAtom == atom.Br:
+			text.WriteByte(' ')
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return text.String()
+}
@@ -0,0 +1,447 @@
+package html
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/assets"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/fonts"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/generator"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// convert converts source with the default options
+func convert(t *testing.T, source string) []model.Element {
+	t.Helper()
+	elements, err := Convert(strings.NewReader(source), Options{})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	return elements
+}
+
+// content returns the elements that are not spacers
+func content(elements []model.Element) []model.Element {
+	var out []model.Element
+	for _, element := range elements {
+		if !strings.HasPrefix(element.ID, "space-") {
+			out = append(out, element)
+		}
+	}
+	return out
+}
+
+func run(text string, flags ...string) map[string]interface{} {
+	fields := map[string]interface{}{"text": text}
+	for _, flag := range flags {
+		key, value, ok := strings.Cut(flag, "=")
+		if ok {
+			fields[key] = value
+		} else {
+			fields[key] = true
+		}
+	}
+	return fields
+}
+
+func TestConvert_Text(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []interface{}
+	}{
+		{
+			name:   "whitespace",
+			source: "<p>  Dear\n   customer,  </p>",
+			want:   []interface{}{run("Dear customer,")},
+		},
+		{
+			name:   "inline formatting",
+			source: `<p>Hi <strong>{{ customer.name }}</strong>, see <a href="https://example.com">our <em>terms</em></a>.</p>`,
+			want: []interface{}{
+				run("Hi "),
+				run("{{ customer.name }}", "bold"),
+				run(", see "),
+				run("our ", "link=https://example.com"),
+				run("terms", "italic", "link=https://example.com"),
+				run("."),
+			},
+		},
+		{
+			name:   "line break",
+			source: "<p>Line one<br>\n  line two</p>",
+			want:   []interface{}{run("Line one\nline two")},
+		},
+		{
+			name:   "block formatting left out of runs",
+			source: `<h2>Order <b>{{ order.id }}</b> <span style="color: #c00">late</span></h2>`,
+			want:   []interface{}{run("Order {{ order.id }} "), run("late", "color=#c00")},
+		},
+		{
+			name:   "code and decorations",
+			source: "<div>Use <code>INV-7</code>, <u>not</u> <del>INV-6</del></div>",
+			want: []interface{}{
+				run("Use "), run("INV-7", "code"), run(", "), run("not", "underline"), run(" "), run("INV-6", "strike"),
+			},
+		},
+		{
+			name:   "preformatted",
+			source: "<pre>total:\n  42</pre>",
+			want:   []interface{}{run("total:\n  42")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elements := content(convert(t, tt.source))
+			if len(elements) != 1 {
+				t.Fatalf("Convert() = %d elements, want 1", len(elements))
+			}
+			if got := elements[0].Content; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("content = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert_Style(t *testing.T) {
+	elements := content(convert(t, `
+		<h1>Invoice</h1>
+		<p style="font-size: 9pt; font-family: 'Times New Roman', serif; color: gray;
+			text-align: right; line-height: 1.5; font-style: italic">Fine print</p>`))
+	if len(elements) != 2 {
+		t.Fatalf("Convert() = %d elements, want 2", len(elements))
+	}
+
+	heading := elements[0].Style
+	if heading.FontSize != 24 || !heading.FontWeight.Bold() || heading.Overflow != model.OverflowGrow {
+		t.Errorf("heading style = %+v, want 24pt bold growing text", heading)
+	}
+
+	want := model.Style{
+		FontFamily: "Times",
+		FontSize:   9,
+		FontColor:  "gray",
+		Alignment:  model.AlignRight,
+		LineHeight: 1.5,
+		Overflow:   model.OverflowGrow,
+		FontStyle:  model.FontStyleItalic,
+	}
+	if got := *elements[1].Style; !reflect.DeepEqual(got, want) {
+		t.Errorf("paragraph style = %+v, want %+v", got, want)
+	}
+}
+
+func TestConvert_Margins(t *testing.T) {
+	elements := convert(t, `<p>One</p><p style="margin-top: 10mm">Two</p><p style="margin: 0">Three</p>`)
+
+	var kinds []string
+	for _, element := range elements {
+		kind := element.ID
+		if strings.HasPrefix(kind, "space-") {
+			kind = "space"
+		}
+		kinds = append(kinds, kind)
+	}
+	want := []string{"space", "p-1", "space", "p-2", "space", "p-3"}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("elements = %v, want %v", kinds, want)
+	}
+
+	// Adjacent margins collapse into the larger one
+	if got := elements[2].Bounds.Height; got != 10 {
+		t.Errorf("margin between paragraphs = %v, want 10", got)
+	}
+}
+
+func TestConvert_Lists(t *testing.T) {
+	elements := content(convert(t, `
+		<ul>
+			<li>Apples</li>
+			<li><p>Pears</p><ol start="3"><li>Conference</li></ol></li>
+		</ul>`))
+
+	want := []struct {
+		marker string
+		indent float64
+	}{
+		{"• ", listIndent},
+		{"• ", listIndent},
+		{"3. ", 2 * listIndent},
+	}
+	if len(elements) != len(want) {
+		t.Fatalf("Convert() = %d elements, want %d", len(elements), len(want))
+	}
+	for i, w := range want {
+		runs := elements[i].Content.([]interface{})
+		if got := runs[0].(map[string]interface{})["text"]; got != w.marker {
+			t.Errorf("item %d marker = %q, want %q", i, got, w.marker)
+		}
+		if got := elements[i].Style.Padding.Left; got != w.indent {
+			t.Errorf("item %d indent = %v, want %v", i, got, w.indent)
+		}
+	}
+}
+
+func TestConvert_Box(t *testing.T) {
+	elements := convert(t, `<div style="background: #f5f5f5 no-repeat; padding: 2mm 4mm; border: 1px solid #ccc; margin: 0">
+		<p style="margin: 0">Paid in full</p>
+		<p style="margin: 0">Thank you</p>
+	</div>`)
+
+	// Top padding, two paragraphs, bottom padding
+	if len(elements) != 4 {
+		t.Fatalf("Convert() = %d elements, want 4", len(elements))
+	}
+	for i, element := range elements {
+		style := element.Style
+		if style.Background != "#f5f5f5" || style.Padding.Left != 4 || style.Padding.Right != 4 {
+			t.Errorf("element %d style = %+v, want the box background and padding", i, style)
+		}
+		if style.BorderLeft == nil || style.BorderRight == nil {
+			t.Errorf("element %d has no side borders", i)
+		}
+		if (style.BorderTop != nil) != (i == 0) || (style.BorderBottom != nil) != (i == len(elements)-1) {
+			t.Errorf("element %d top and bottom borders = %v, %v", i, style.BorderTop, style.BorderBottom)
+		}
+	}
+	if elements[0].Bounds.Height != 2 || elements[3].Bounds.Height != 2 {
+		t.Errorf("vertical padding = %v, %v, want 2", elements[0].Bounds.Height, elements[3].Bounds.Height)
+	}
+}
+
+func TestConvert_Table(t *testing.T) {
+	elements := content(convert(t, `
+		<table border="1" style="width: 50%">
+			<thead><tr><th>Item</th><th>Qty</th></tr></thead>
+			<tbody>
+				<tr data-repeat="order.items" data-as="line"><td>{{ line.name }}</td><td>{{ line.qty }}</td></tr>
+				<tr data-if="order.discount"><td colspan="2">Discount applied</td></tr>
+			</tbody>
+		</table>`))
+	if len(elements) != 1 {
+		t.Fatalf("Convert() = %d elements, want 1", len(elements))
+	}
+
+	table := elements[0]
+	want := []interface{}{
+		[]interface{}{"Item", "Qty"},
+		map[string]interface{}{"cells": []interface{}{"{{ line.name }}", "{{ line.qty }}"}, "repeat": "order.items", "as": "line"},
+		map[string]interface{}{"cells": []interface{}{map[string]interface{}{"value": "Discount applied", "colSpan": 2.0}}, "if": "order.discount"},
+	}
+	if !reflect.DeepEqual(table.Content, want) {
+		t.Errorf("rows = %v, want %v", table.Content, want)
+	}
+	if table.Type != model.ElementTypeTable || table.Bounds.Width != 95 {
+		t.Errorf("table = %s %v wide, want a table 95 wide", table.Type, table.Bounds.Width)
+	}
+	if string(table.Metadata) != `{"headerRows":1}` {
+		t.Errorf("metadata = %s, want one header row", table.Metadata)
+	}
+	if table.Style.Border == nil || table.Style.BorderTop != nil {
+		t.Errorf("table border = %+v, want cell borders only", table.Style)
+	}
+}
+
+func TestConvert_Image(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logo.png")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(file, image.NewGray(image.Rect(0, 0, 192, 96))); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	tests := []struct {
+		name   string
+		source string
+		width  float64
+		height float64
+	}{
+		{name: "attributes", source: `<img src="{{ logo }}" width="96" height="48">`, width: 25.4, height: 12.7},
+		{name: "natural size", source: `<img src="` + path + `">`, width: 50.8, height: 25.4},
+		{name: "scaled", source: `<img src="` + path + `" style="width: 20mm">`, width: 20, height: 10},
+		{name: "limited to the width", source: `<img src="x.png" width="380mm" height="20mm">`, width: 190, height: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elements := content(convert(t, tt.source))
+			if len(elements) != 1 || elements[0].Type != model.ElementTypeImage {
+				t.Fatalf("Convert() = %+v, want one image", elements)
+			}
+			size := elements[0].Bounds.Size
+			if diff(size.Width, tt.width) > 1e-9 || diff(size.Height, tt.height) > 1e-9 {
+				t.Errorf("size = %+v, want %v x %v", size, tt.width, tt.height)
+			}
+		})
+	}
+
+	if _, err := Convert(strings.NewReader(`<img src="missing.png">`), Options{}); err == nil {
+		t.Error("Convert() error = nil, want error for an unsized image that cannot be read")
+	}
+
+	fitted := content(convert(t, `<img src="x.png" width="40" height="20" style="object-fit: cover; opacity: 0.5">`))
+	if string(fitted[0].Metadata) != `{"fit":"cover","opacity":0.5}` {
+		t.Errorf("image metadata = %s, want cover at half opacity", fitted[0].Metadata)
+	}
+
+	// Sources are read with the asset resolver
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	elements, err := Convert(strings.NewReader(`<img src="logo:42">`), Options{Assets: assets.Map{"logo:42": data}})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if size := content(elements)[0].Bounds.Size; diff(size.Width, 50.8) > 1e-9 {
+		t.Errorf("resolved image size = %+v, want 50.8 wide", size)
+	}
+}
+
+func diff(a, b float64) float64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func TestConvert_Control(t *testing.T) {
+	elements := content(convert(t, `<div data-repeat="order.items" data-as="line" style="margin: 0"><p>{{ line.name }}</p></div>`))
+	if len(elements) != 1 {
+		t.Fatalf("Convert() = %d elements, want 1", len(elements))
+	}
+
+	group := elements[0]
+	if group.Type != model.ElementTypeGroup || group.Repeat != "order.items" || group.RepeatAs != "line" {
+		t.Errorf("group = %+v, want a group repeating order.items as line", group)
+	}
+	if len(content(group.Children)) != 1 {
+		t.Errorf("group children = %+v, want the paragraph", group.Children)
+	}
+}
+
+func TestConvert_FontsAndColors(t *testing.T) {
+	registry := fonts.NewRegistry()
+	if err := registry.AddFile("Go", fonts.Bold, "../generator/testdata/fonts/GoSubset.ttf"); err != nil {
+		t.Fatalf("AddFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		style      string
+		wantFamily string
+		wantColor  string
+	}{
+		{name: "registered family", style: "font-family: 'Go', Arial", wantFamily: "Go"},
+		{name: "first known family", style: "font-family: Georgia, 'Courier New', monospace", wantFamily: "Courier"},
+		{name: "generic family", style: "font-family: Georgia, serif", wantFamily: "Times"},
+		{name: "unknown families", style: "font-family: Georgia, Verdana", wantFamily: "Helvetica"},
+		{name: "named color", style: "color: navy", wantFamily: "Helvetica", wantColor: "navy"},
+		{name: "inherited color", style: "color: inherit", wantFamily: "Helvetica"},
+		{name: "current color", style: "color: currentColor", wantFamily: "Helvetica"},
+		{name: "unsupported color", style: "color: hsl(0, 100%, 50%)", wantFamily: "Helvetica"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elements, err := Convert(strings.NewReader(`<p style="`+tt.style+`">Text</p>`), Options{FontFamily: "Helvetica", Fonts: registry})
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			style := content(elements)[0].Style
+			if style.FontFamily != tt.wantFamily || style.FontColor != tt.wantColor {
+				t.Errorf("style = %q in %q, want %q in %q", style.FontFamily, style.FontColor, tt.wantFamily, tt.wantColor)
+			}
+		})
+	}
+}
+
+func TestConvert_Generate(t *testing.T) {
+	elements := convert(t, `<html><head><title>Ignored</title></head><body>
+		<h1>Invoice {{ order.id }}</h1>
+		<p style="font-family: Georgia, serif; color: inherit">Dear <strong style="color: currentColor">{{ customer.name }}</strong>,</p>
+		<table><tr><th>Item</th></tr><tr data-repeat="order.items"><td>{{ item.name }}</td></tr></table>
+		<div data-if="order.paid" style="background: #eef; padding: 4px">Paid</div>
+		<p>See <em>{{ order.id }}</em> in <a href="https://example.com/orders/{{ order.id }}">your account</a>.</p>
+	</body></html>`)
+
+	template := &model.Template{Name: "invoice", Size: model.Size{Width: 210, Height: 297}, Elements: elements}
+	data := map[string]interface{}{
+		"customer": map[string]interface{}{"name": "John Doe"},
+		"order": map[string]interface{}{
+			"id":    "ORD-1",
+			"paid":  true,
+			"items": []interface{}{map[string]interface{}{"name": "Product A"}},
+		},
+	}
+	buf, err := generator.New(template).Generate(context.Background(), data)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	out := inflateStreams(t, buf.Bytes())
+	// Inline formatting keeps its runs: rich text is drawn word by word in
+	// the fonts of the runs, and links get an annotation
+	for _, want := range []string{
+		"(Dear) Tj", "(John) Tj", "(Doe) Tj", "/BaseFont /Times-Bold",
+		"(ORD-1) Tj", "/BaseFont /Helvetica-Oblique",
+		"(account) Tj", "/URI (https://example.com/orders/ORD-1)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("document does not contain %q", want)
+		}
+	}
+	if strings.Contains(out, "map[") {
+		t.Errorf("document draws runs as Go maps")
+	}
+}
+
+// inflateStreams returns the PDF with every Flate compressed stream
+// decompressed, so the drawn text can be searched
+func inflateStreams(t *testing.T, pdf []byte) string {
+	t.Helper()
+	var sb strings.Builder
+	for {
+		start := bytes.Index(pdf, []byte("stream\n"))
+		if start < 0 {
+			sb.Write(pdf)
+			return sb.String()
+		}
+		start += len("stream\n")
+		end := bytes.Index(pdf[start:], []byte("endstream"))
+		if end < 0 {
+			t.Fatalf("stream at %d has no end", start)
+		}
+		sb.Write(pdf[:start])
+		stream := pdf[start : start+end]
+		if r, err := zlib.NewReader(bytes.NewReader(stream)); err == nil {
+			inflated, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("failed to inflate stream at %d: %v", start, err)
+			}
+			stream = inflated
+		}
+		sb.Write(stream)
+		sb.WriteString("endstream")
+		pdf = pdf[start+end+len("endstream"):]
+	}
+}
+
+func TestConvert_Empty(t *testing.T) {
+	elements := convert(t, "<p>   </p><!-- nothing -->")
+	if len(content(elements)) != 0 {
+		t.Errorf("Convert() = %+v, want no content", elements)
+	}
+}
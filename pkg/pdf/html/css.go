@@ -0,0 +1,261 @@
+package html
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/fonts"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/internal/color"
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// Unit conversions to millimetres; CSS pixels are 1/96 inch
+const (
+	mmPerPixel = 25.4 / 96
+	mmPerPoint = 25.4 / 72
+	mmPerInch  = 25.4
+)
+
+// declarations parses an inline style attribute into lower-case properties
+// and their values
+func declarations(style string) map[string]string {
+	props := make(map[string]string)
+	for _, declaration := range strings.Split(style, ";") {
+		name, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+		if name != "" && value != "" {
+			props[name] = value
+		}
+	}
+	return props
+}
+
+// length converts a CSS length to millimetres. Em units are relative to
+// fontSize in points and percentages to base in millimetres. Unitless
+// numbers are pixels, as in HTML width and height attributes.
+func length(value string, fontSize, base float64) (float64, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"rem", fontSize * mmPerPoint},
+		{"em", fontSize * mmPerPoint},
+		{"mm", 1},
+		{"cm", 10},
+		{"in", mmPerInch},
+		{"pt", mmPerPoint},
+		{"px", mmPerPixel},
+		{"%", base / 100},
+		{"", mmPerPixel},
+	}
+	for _, unit := range units {
+		if !strings.HasSuffix(value, unit.suffix) {
+			continue
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, unit.suffix)), 64)
+		if err != nil {
+			return 0, false
+		}
+		return n * unit.scale, true
+	}
+	return 0, false
+}
+
+// fontSize converts a CSS font size to points relative to the parent size
+func fontSize(value string, parent float64) (float64, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "smaller":
+		return parent / 1.2, true
+	case "larger":
+		return parent * 1.2, true
+	}
+	if mm, ok := length(value, parent, parent*mmPerPoint); ok && mm > 0 {
+		return mm / mmPerPoint, true
+	}
+	return 0, false
+}
+
+// coreFamilies maps font families, including the CSS generic families, to
+// the standard PDF fonts
+var coreFamilies = map[string]string{
+	"arial":           "Arial",
+	"helvetica":       "Arial",
+	"sans-serif":      "Arial",
+	"system-ui":       "Arial",
+	"times":           "Times",
+	"times new roman": "Times",
+	"serif":           "Times",
+	"courier":         "Courier",
+	"courier new":     "Courier",
+	"monospace":       "Courier",
+}
+
+// fontFamily returns the first family of a font-family list that is
+// registered in registry or maps to a standard PDF font. It reports false
+// when the list names neither, as "Georgia, Verdana" does without those
+// fonts registered.
+func fontFamily(value string, registry *fonts.Registry) (string, bool) {
+	for _, family := range strings.Split(value, ",") {
+		family = strings.Trim(strings.TrimSpace(family), `"'`)
+		// Any registered style will do, as the renderer picks the closest
+		if _, ok := registry.Lookup(family, fonts.BoldItalic); ok {
+			return family, true
+		}
+		if core, ok := coreFamilies[strings.ToLower(family)]; ok {
+			return core, true
+		}
+	}
+	return "", false
+}
+
+// cssColor returns a color value the renderer can parse. Keywords that
+// keep the inherited color, and colors it cannot draw, report false.
+func cssColor(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "inherit", "currentcolor", "initial", "unset":
+		return "", false
+	}
+	if _, err := color.Parse(value); err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+// lineHeight converts a CSS line height to a multiple of the font size
+func lineHeight(value string, size float64) (float64, bool) {
+	if n, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+		return n, n > 0
+	}
+	if strings.HasSuffix(strings.TrimSpace(value), "%") {
+		mm, ok := length(value, size, 1)
+		return mm, ok && mm > 0
+	}
+	if mm, ok := length(value, size, 0); ok && mm > 0 {
+		return mm / (size * mmPerPoint), true
+	}
+	return 0, false
+}
+
+// padding parses the padding shorthand and its per-side properties
+func padding(props map[string]string, size, base float64) *model.Padding {
+	var p model.Padding
+	found := false
+	if value, ok := props["padding"]; ok {
+		var sides []float64
+		for _, field := range strings.Fields(value) {
+			n, ok := length(field, size, base)
+			if !ok {
+				return nil
+			}
+			sides = append(sides, n)
+		}
+		switch len(sides) {
+		case 1:
+			p = model.Padding{Top: sides[0], Right: sides[0], Bottom: sides[0], Left: sides[0]}
+		case 2:
+			p = model.Padding{Top: sides[0], Right: sides[1], Bottom: sides[0], Left: sides[1]}
+		case 3:
+			p = model.Padding{Top: sides[0], Right: sides[1], Bottom: sides[2], Left: sides[1]}
+		case 4:
+			p = model.Padding{Top: sides[0], Right: sides[1], Bottom: sides[2], Left: sides[3]}
+		}
+		found = len(sides) > 0
+	}
+	for name, side := range map[string]*float64{
+		"padding-top": &p.Top, "padding-right": &p.Right, "padding-bottom": &p.Bottom, "padding-left": &p.Left,
+	} {
+		if n, ok := length(props[name], size, base); ok {
+			*side = n
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return &p
+}
+
+// margins returns the vertical margins, the only ones the flow layout uses
+func margins(props map[string]string, size, base, top, bottom float64) (float64, float64) {
+	if fields := strings.Fields(props["margin"]); len(fields) > 0 {
+		if n, ok := length(fields[0], size, base); ok {
+			top, bottom = n, n
+		}
+		if len(fields) > 2 {
+			if n, ok := length(fields[2], size, base); ok {
+				bottom = n
+			}
+		}
+	}
+	if n, ok := length(props["margin-top"], size, base); ok {
+		top = n
+	}
+	if n, ok := length(props["margin-bottom"], size, base); ok {
+		bottom = n
+	}
+	return top, bottom
+}
+
+// border parses a border shorthand such as "1px solid #ccc"
+func border(value string, size float64) *model.Border {
+	if value == "" {
+		return nil
+	}
+	b := &model.Border{Width: mmPerPixel, Style: model.BorderSolid}
+	for _, field := range strings.Fields(value) {
+		switch lower := strings.ToLower(field); lower {
+		case model.BorderSolid, model.BorderDashed, model.BorderDotted:
+			b.Style = lower
+		case model.BorderNone, "hidden", "0":
+			return &model.Border{Style: model.BorderNone}
+		case "thin":
+			b.Width = mmPerPixel
+		case "medium":
+			b.Width = 3 * mmPerPixel
+		case "thick":
+			b.Width = 5 * mmPerPixel
+		default:
+			if n, ok := length(lower, size, 0); ok && strings.ContainsRune("0123456789.", rune(lower[0])) {
+				b.Width = n
+			} else if c, ok := cssColor(field); ok {
+				b.Color = c
+			}
+		}
+	}
+	return b
+}
+
+// backgroundColor picks the color out of a background shorthand such as
+// "#f5f5f5 url(bg.png) no-repeat"
+func backgroundColor(value string) string {
+	// Color functions may contain spaces
+	for _, fn := range []string{"rgba(", "rgb(", "cmyk("} {
+		if i := strings.Index(strings.ToLower(value), fn); i >= 0 {
+			if end := strings.IndexByte(value[i:], ')'); end >= 0 {
+				return value[i : i+end+1]
+			}
+		}
+	}
+
+	for _, field := range strings.Fields(value) {
+		lower := strings.ToLower(field)
+		switch {
+		case strings.HasPrefix(lower, "url("), strings.ContainsRune("0123456789.-", rune(lower[0])):
+			continue
+		}
+		switch lower {
+		case "none", "repeat", "no-repeat", "repeat-x", "repeat-y", "center", "top", "bottom",
+			"left", "right", "fixed", "scroll", "cover", "contain":
+			continue
+		}
+		return field
+	}
+	return ""
+}
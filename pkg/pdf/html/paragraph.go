@@ -0,0 +1,124 @@
+package html
+
+import (
+	"strings"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/model"
+)
+
+// paragraph collects the inline content of a block as text runs, collapsing
+// whitespace the way browsers do
+type paragraph struct {
+	runs []model.TextRun
+	// space is set after whitespace and at the start, where further
+	// whitespace is dropped
+	space bool
+}
+
+// add appends text in the formatting of st. Preformatted text, such as the
+// newline of a br, is kept as is.
+func (p *paragraph) add(text string, st state, preformatted bool) {
+	var out strings.Builder
+	for _, r := range text {
+		switch {
+		case preformatted:
+			out.WriteRune(r)
+			p.space = r == '\n'
+		case isSpace(r):
+			if !p.space {
+				out.WriteByte(' ')
+				p.space = true
+			}
+		default:
+			out.WriteRune(r)
+			p.space = false
+		}
+	}
+	if out.Len() == 0 {
+		return
+	}
+
+	run := model.TextRun{
+		Bold:      st.style.FontWeight.Bold(),
+		Italic:    st.style.FontStyle.Italic(),
+		Underline: st.style.TextDecoration.Has(model.DecorationUnderline),
+		Strike:    st.style.TextDecoration.Has(model.DecorationLineThrough),
+		Code:      st.code,
+		Color:     st.style.FontColor,
+		Link:      st.link,
+	}
+	if n := len(p.runs); n > 0 {
+		last := p.runs[n-1]
+		last.Text = ""
+		if last == run {
+			p.runs[n-1].Text += out.String()
+			return
+		}
+	}
+	run.Text = out.String()
+	p.runs = append(p.runs, run)
+}
+
+// empty reports whether the paragraph has no visible text
+func (p *paragraph) empty() bool {
+	for _, run := range p.runs {
+		if strings.TrimSpace(run.Text) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// content returns the runs as element content, leaving out formatting the
+// block style already applies
+func (p *paragraph) content(block model.Style) []interface{} {
+	if n := len(p.runs); n > 0 {
+		p.runs[n-1].Text = strings.TrimRight(p.runs[n-1].Text, " ")
+	}
+
+	content := make([]interface{}, 0, len(p.runs))
+	for _, run := range p.runs {
+		if run.Text == "" {
+			continue
+		}
+		fields := map[string]interface{}{"text": run.Text}
+		flags := []struct {
+			key   string
+			set   bool
+			block bool
+		}{
+			{"bold", run.Bold, block.FontWeight.Bold()},
+			{"italic", run.Italic, block.FontStyle.Italic()},
+			{"underline", run.Underline, block.TextDecoration.Has(model.DecorationUnderline)},
+			{"strike", run.Strike, block.TextDecoration.Has(model.DecorationLineThrough)},
+			{"code", run.Code, false},
+		}
+		for _, flag := range flags {
+			if flag.set && !flag.block {
+				fields[flag.key] = true
+			}
+		}
+		if run.Color != "" && run.Color != block.FontColor {
+			fields["color"] = run.Color
+		}
+		if run.Link != "" {
+			fields["link"] = run.Link
+		}
+		content = append(content, fields)
+	}
+	return content
+}
+
+// collapse replaces runs of whitespace with single spaces and trims the ends
+func collapse(text string) string {
+	return strings.Join(strings.FieldsFunc(text, isSpace), " ")
+}
+
+// isSpace reports whether r is HTML whitespace; non-breaking spaces are not
+func isSpace(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\r', '\f':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+// Package hyphen finds the points where words may be hyphenated when
+// justified text is wrapped.
+//
+// Patterns implements Liang's algorithm, as used by TeX, on top of the
+// pattern files published for most languages (for example hyph-en-us.tex
+// or hyph-fr.tex from the hyph-utf8 project). Any other dictionary can be
+// plugged in by implementing Hyphenator.
+package hyphen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Hyphenator splits a word into the pieces between its hyphenation points.
+// Words that cannot be hyphenated are returned as a single piece.
+type Hyphenator interface {
+	Hyphenate(word string) []string
+}
+
+// Default minimum number of letters before the first and after the last hyphen
+const (
+	DefaultLeftMin  = 2
+	DefaultRightMin = 3
+)
+
+// Patterns is a Hyphenator built from TeX hyphenation patterns
+type Patterns struct {
+	patterns   map[string][]int
+	exceptions map[string][]int
+	maxLength  int
+
+	// LeftMin and RightMin are the fewest letters kept before the first
+	// and after the last hyphen
+	LeftMin  int
+	RightMin int
+}
+
+// NewPatterns reads TeX hyphenation patterns. The input is either a bare
+// list of whitespace separated patterns such as "hy3ph he2n" or a TeX file
+// with \patterns{...} and \hyphenation{...} groups; % starts a comment.
+// Entries of the \hyphenation group are exceptions written with hyphens,
+// such as "ta-ble".
+func NewPatterns(r io.Reader) (*Patterns, error) {
+	p := &Patterns{
+		patterns:   make(map[string][]int),
+		exceptions: make(map[string][]int),
+		LeftMin:    DefaultLeftMin,
+		RightMin:   DefaultRightMin,
+	}
+
+	exceptions := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '%'); i >= 0 {
+			line = line[:i]
+		}
+
+		for _, field := range strings.Fields(line) {
+			switch {
+			case strings.HasPrefix(field, `\patterns{`):
+				exceptions, field = false, strings.TrimPrefix(field, `\patterns{`)
+			case strings.HasPrefix(field, `\hyphenation{`):
+				exceptions, field = true, strings.TrimPrefix(field, `\hyphenation{`)
+			}
+			field = strings.TrimSuffix(field, "}")
+			if field == "" {
+				continue
+			}
+
+			if exceptions {
+				p.addException(field)
+			} else if err := p.addPattern(field); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hyphenation patterns: %w", err)
+	}
+	return p, nil
+}
+
+// addPattern stores a pattern such as "hen5at" as its letters and the
+// values between them
+func (p *Patterns) addPattern(pattern string) error {
+	var letters []rune
+	values := []int{0}
+	for _, r := range pattern {
+		if r >= '0' && r <= '9' {
+			values[len(values)-1] = int(r - '0')
+			continue
+		}
+		if r != '.' && !unicode.IsLetter(r) && r != '\'' {
+			return fmt.Errorf("invalid hyphenation pattern %q", pattern)
+		}
+		letters = append(letters, unicode.ToLower(r))
+		values = append(values, 0)
+	}
+
+	key := string(letters)
+	p.patterns[key] = values
+	if len(letters) > p.maxLength {
+		p.maxLength = len(letters)
+	}
+	return nil
+}
+
+// addException stores a word with explicit hyphenation points such as "ta-ble"
+func (p *Patterns) addException(word string) {
+	var letters []rune
+	var points []int
+	for _, r := range word {
+		if r == '-' {
+			points = append(points, len(letters))
+			continue
+		}
+		letters = append(letters, unicode.ToLower(r))
+	}
+	p.exceptions[string(letters)] = points
+}
+
+// Hyphenate splits word at its hyphenation points, keeping its case
+func (p *Patterns) Hyphenate(word string) []string {
+	runes := []rune(word)
+	lower := []rune(strings.ToLower(word))
+	if len(lower) != len(runes) {
+		return []string{word}
+	}
+
+	points, ok := p.exceptions[string(lower)]
+	if !ok {
+		points = p.points(lower)
+	}
+
+	var pieces []string
+	start := 0
+	for _, point := range points {
+		if point <= start || point >= len(runes) {
+			continue
+		}
+		pieces = append(pieces, string(runes[start:point]))
+		start = point
+	}
+	return append(pieces, string(runes[start:]))
+}
+
+// points returns the positions between letters where a hyphen may go
+func (p *Patterns) points(word []rune) []int {
+	if len(word) < p.LeftMin+p.RightMin {
+		return nil
+	}
+
+	// Match every substring of the word, delimited by dots, against the patterns
+	dotted := append(append([]rune{'.'}, word...), '.')
+	values := make([]int, len(dotted)+1)
+	for i := range dotted {
+		for j := i + 1; j <= len(dotted) && j-i <= p.maxLength; j++ {
+			pattern, ok := p.patterns[string(dotted[i:j])]
+			if !ok {
+				continue
+			}
+			for k, v := range pattern {
+				if v > values[i+k] {
+					values[i+k] = v
+				}
+			}
+		}
+	}
+
+	// An odd value before letter i of the word allows a hyphen there; the
+	// leading dot shifts word positions by one
+	var points []int
+	for i := p.LeftMin; i <= len(word)-p.RightMin; i++ {
+		if values[i+1]%2 == 1 {
+			points = append(points, i)
+		}
+	}
+	return points
+}
@@ -0,0 +1,48 @@
+package hyphen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// liangPatterns are the patterns from Liang's thesis that hyphenate "hyphenation"
+const liangPatterns = `% sample patterns
+\patterns{
+hy3ph he2n hena4 hen5at 1na n2at 1tio 2io o2n
+}
+\hyphenation{
+ta-ble
+}`
+
+func TestPatterns_Hyphenate(t *testing.T) {
+	patterns, err := NewPatterns(strings.NewReader(liangPatterns))
+	if err != nil {
+		t.Fatalf("NewPatterns() error = %v", err)
+	}
+
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{word: "hyphenation", want: []string{"hy", "phen", "ation"}},
+		{word: "Hyphenation", want: []string{"Hy", "phen", "ation"}},
+		{word: "table", want: []string{"ta", "ble"}},
+		{word: "on", want: []string{"on"}},
+		{word: "contract", want: []string{"contract"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := patterns.Hyphenate(tt.word); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Hyphenate(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPatterns_Invalid(t *testing.T) {
+	if _, err := NewPatterns(strings.NewReader("a1b c+d")); err == nil {
+		t.Error("NewPatterns() error = nil, want error for invalid pattern")
+	}
+}
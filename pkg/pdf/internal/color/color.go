@@ -0,0 +1,158 @@
+// Package color parses the color strings used in template styles.
+//
+// Supported forms are hex (#rgb, #rgba, #rrggbb, #rrggbbaa), rgb() and
+// rgba() with 0-255 or percentage channels, cmyk() with 0-1 or percentage
+// components, "transparent" and the CSS named colors. CMYK colors are
+// converted to RGB, as gofpdf only writes DeviceRGB colors.
+package color
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Color is an RGB color with an alpha between 0 (transparent) and 1 (opaque)
+type Color struct {
+	R, G, B int
+	A       float64
+}
+
+// Black is the default color for text and barcodes
+var Black = Color{A: 1}
+
+// Parse converts a color string to a Color
+func Parse(s string) (Color, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case value == "":
+		return Color{}, fmt.Errorf("empty color")
+	case value == "transparent":
+		return Color{}, nil
+	case strings.HasPrefix(value, "#"):
+		return parseHex(value[1:], s)
+	case strings.HasPrefix(value, "rgb"):
+		return parseRGB(value, s)
+	case strings.HasPrefix(value, "cmyk"):
+		return parseCMYK(value, s)
+	}
+
+	if rgb, ok := named[value]; ok {
+		return Color{R: int(rgb >> 16), G: int(rgb >> 8 & 0xff), B: int(rgb & 0xff), A: 1}, nil
+	}
+	return Color{}, fmt.Errorf("unknown color %q", s)
+}
+
+// Transparent reports whether the color draws nothing
+func (c Color) Transparent() bool {
+	return c.A <= 0
+}
+
+// Opaque reports whether the color needs no alpha blending
+func (c Color) Opaque() bool {
+	return c.A >= 1
+}
+
+func parseHex(digits, original string) (Color, error) {
+	// Expand the short forms #rgb and #rgba
+	if len(digits) == 3 || len(digits) == 4 {
+		var sb strings.Builder
+		for _, d := range digits {
+			sb.WriteRune(d)
+			sb.WriteRune(d)
+		}
+		digits = sb.String()
+	}
+	if len(digits) != 6 && len(digits) != 8 {
+		return Color{}, fmt.Errorf("invalid hex color %q", original)
+	}
+
+	n, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid hex color %q", original)
+	}
+
+	alpha := 1.0
+	if len(digits) == 8 {
+		alpha = float64(n&0xff) / 255
+		n >>= 8
+	}
+	return Color{R: int(n >> 16), G: int(n >> 8 & 0xff), B: int(n & 0xff), A: alpha}, nil
+}
+
+func parseRGB(value, original string) (Color, error) {
+	args, err := functionArgs(value, original, "rgba", "rgb")
+	if err != nil {
+		return Color{}, err
+	}
+	if len(args) != 3 && len(args) != 4 {
+		return Color{}, fmt.Errorf("invalid rgb color %q", original)
+	}
+
+	var channels [3]int
+	for i := range channels {
+		v, err := component(args[i], 255)
+		if err != nil || v < 0 || v > 255 {
+			return Color{}, fmt.Errorf("invalid rgb color %q", original)
+		}
+		channels[i] = int(v + 0.5)
+	}
+
+	alpha := 1.0
+	if len(args) == 4 {
+		if alpha, err = component(args[3], 1); err != nil || alpha < 0 || alpha > 1 {
+			return Color{}, fmt.Errorf("invalid rgb color %q", original)
+		}
+	}
+	return Color{R: channels[0], G: channels[1], B: channels[2], A: alpha}, nil
+}
+
+func parseCMYK(value, original string) (Color, error) {
+	args, err := functionArgs(value, original, "cmyk")
+	if err != nil {
+		return Color{}, err
+	}
+	if len(args) != 4 {
+		return Color{}, fmt.Errorf("invalid cmyk color %q", original)
+	}
+
+	var cmyk [4]float64
+	for i := range cmyk {
+		v, err := component(args[i], 1)
+		if err != nil || v < 0 || v > 1 {
+			return Color{}, fmt.Errorf("invalid cmyk color %q", original)
+		}
+		cmyk[i] = v
+	}
+
+	c, m, y, k := cmyk[0], cmyk[1], cmyk[2], cmyk[3]
+	channel := func(v float64) int {
+		return int(255*(1-v)*(1-k) + 0.5)
+	}
+	return Color{R: channel(c), G: channel(m), B: channel(y), A: 1}, nil
+}
+
+// functionArgs splits the comma or space separated arguments of name(...)
+func functionArgs(value, original string, names ...string) ([]string, error) {
+	for _, name := range names {
+		if !strings.HasPrefix(value, name+"(") {
+			continue
+		}
+		if !strings.HasSuffix(value, ")") {
+			break
+		}
+		body := value[len(name)+1 : len(value)-1]
+		body = strings.NewReplacer(",", " ", "/", " ").Replace(body)
+		return strings.Fields(body), nil
+	}
+	return nil, fmt.Errorf("invalid color %q", original)
+}
+
+// component parses a number, scaling percentages to max
+func component(s string, max float64) (float64, error) {
+	if strings.HasSuffix(s, "%") {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		return v / 100 * max, err
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,42 @@
+package color
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Color
+		wantErr bool
+	}{
+		{input: "#1a73e8", want: Color{R: 0x1a, G: 0x73, B: 0xe8, A: 1}},
+		{input: "#FFF", want: Color{R: 255, G: 255, B: 255, A: 1}},
+		{input: "#ff000080", want: Color{R: 255, A: 128.0 / 255}},
+		{input: "#0f08", want: Color{G: 255, A: 136.0 / 255}},
+		{input: "rgb(26, 115, 232)", want: Color{R: 26, G: 115, B: 232, A: 1}},
+		{input: "rgba(0, 0, 0, 0.5)", want: Color{A: 0.5}},
+		{input: "rgb(100% 50% 0%)", want: Color{R: 255, G: 128, A: 1}},
+		{input: "cmyk(0%, 100%, 100%, 0%)", want: Color{R: 255, A: 1}},
+		{input: "cmyk(0, 0, 0, 0.5)", want: Color{R: 128, G: 128, B: 128, A: 1}},
+		{input: "  NavajoWhite ", want: Color{R: 0xff, G: 0xde, B: 0xad, A: 1}},
+		{input: "transparent", want: Color{}},
+		{input: "", wantErr: true},
+		{input: "#12345", wantErr: true},
+		{input: "#ggg", wantErr: true},
+		{input: "rgb(300, 0, 0)", wantErr: true},
+		{input: "rgb(1, 2)", wantErr: true},
+		{input: "cmyk(0, 0, 0)", wantErr: true},
+		{input: "brandblue", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/errors"
+)
+
+// Orientation defines page orientation options
+type Orientation string
+
+const (
+	OrientationPortrait  Orientation = "portrait"
+	OrientationLandscape Orientation = "landscape"
+)
+
+// PresetRoll is the page size preset for receipt rolls of a custom width:
+// the width comes from Template.Size and the height grows with the content
+const PresetRoll = "roll"
+
+// PageSizes maps preset names to portrait page sizes in millimetres. A zero
+// height marks a continuous roll whose height grows to fit the content.
+var PageSizes = map[string]Size{
+	"a3":        {Width: 297, Height: 420},
+	"a4":        {Width: 210, Height: 297},
+	"a5":        {Width: 148, Height: 210},
+	"a6":        {Width: 105, Height: 148},
+	"letter":    {Width: 215.9, Height: 279.4},
+	"legal":     {Width: 215.9, Height: 355.6},
+	"tabloid":   {Width: 279.4, Height: 431.8},
+	"receipt80": {Width: 80, Height: 0},
+	"receipt58": {Width: 58, Height: 0},
+}
+
+// PageSetup overrides the size or orientation of a single page
+type PageSetup struct {
+	// Page is the 1-based number of the page the override applies to
+	Page        int         `json:"page"`
+	Preset      string      `json:"preset,omitempty"`
+	Width       float64     `json:"width,omitempty"`
+	Height      float64     `json:"height,omitempty"`
+	Orientation Orientation `json:"orientation,omitempty"`
+}
+
+// PageSizeFor returns the size of a 1-based page after applying the page
+// size preset, orientation and any override for that page. A zero height
+// means the page grows to fit its content.
+func (t *Template) PageSizeFor(page int) Size {
+	size := t.Size
+	if preset, ok := lookupPreset(t.PageSize); ok {
+		size.Height = preset.Height
+		if !strings.EqualFold(t.PageSize, PresetRoll) {
+			size.Width = preset.Width
+		}
+	}
+	orientation := t.Orientation
+
+	for _, override := range t.Pages {
+		if override.Page != page {
+			continue
+		}
+		if preset, ok := lookupPreset(override.Preset); ok {
+			size = preset
+		}
+		if override.Width > 0 {
+			size.Width = override.Width
+		}
+		if override.Height > 0 {
+			size.Height = override.Height
+		}
+		if override.Orientation != "" {
+			orientation = override.Orientation
+		}
+	}
+
+	return orient(size, orientation)
+}
+
+// AutoHeight reports whether the document is a single page that grows with its content
+func (t *Template) AutoHeight() bool {
+	return t.PageSizeFor(1).Height == 0
+}
+
+// validatePages checks the page size preset, orientation and overrides
+func (t *Template) validatePages() error {
+	if t.PageSize != "" {
+		if _, ok := lookupPreset(t.PageSize); !ok {
+			return errors.NewPDFError(errors.ErrInvalidTemplate, fmt.Sprintf("unknown page size %q", t.PageSize), nil)
+		}
+		if strings.EqualFold(t.PageSize, PresetRoll) && t.Size.Width <= 0 {
+			return errors.NewPDFError(errors.ErrInvalidTemplate, "roll page size requires a width", nil)
+		}
+	} else if t.Size.Width <= 0 || t.Size.Height <= 0 {
+		return errors.NewPDFError(errors.ErrInvalidTemplate, "invalid template size", nil)
+	}
+
+	if err := validateOrientation(t.Orientation); err != nil {
+		return err
+	}
+
+	for _, override := range t.Pages {
+		if override.Page < 1 {
+			return errors.NewPDFError(errors.ErrInvalidTemplate, "page override requires a page number", nil)
+		}
+		if override.Preset != "" {
+			preset, ok := lookupPreset(override.Preset)
+			if !ok || preset.Height == 0 {
+				return errors.NewPDFError(errors.ErrInvalidTemplate, fmt.Sprintf("invalid page size %q for page %d", override.Preset, override.Page), nil)
+			}
+		}
+		if override.Width < 0 || override.Height < 0 {
+			return errors.NewPDFError(errors.ErrInvalidTemplate, fmt.Sprintf("invalid size for page %d", override.Page), nil)
+		}
+		if err := validateOrientation(override.Orientation); err != nil {
+			return err
+		}
+	}
+
+	if t.AutoHeight() && len(t.Pages) > 0 {
+		return errors.NewPDFError(errors.ErrInvalidTemplate, "page overrides cannot be combined with a continuous roll", nil)
+	}
+	return nil
+}
+
+func validateOrientation(o Orientation) error {
+	switch o {
+	case "", OrientationPortrait, OrientationLandscape:
+		return nil
+	}
+	return errors.NewPDFError(errors.ErrInvalidTemplate, fmt.Sprintf("unknown orientation %q", o), nil)
+}
+
+func lookupPreset(name string) (Size, bool) {
+	if strings.EqualFold(name, PresetRoll) {
+		return Size{}, true
+	}
+	size, ok := PageSizes[strings.ToLower(name)]
+	return size, ok
+}
+
+// orient swaps width and height when they do not match the orientation
+func orient(size Size, orientation Orientation) Size {
+	if size.Height == 0 {
+		return size
+	}
+	landscape := size.Width > size.Height
+	if (orientation == OrientationLandscape && !landscape) || (orientation == OrientationPortrait && landscape) {
+		size.Width, size.Height = size.Height, size.Width
+	}
+	return size
+}
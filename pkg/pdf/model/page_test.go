@@ -0,0 +1,106 @@
+package model
+
+import "testing"
+
+func TestTemplate_PageSizeFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		template Template
+		page     int
+		want     Size
+	}{
+		{
+			name:     "custom size",
+			template: Template{Size: Size{Width: 100, Height: 150}},
+			page:     1,
+			want:     Size{Width: 100, Height: 150},
+		},
+		{
+			name:     "preset replaces size",
+			template: Template{PageSize: "Letter"},
+			page:     1,
+			want:     Size{Width: 215.9, Height: 279.4},
+		},
+		{
+			name:     "landscape preset",
+			template: Template{PageSize: "A4", Orientation: OrientationLandscape},
+			page:     3,
+			want:     Size{Width: 297, Height: 210},
+		},
+		{
+			name:     "receipt roll grows with content",
+			template: Template{PageSize: "receipt80", Orientation: OrientationLandscape},
+			page:     1,
+			want:     Size{Width: 80},
+		},
+		{
+			name:     "roll of custom width",
+			template: Template{PageSize: PresetRoll, Size: Size{Width: 76}},
+			page:     1,
+			want:     Size{Width: 76},
+		},
+		{
+			name: "override applies to its page only",
+			template: Template{PageSize: "A4", Pages: []PageSetup{
+				{Page: 2, Orientation: OrientationLandscape},
+			}},
+			page: 2,
+			want: Size{Width: 297, Height: 210},
+		},
+		{
+			name: "override preset",
+			template: Template{PageSize: "A4", Pages: []PageSetup{
+				{Page: 2, Preset: "A3"},
+			}},
+			page: 1,
+			want: Size{Width: 210, Height: 297},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.template.PageSizeFor(tt.page); got != tt.want {
+				t.Errorf("PageSizeFor(%d) = %+v, want %+v", tt.page, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplate_ValidatePages(t *testing.T) {
+	element := []Element{{ID: "title", Type: ElementTypeText}}
+
+	tests := []struct {
+		name     string
+		template Template
+		wantErr  bool
+	}{
+		{name: "custom size", template: Template{Size: Size{Width: 100, Height: 100}}},
+		{name: "preset without size", template: Template{PageSize: "legal"}},
+		{name: "missing size", template: Template{}, wantErr: true},
+		{name: "unknown preset", template: Template{PageSize: "B7"}, wantErr: true},
+		{name: "roll without width", template: Template{PageSize: PresetRoll}, wantErr: true},
+		{name: "unknown orientation", template: Template{PageSize: "A4", Orientation: "sideways"}, wantErr: true},
+		{name: "override without page", template: Template{PageSize: "A4", Pages: []PageSetup{{Preset: "A3"}}}, wantErr: true},
+		{name: "override with roll", template: Template{PageSize: "A4", Pages: []PageSetup{{Page: 2, Preset: "receipt58"}}}, wantErr: true},
+		{name: "roll with overrides", template: Template{PageSize: "receipt80", Pages: []PageSetup{{Page: 1, Width: 90}}}, wantErr: true},
+		{name: "form in header", template: Template{PageSize: "A4", Header: &Region{Height: 10, Elements: []Element{{ID: "sign", Type: ElementTypeForm}}}}, wantErr: true},
+		{
+			name: "form in a footer variant group",
+			template: Template{PageSize: "A4", Footer: &Region{Height: 10, First: &Region{Height: 10, Elements: []Element{
+				{ID: "group", Children: []Element{{ID: "sign", Type: ElementTypeForm}}},
+			}}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.template.Name = "test"
+			tt.template.Elements = element
+			err := tt.template.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/errors"
+)
+
+// Region is a band of elements drawn at the top or bottom of every page,
+// such as a letterhead or a "Page {page} of {pages}" footer. Element bounds
+// are relative to the top-left corner of the band and a zero width or
+// height fills the rest of the band.
+type Region struct {
+	Height   float64   `json:"height"`
+	Elements []Element `json:"elements"`
+	// First replaces the region on the first page; a region without
+	// elements leaves the first page blank
+	First *Region `json:"first,omitempty"`
+	// Even replaces the region on even pages
+	Even *Region `json:"even,omitempty"`
+}
+
+// ForPage returns the variant of the region shown on a 1-based page
+func (r *Region) ForPage(page int) *Region {
+	if r == nil {
+		return nil
+	}
+	if page == 1 && r.First != nil {
+		return r.First
+	}
+	if page%2 == 0 && r.Even != nil {
+		return r.Even
+	}
+	return r
+}
+
+// HeightOn returns the space the region reserves on a 1-based page
+func (r *Region) HeightOn(page int) float64 {
+	if variant := r.ForPage(page); variant != nil {
+		return variant.Height
+	}
+	return 0
+}
+
+// validate checks the region and its page variants
+func (r *Region) validate(name string) error {
+	if r == nil {
+		return nil
+	}
+	variants := []struct {
+		name   string
+		region *Region
+	}{{name, r}, {name + " first", r.First}, {name + " even", r.Even}}
+
+	for _, v := range variants {
+		variant, region := v.name, v.region
+		if region == nil {
+			continue
+		}
+		if region.Height < 0 || (len(region.Elements) > 0 && region.Height == 0) {
+			return errors.NewPDFError(errors.ErrInvalidTemplate, fmt.Sprintf("%s requires a height", variant), nil)
+		}
+		if region != r && (region.First != nil || region.Even != nil) {
+			return errors.NewPDFError(errors.ErrInvalidTemplate, fmt.Sprintf("%s cannot have page variants", variant), nil)
+		}
+		// Regions are drawn on every page, and a form field name can only
+		// be used once per document
+		if id, ok := findFormElement(region.Elements); ok {
+			return errors.NewPDFError(errors.ErrInvalidTemplate, fmt.Sprintf("%s cannot contain form element %s", variant, id), nil)
+		}
+		if err := validateStyles(region.Elements); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findFormElement returns the ID of the first form element among elements
+// and their children
+func findFormElement(elements []Element) (string, bool) {
+	for _, element := range elements {
+		if element.Type == ElementTypeForm {
+			return element.ID, true
+		}
+		if id, ok := findFormElement(element.Children); ok {
+			return id, true
+		}
+	}
+	return "", false
+}
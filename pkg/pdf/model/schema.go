@@ -2,6 +2,9 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/josephmojoo/pdfgen/pkg/pdf/errors"
 )
@@ -33,6 +36,10 @@ const (
 	ElementTypeImage   ElementType = "image"
 	ElementTypeBarcode ElementType = "barcode"
 	ElementTypeForm    ElementType = "form"
+	ElementTypeShape   ElementType = "shape"
+	ElementTypeChart   ElementType = "chart"
+	ElementTypeSVG     ElementType = "svg"
+	ElementTypeGroup   ElementType = "group"
 )
 
 // TextAlignment defines text alignment options
@@ -45,6 +52,15 @@ const (
 	AlignJustify TextAlignment = "justify"
 )
 
+// VerticalAlignment places content within the height of its box
+type VerticalAlignment string
+
+const (
+	AlignTop    VerticalAlignment = "top"
+	AlignMiddle VerticalAlignment = "middle"
+	AlignBottom VerticalAlignment = "bottom"
+)
+
 // Element represents a PDF element configuration
 type Element struct {
 	ID       string          `json:"id"`
@@ -53,6 +69,44 @@ type Element struct {
 	Content  interface{}     `json:"content"`
 	Style    *Style          `json:"style,omitempty"`
 	Metadata json.RawMessage `json:"metadata,omitempty"`
+
+	// Repeat is a binding path to an array; the element (or its children,
+	// for groups) is emitted once per item
+	Repeat string `json:"repeat,omitempty"`
+	// RepeatAs names the loop variable, "item" by default
+	RepeatAs string `json:"as,omitempty"`
+	// Children are the elements of a group, laid out in order
+	Children []Element `json:"children,omitempty"`
+	// If is a condition evaluated against the render data; when it is
+	// false the element is dropped before layout and takes no space
+	If string `json:"if,omitempty"`
+
+	// layoutState is attached during layout, such as the place of a table
+	// part in a table broken across pages, and is never read from JSON
+	layoutState interface{}
+}
+
+// LayoutState returns the state the layout attached to the element
+func (e *Element) LayoutState() interface{} {
+	return e.layoutState
+}
+
+// SetLayoutState attaches state for the element's renderer, which travels
+// with the element from layout to rendering
+func (e *Element) SetLayoutState(state interface{}) {
+	e.layoutState = state
+}
+
+// DecodeMetadata unmarshals the element's type-specific options into v.
+// Elements without metadata leave v unchanged.
+func (e *Element) DecodeMetadata(v interface{}) error {
+	if len(e.Metadata) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(e.Metadata, v); err != nil {
+		return errors.NewPDFError(errors.ErrInvalidTemplate, "invalid metadata for element "+e.ID, err)
+	}
+	return nil
 }
 
 // Style defines the visual properties of an element
@@ -64,6 +118,119 @@ type Style struct {
 	Border     *Border       `json:"border,omitempty"`
 	Padding    *Padding      `json:"padding,omitempty"`
 	Alignment  TextAlignment `json:"alignment,omitempty"`
+	// VerticalAlignment places text within the element height, or table cell
+	// text within its row; text elements default to top and cells to middle
+	VerticalAlignment VerticalAlignment `json:"verticalAlignment,omitempty"`
+	// LineHeight is the distance between text baselines as a multiple of the font size
+	LineHeight float64 `json:"lineHeight,omitempty"`
+	// Overflow controls text that does not fit the element bounds
+	Overflow Overflow `json:"overflow,omitempty"`
+	// Hyphenate breaks words at line ends using the generator's hyphenator
+	Hyphenate bool `json:"hyphenate,omitempty"`
+	// FontWeight is "normal" or "bold"; numeric weights from 600 up, written
+	// as numbers or strings, are bold
+	FontWeight FontWeight `json:"fontWeight,omitempty"`
+	// FontStyle is "normal" or "italic"
+	FontStyle FontStyle `json:"fontStyle,omitempty"`
+	// TextDecoration is "underline", "line-through" or both separated by a space
+	TextDecoration TextDecoration `json:"textDecoration,omitempty"`
+	// LetterSpacing is extra space after each character in points
+	LetterSpacing float64 `json:"letterSpacing,omitempty"`
+
+	// BorderTop, BorderRight, BorderBottom and BorderLeft override Border for one side
+	BorderTop    *Border `json:"borderTop,omitempty"`
+	BorderRight  *Border `json:"borderRight,omitempty"`
+	BorderBottom *Border `json:"borderBottom,omitempty"`
+	BorderLeft   *Border `json:"borderLeft,omitempty"`
+}
+
+// Overflow defines how text that does not fit its bounds is handled
+type Overflow string
+
+const (
+	// OverflowVisible draws the remaining lines below the bounds
+	OverflowVisible Overflow = "visible"
+	// OverflowClip cuts the text off at the bounds
+	OverflowClip Overflow = "clip"
+	// OverflowEllipsis drops the remaining lines and ends the last one with "..."
+	OverflowEllipsis Overflow = "ellipsis"
+	// OverflowShrink reduces the font size until the text fits
+	OverflowShrink Overflow = "shrink"
+	// OverflowGrow extends the element height and moves the following elements down
+	OverflowGrow Overflow = "grow"
+)
+
+// valid reports whether the overflow is empty or one of the known policies
+func (o Overflow) valid() bool {
+	switch o {
+	case "", OverflowVisible, OverflowClip, OverflowEllipsis, OverflowShrink, OverflowGrow:
+		return true
+	}
+	return false
+}
+
+// FontWeight defines the weight of text
+type FontWeight string
+
+const (
+	FontWeightNormal FontWeight = "normal"
+	FontWeightBold   FontWeight = "bold"
+)
+
+// Bold reports whether the weight selects the bold variant of a font
+func (w FontWeight) Bold() bool {
+	if w == FontWeightBold || w == "bolder" {
+		return true
+	}
+	n, err := strconv.Atoi(string(w))
+	return err == nil && n >= 600
+}
+
+// UnmarshalJSON accepts weights written as numbers or strings
+func (w *FontWeight) UnmarshalJSON(data []byte) error {
+	var n float64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*w = FontWeight(strconv.FormatFloat(n, 'f', -1, 64))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("font weight must be a number or a string, got %s", data)
+	}
+	*w = FontWeight(s)
+	return nil
+}
+
+// FontStyle defines the slant of text
+type FontStyle string
+
+const (
+	FontStyleNormal FontStyle = "normal"
+	FontStyleItalic FontStyle = "italic"
+)
+
+// Italic reports whether the style selects the italic variant of a font
+func (s FontStyle) Italic() bool {
+	return s == FontStyleItalic || s == "oblique"
+}
+
+// TextDecoration defines the lines drawn with text
+type TextDecoration string
+
+const (
+	DecorationNone        TextDecoration = "none"
+	DecorationUnderline   TextDecoration = "underline"
+	DecorationLineThrough TextDecoration = "line-through"
+)
+
+// Has reports whether the decoration includes line
+func (d TextDecoration) Has(line TextDecoration) bool {
+	for _, field := range strings.Fields(string(d)) {
+		if TextDecoration(field) == line {
+			return true
+		}
+	}
+	return false
 }
 
 // Border defines border properties
@@ -73,6 +240,14 @@ type Border struct {
 	Style string  `json:"style"`
 }
 
+// Border styles
+const (
+	BorderSolid  = "solid"
+	BorderDashed = "dashed"
+	BorderDotted = "dotted"
+	BorderNone   = "none"
+)
+
 // Padding defines padding properties
 type Padding struct {
 	Top    float64 `json:"top"`
@@ -81,6 +256,291 @@ type Padding struct {
 	Left   float64 `json:"left"`
 }
 
+// BarcodeSymbology selects the encoding used by barcode elements
+type BarcodeSymbology string
+
+const (
+	SymbologyCode128     BarcodeSymbology = "code128"
+	SymbologyCode39      BarcodeSymbology = "code39"
+	SymbologyEAN13       BarcodeSymbology = "ean13"
+	SymbologyInterleaved BarcodeSymbology = "i2of5"
+	SymbologyQR          BarcodeSymbology = "qr"
+	SymbologyDataMatrix  BarcodeSymbology = "datamatrix"
+	SymbologyPDF417      BarcodeSymbology = "pdf417"
+)
+
+// BarcodeOptions configures a barcode element through its metadata
+type BarcodeOptions struct {
+	Symbology BarcodeSymbology `json:"symbology,omitempty"`
+	// ShowText prints the encoded value below the bars
+	ShowText bool `json:"showText,omitempty"`
+	// Text replaces the encoded value as the human-readable caption
+	Text string `json:"text,omitempty"`
+	// Checksum adds the optional check digit for Code39 and Interleaved 2 of 5
+	Checksum bool `json:"checksum,omitempty"`
+	// ErrorCorrection is the QR error correction level: L, M (default), Q or H
+	ErrorCorrection string `json:"errorCorrection,omitempty"`
+	// SecurityLevel is the PDF417 error correction level from 0 to 8, 2 by default
+	SecurityLevel *int `json:"securityLevel,omitempty"`
+}
+
+// ImageFit decides how an image is sized to its element, as CSS object-fit does
+type ImageFit string
+
+const (
+	// ImageFitFill stretches the image to the element bounds
+	ImageFitFill ImageFit = "fill"
+	// ImageFitContain scales the image to fit inside the bounds, keeping its aspect ratio
+	ImageFitContain ImageFit = "contain"
+	// ImageFitCover scales the image to cover the bounds, cropping what lies outside
+	ImageFitCover ImageFit = "cover"
+	// ImageFitNone draws the image at its natural size at 96 dpi, cropped to the bounds
+	ImageFitNone ImageFit = "none"
+)
+
+// ImageOptions configures an image or SVG element through its metadata
+type ImageOptions struct {
+	// Fit is "fill" by default for images and "contain" for SVG graphics,
+	// unless their preserveAspectRatio is "none"
+	Fit ImageFit `json:"fit,omitempty"`
+	// Alignment and VerticalAlignment place an image that does not fill its
+	// bounds, centered by default
+	Alignment         TextAlignment     `json:"alignment,omitempty"`
+	VerticalAlignment VerticalAlignment `json:"verticalAlignment,omitempty"`
+	// Rotation turns the fitted image clockwise in degrees about the center
+	// of the element, as a CSS rotate transform does
+	Rotation float64 `json:"rotation,omitempty"`
+	// Opacity from 0 to 1, fully opaque by default
+	Opacity *float64 `json:"opacity,omitempty"`
+}
+
+// ShapeKind selects the outline a shape element draws
+type ShapeKind string
+
+const (
+	ShapeLine     ShapeKind = "line"
+	ShapeRect     ShapeKind = "rect"
+	ShapeEllipse  ShapeKind = "ellipse"
+	ShapePolyline ShapeKind = "polyline"
+	ShapePolygon  ShapeKind = "polygon"
+	ShapePath     ShapeKind = "path"
+)
+
+// ShapeOptions configures a shape element through its metadata. The
+// style's border strokes the shape and its background fills it; shapes
+// with neither are outlined in a thin black line.
+type ShapeOptions struct {
+	Shape ShapeKind `json:"shape"`
+	// Points are the vertices of lines, polylines and polygons in
+	// millimetres from the top-left corner of the element. A line without
+	// points runs from the top-left to the bottom-right corner.
+	Points []Position `json:"points,omitempty"`
+	// Path outlines a path shape in SVG path syntax with the M, L, H, V, C,
+	// S, Q, T, A and Z commands, lower case for relative coordinates, in
+	// millimetres from the top-left corner of the element
+	Path string `json:"path,omitempty"`
+	// Radius rounds the corners of rectangles
+	Radius float64 `json:"radius,omitempty"`
+	// Dash lists alternating dash and gap lengths, replacing the border style
+	Dash []float64 `json:"dash,omitempty"`
+	// ArrowStart and ArrowEnd draw arrowheads at the ends of open shapes
+	ArrowStart bool `json:"arrowStart,omitempty"`
+	ArrowEnd   bool `json:"arrowEnd,omitempty"`
+	// ArrowSize is the length of the arrowheads, four times the line width
+	// and at least 1.5mm by default
+	ArrowSize float64 `json:"arrowSize,omitempty"`
+}
+
+// ChartKind selects how a chart element draws its series
+type ChartKind string
+
+const (
+	ChartBar        ChartKind = "bar"
+	ChartStackedBar ChartKind = "stackedBar"
+	ChartLine       ChartKind = "line"
+	ChartArea       ChartKind = "area"
+	ChartPie        ChartKind = "pie"
+	ChartDonut      ChartKind = "donut"
+)
+
+// LegendPosition places the legend of a chart
+type LegendPosition string
+
+const (
+	LegendBottom LegendPosition = "bottom"
+	LegendTop    LegendPosition = "top"
+	LegendRight  LegendPosition = "right"
+	LegendNone   LegendPosition = "none"
+)
+
+// ChartSeries is one named set of values of a chart
+type ChartSeries struct {
+	Name string `json:"name"`
+	// Key is the path of the series' value in content given as data
+	// objects, such as "totals.revenue"
+	Key string `json:"key,omitempty"`
+	// Color overrides the palette color of the series
+	Color string `json:"color,omitempty"`
+}
+
+// ChartOptions configures a chart element through its metadata. Content
+// is either a list of data objects, read through CategoryKey and the keys
+// of the series, or an object with "categories" and "series" lists whose
+// entries hold a name, values and an optional color.
+type ChartOptions struct {
+	Chart       ChartKind     `json:"chart"`
+	CategoryKey string        `json:"categoryKey,omitempty"`
+	Series      []ChartSeries `json:"series,omitempty"`
+	// Colors is the palette used in turn for series, or for the slices of
+	// pie and donut charts
+	Colors []string       `json:"colors,omitempty"`
+	Legend LegendPosition `json:"legend,omitempty"`
+	// Min and Max fix the ends of the value axis, which otherwise covers
+	// zero and all values
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// Gridlines are drawn at the value axis ticks unless set to false
+	Gridlines *bool `json:"gridlines,omitempty"`
+	// Format is a filter chain applied to axis and value labels, such as
+	// "number 0" or `currency "USD"`
+	Format string `json:"format,omitempty"`
+	// ShowValues labels bars and points with their value and pie slices
+	// with their share
+	ShowValues bool `json:"showValues,omitempty"`
+	// HoleSize is the share of the radius left open in donut charts, 0.5
+	// by default
+	HoleSize float64 `json:"holeSize,omitempty"`
+}
+
+// FormFieldType selects the kind of interactive field a form element creates
+type FormFieldType string
+
+const (
+	FormFieldText      FormFieldType = "text"
+	FormFieldCheckbox  FormFieldType = "checkbox"
+	FormFieldRadio     FormFieldType = "radio"
+	FormFieldDropdown  FormFieldType = "dropdown"
+	FormFieldSignature FormFieldType = "signature"
+)
+
+// FormFieldOptions configures a form element through its metadata. The
+// element content, after data binding, is the field's default value.
+type FormFieldOptions struct {
+	Field FormFieldType `json:"field,omitempty"`
+	// Name is the field name reported by PDF readers, the element ID by default
+	Name      string `json:"name,omitempty"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+	Required  bool   `json:"required,omitempty"`
+	Multiline bool   `json:"multiline,omitempty"`
+	MaxLength int    `json:"maxLength,omitempty"`
+	// Options are the choices of radio groups and dropdowns
+	Options []string `json:"options,omitempty"`
+	// Label is printed next to checkboxes
+	Label string `json:"label,omitempty"`
+}
+
+// TextFormat selects how the string content of a text element is read
+type TextFormat string
+
+const (
+	TextFormatPlain    TextFormat = "plain"
+	TextFormatMarkdown TextFormat = "markdown"
+)
+
+// TextOptions configures a text element through its metadata
+type TextOptions struct {
+	// Format "markdown" reads **bold**, *italic*, ~~strike~~, `code` and
+	// [links](url) from the content
+	Format TextFormat `json:"format,omitempty"`
+}
+
+// TextRun is a stretch of rich text. Text elements accept a list of runs,
+// or plain strings, as content in place of a single string.
+type TextRun struct {
+	Text      string `json:"text"`
+	Bold      bool   `json:"bold,omitempty"`
+	Italic    bool   `json:"italic,omitempty"`
+	Underline bool   `json:"underline,omitempty"`
+	Strike    bool   `json:"strike,omitempty"`
+	// Code sets the run in a monospaced font on a shaded background
+	Code bool `json:"code,omitempty"`
+	// Color overrides the element's font color
+	Color string `json:"color,omitempty"`
+	// Link is a URL opened when the run is clicked
+	Link string `json:"link,omitempty"`
+}
+
+// TableOptions configures a table element through its metadata. Cells in
+// the content are plain values or objects such as
+// {"value": "{{ item.total }}", "style": {"fontColor": "red"}, "colSpan": 2}
+// whose style overrides the row and column styles for that cell and whose
+// colSpan and rowSpan merge it with the cells to its right and below. Rows are lists of
+// cells or objects such as {"cells": [...], "style": {...}, "keepWithNext": true},
+// whose style applies to the whole row and whose keepWithNext prevents a
+// page break between it and the next row. Rows may also be objects from the
+// data, as when the content is bound with "{{ order.items }}", whose cells
+// are read with the column keys.
+type TableOptions struct {
+	// Columns style the columns in order; columns without a definition
+	// share the width left by the others
+	Columns []TableColumn `json:"columns,omitempty"`
+	// HeaderRows is the number of leading rows drawn in the header style
+	HeaderRows int `json:"headerRows,omitempty"`
+	// HeaderStyle overrides the table style in header rows, which are bold by default
+	HeaderStyle *Style `json:"headerStyle,omitempty"`
+	// Stripe is the background of every second body row
+	Stripe string `json:"stripe,omitempty"`
+
+	// KeepTogether moves a table that does not fit the rest of the page to
+	// the next one rather than breaking it, unless it is taller than a page
+	KeepTogether bool `json:"keepTogether,omitempty"`
+	// Subtotal is a row printed at the bottom of each page the table breaks
+	// from, and CarriedForward a row printed below the repeated header rows
+	// on each page it continues on. Their cells are values or the tokens
+	// {total}, the column total up to the break, and {pageTotal}, the
+	// column total on the page.
+	Subtotal       []interface{} `json:"subtotal,omitempty"`
+	CarriedForward []interface{} `json:"carriedForward,omitempty"`
+}
+
+// TableColumn configures one table column
+type TableColumn struct {
+	// Key is the path of the column's value in rows given as data objects,
+	// such as "product.name"
+	Key string `json:"key,omitempty"`
+	// Header labels the column in a header row added above the content rows
+	Header    string        `json:"header,omitempty"`
+	Width     ColumnWidth   `json:"width,omitempty"`
+	Alignment TextAlignment `json:"alignment,omitempty"`
+	// Format is a filter pipeline applied to the body cells, such as
+	// `currency "USD"` or `number 0`
+	Format string `json:"format,omitempty"`
+	// Style overrides the table style in the column's body cells
+	Style *Style `json:"style,omitempty"`
+}
+
+// ColumnWidth is a table column width: a length such as 40, a share of the
+// table width such as "25%", or "auto" to fit the widest cell
+type ColumnWidth string
+
+// ColumnWidthAuto fits a column to its content
+const ColumnWidthAuto ColumnWidth = "auto"
+
+// UnmarshalJSON accepts widths written as numbers or strings
+func (w *ColumnWidth) UnmarshalJSON(data []byte) error {
+	var n float64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*w = ColumnWidth(strconv.FormatFloat(n, 'f', -1, 64))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("column width must be a number or a string, got %s", data)
+	}
+	*w = ColumnWidth(s)
+	return nil
+}
+
 // Template defines the structure of a PDF template
 type Template struct {
 	Name     string                 `json:"name"`
@@ -88,6 +548,16 @@ type Template struct {
 	Size     Size                   `json:"size"`
 	Elements []Element              `json:"elements"`
 	Schema   map[string]interface{} `json:"schema"`
+
+	// PageSize names a preset from PageSizes, replacing Size
+	PageSize    string      `json:"pageSize,omitempty"`
+	Orientation Orientation `json:"orientation,omitempty"`
+	// Pages overrides the size or orientation of individual pages
+	Pages []PageSetup `json:"pages,omitempty"`
+
+	// Header and Footer repeat on every page, inside the page margins
+	Header *Region `json:"header,omitempty"`
+	Footer *Region `json:"footer,omitempty"`
 }
 
 // Validate ensures the template configuration is valid
@@ -95,20 +565,48 @@ func (t *Template) Validate() error {
 	if t.Name == "" {
 		return errors.NewPDFError(errors.ErrInvalidTemplate, "template name is required", nil)
 	}
-	if t.Size.Width <= 0 || t.Size.Height <= 0 {
-		return errors.NewPDFError(errors.ErrInvalidTemplate, "invalid template size", nil)
+	if err := t.validatePages(); err != nil {
+		return err
+	}
+	if err := t.Header.validate("header"); err != nil {
+		return err
+	}
+	if err := t.Footer.validate("footer"); err != nil {
+		return err
 	}
 	if len(t.Elements) == 0 {
 		return errors.NewPDFError(errors.ErrInvalidTemplate, "template must contain at least one element", nil)
 	}
+	return validateStyles(t.Elements)
+}
+
+// validateStyles checks the style enums of elements and their children
+func validateStyles(elements []Element) error {
+	for _, element := range elements {
+		if element.Style != nil && !element.Style.Overflow.valid() {
+			return errors.NewPDFError(errors.ErrInvalidTemplate, fmt.Sprintf("unknown overflow %q for element %s", element.Style.Overflow, element.ID), nil)
+		}
+		if err := validateStyles(element.Children); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// ValidateData ensures the provided data matches the template schema
+// ValidateData ensures the provided data matches the template schema.
+// All violations are reported together as ValidationErrors wrapped in an
+// ErrInvalidData PDFError.
 func (t *Template) ValidateData(data interface{}) error {
 	if t.Schema == nil {
 		return nil
 	}
-	// TODO: Implement schema validation
+
+	violations, err := validateSchema(t.Schema, data)
+	if err != nil {
+		return errors.NewPDFError(errors.ErrInvalidData, "data cannot be validated", err)
+	}
+	if len(violations) > 0 {
+		return errors.NewPDFError(errors.ErrInvalidData, "data does not match template schema", violations)
+	}
 	return nil
 }
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStyle_FontVariants(t *testing.T) {
+	tests := []struct {
+		name       string
+		weight     FontWeight
+		style      FontStyle
+		decoration TextDecoration
+		wantBold   bool
+		wantItalic bool
+		wantUnder  bool
+		wantStrike bool
+	}{
+		{name: "defaults"},
+		{name: "bold", weight: FontWeightBold, wantBold: true},
+		{name: "numeric bold", weight: "600", wantBold: true},
+		{name: "numeric normal", weight: "400"},
+		{name: "italic", style: FontStyleItalic, wantItalic: true},
+		{name: "oblique", style: "oblique", wantItalic: true},
+		{name: "underline", decoration: DecorationUnderline, wantUnder: true},
+		{name: "both lines", decoration: "line-through underline", wantUnder: true, wantStrike: true},
+		{name: "none", decoration: DecorationNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.weight.Bold(); got != tt.wantBold {
+				t.Errorf("Bold() = %v, want %v", got, tt.wantBold)
+			}
+			if got := tt.style.Italic(); got != tt.wantItalic {
+				t.Errorf("Italic() = %v, want %v", got, tt.wantItalic)
+			}
+			if got := tt.decoration.Has(DecorationUnderline); got != tt.wantUnder {
+				t.Errorf("Has(underline) = %v, want %v", got, tt.wantUnder)
+			}
+			if got := tt.decoration.Has(DecorationLineThrough); got != tt.wantStrike {
+				t.Errorf("Has(line-through) = %v, want %v", got, tt.wantStrike)
+			}
+		})
+	}
+}
+
+func TestTemplate_ValidateStyles(t *testing.T) {
+	text := func(overflow Overflow) Element {
+		return Element{ID: "note", Type: ElementTypeText, Style: &Style{Overflow: overflow}}
+	}
+
+	tests := []struct {
+		name     string
+		template Template
+		wantErr  bool
+	}{
+		{name: "known overflow", template: Template{Elements: []Element{text(OverflowEllipsis), {ID: "plain", Type: ElementTypeText}}}},
+		{name: "unknown overflow", template: Template{Elements: []Element{text("elipsis")}}, wantErr: true},
+		{name: "unknown overflow in a group", template: Template{Elements: []Element{{ID: "group", Children: []Element{text("hidden")}}}}, wantErr: true},
+		{
+			name:     "unknown overflow in a footer",
+			template: Template{Elements: []Element{text("")}, Footer: &Region{Height: 10, Elements: []Element{text("cut")}}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.template.Name = "test"
+			tt.template.PageSize = "A4"
+			err := tt.template.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFontWeight_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json     string
+		want     FontWeight
+		wantBold bool
+		wantErr  bool
+	}{
+		{json: `{"fontWeight": 700}`, want: "700", wantBold: true},
+		{json: `{"fontWeight": 400}`, want: "400"},
+		{json: `{"fontWeight": "bold"}`, want: FontWeightBold, wantBold: true},
+		{json: `{"fontWeight": "600"}`, want: "600", wantBold: true},
+		{json: `{"fontWeight": true}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			var style Style
+			err := json.Unmarshal([]byte(tt.json), &style)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if style.FontWeight != tt.want {
+				t.Errorf("FontWeight = %q, want %q", style.FontWeight, tt.want)
+			}
+			if got := style.FontWeight.Bold(); got != tt.wantBold {
+				t.Errorf("Bold() = %v, want %v", got, tt.wantBold)
+			}
+		})
+	}
+}
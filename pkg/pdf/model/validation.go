@@ -0,0 +1,352 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError describes a single schema violation
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s", path, e.Message)
+}
+
+// ValidationErrors collects every violation found while validating data
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// formatPatterns holds the checks used for the "format" keyword
+var formatPatterns = map[string]func(string) bool{
+	"date": func(s string) bool {
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	},
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	},
+	"time": func(s string) bool {
+		_, err := time.Parse("15:04:05", s)
+		return err == nil
+	},
+	"email": func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	},
+	"uuid": regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`).MatchString,
+}
+
+// schemaValidator walks data alongside a JSON Schema subset and records violations
+type schemaValidator struct {
+	errors ValidationErrors
+}
+
+// validateSchema checks data against schema, returning every violation found.
+// Supported keywords are type, required, properties, additionalProperties,
+// items, enum, minimum, maximum, exclusiveMinimum, exclusiveMaximum,
+// minLength, maxLength, minItems, maxItems, pattern and format.
+func validateSchema(schema map[string]interface{}, data interface{}) (ValidationErrors, error) {
+	normalized, err := normalizeData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &schemaValidator{}
+	v.validate(schema, normalized, "")
+	return v.errors, nil
+}
+
+// normalizeData converts arbitrary Go values into their JSON representation
+// so structs, typed maps and integer values validate the same way decoded JSON does
+func normalizeData(data interface{}) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode data: %w", err)
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to decode data: %w", err)
+	}
+	return normalized, nil
+}
+
+func (v *schemaValidator) addError(path, format string, args ...interface{}) {
+	v.errors = append(v.errors, ValidationError{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *schemaValidator) validate(schema map[string]interface{}, data interface{}, path string) {
+	if schema == nil {
+		return
+	}
+
+	if types, ok := schemaTypes(schema["type"]); ok && !matchesAnyType(data, types) {
+		v.addError(path, "expected %s, got %s", strings.Join(types, " or "), jsonType(data))
+		// Further keywords only make sense for the declared type
+		return
+	}
+
+	if enum, ok := schemaList(schema["enum"]); ok {
+		v.validateEnum(enum, data, path)
+	}
+
+	switch value := data.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, value, path)
+	case []interface{}:
+		v.validateArray(schema, value, path)
+	case string:
+		v.validateString(schema, value, path)
+	case float64:
+		v.validateNumber(schema, value, path)
+	}
+}
+
+func (v *schemaValidator) validateEnum(enum []interface{}, data interface{}, path string) {
+	for _, allowed := range enum {
+		if reflect.DeepEqual(normalizeNumber(allowed), data) {
+			return
+		}
+	}
+
+	options := make([]string, len(enum))
+	for i, allowed := range enum {
+		options[i] = fmt.Sprintf("%v", allowed)
+	}
+	v.addError(path, "value %v is not one of [%s]", data, strings.Join(options, ", "))
+}
+
+func (v *schemaValidator) validateObject(schema map[string]interface{}, obj map[string]interface{}, path string) {
+	if required, ok := schemaList(schema["required"]); ok {
+		for _, name := range required {
+			key, ok := name.(string)
+			if !ok {
+				continue
+			}
+			if _, exists := obj[key]; !exists {
+				v.addError(path, "missing required property %q", key)
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	// Iterate in a stable order so violations are reported deterministically
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := path + "/" + escapePointer(key)
+		if propSchema, ok := properties[key].(map[string]interface{}); ok {
+			v.validate(propSchema, obj[key], childPath)
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.addError(childPath, "additional property %q is not allowed", key)
+			}
+		case map[string]interface{}:
+			v.validate(additional, obj[key], childPath)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(schema map[string]interface{}, arr []interface{}, path string) {
+	if min, ok := toFloat(schema["minItems"]); ok && float64(len(arr)) < min {
+		v.addError(path, "expected at least %v items, got %d", min, len(arr))
+	}
+	if max, ok := toFloat(schema["maxItems"]); ok && float64(len(arr)) > max {
+		v.addError(path, "expected at most %v items, got %d", max, len(arr))
+	}
+
+	items, ok := schema["items"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for i, item := range arr {
+		v.validate(items, item, path+"/"+strconv.Itoa(i))
+	}
+}
+
+func (v *schemaValidator) validateString(schema map[string]interface{}, s string, path string) {
+	length := float64(len([]rune(s)))
+	if min, ok := toFloat(schema["minLength"]); ok && length < min {
+		v.addError(path, "expected at least %v characters, got %v", min, length)
+	}
+	if max, ok := toFloat(schema["maxLength"]); ok && length > max {
+		v.addError(path, "expected at most %v characters, got %v", max, length)
+	}
+
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			v.addError(path, "invalid pattern %q in schema: %v", pattern, err)
+		} else if !re.MatchString(s) {
+			v.addError(path, "value %q does not match pattern %q", s, pattern)
+		}
+	}
+
+	if format, ok := schema["format"].(string); ok {
+		// Unknown formats are annotations only, as in the JSON Schema spec
+		if check, known := formatPatterns[format]; known && !check(s) {
+			v.addError(path, "value %q is not a valid %s", s, format)
+		}
+	}
+}
+
+func (v *schemaValidator) validateNumber(schema map[string]interface{}, n float64, path string) {
+	if min, ok := toFloat(schema["minimum"]); ok && n < min {
+		v.addError(path, "value %v is less than minimum %v", n, min)
+	}
+	if max, ok := toFloat(schema["maximum"]); ok && n > max {
+		v.addError(path, "value %v is greater than maximum %v", n, max)
+	}
+	if min, ok := toFloat(schema["exclusiveMinimum"]); ok && n <= min {
+		v.addError(path, "value %v must be greater than %v", n, min)
+	}
+	if max, ok := toFloat(schema["exclusiveMaximum"]); ok && n >= max {
+		v.addError(path, "value %v must be less than %v", n, max)
+	}
+}
+
+// schemaTypes reads the "type" keyword, which may be a string or a list of strings
+func schemaTypes(value interface{}) ([]string, bool) {
+	switch t := value.(type) {
+	case string:
+		return []string{t}, true
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types, len(types) > 0
+	case []string:
+		return t, len(t) > 0
+	}
+	return nil, false
+}
+
+// schemaList reads a list keyword, which may be a typed Go slice such as
+// []string when the schema is built in code rather than decoded from JSON
+func schemaList(value interface{}) ([]interface{}, bool) {
+	if list, ok := value.([]interface{}); ok {
+		return list, true
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	list := make([]interface{}, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+	return list, true
+}
+
+func matchesAnyType(data interface{}, types []string) bool {
+	actual := jsonType(data)
+	for _, t := range types {
+		if t == actual {
+			return true
+		}
+		if t == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type name of a normalized value
+func jsonType(data interface{}) string {
+	switch v := data.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", data)
+	}
+}
+
+// toFloat converts numeric schema values, which may be Go ints when the
+// schema is built in code rather than decoded from JSON
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func normalizeNumber(value interface{}) interface{} {
+	if f, ok := toFloat(value); ok {
+		return f
+	}
+	return value
+}
+
+// escapePointer escapes a property name for use in a JSON pointer (RFC 6901)
+func escapePointer(key string) string {
+	key = strings.ReplaceAll(key, "~", "~0")
+	return strings.ReplaceAll(key, "/", "~1")
+}
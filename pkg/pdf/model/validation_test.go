@@ -0,0 +1,176 @@
+package model
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+
+	"github.com/josephmojoo/pdfgen/pkg/pdf/errors"
+)
+
+const invoiceSchema = `{
+	"type": "object",
+	"required": ["customer", "order"],
+	"properties": {
+		"customer": {
+			"type": "object",
+			"required": ["name", "email"],
+			"properties": {
+				"name":  {"type": "string", "minLength": 1},
+				"email": {"type": "string", "format": "email"}
+			}
+		},
+		"order": {
+			"type": "object",
+			"properties": {
+				"id":     {"type": "string", "pattern": "^ORD-[0-9]+$"},
+				"date":   {"type": "string", "format": "date"},
+				"status": {"enum": ["Pending", "Completed", "Refunded"]},
+				"items": {
+					"type": "array",
+					"minItems": 1,
+					"items": {
+						"type": "object",
+						"required": ["name", "quantity"],
+						"properties": {
+							"name":     {"type": "string"},
+							"quantity": {"type": "integer", "minimum": 1},
+							"price":    {"type": "number", "exclusiveMinimum": 0}
+						}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestTemplate_ValidateData(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(invoiceSchema), &schema); err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	template := &Template{Name: "invoice", Schema: schema}
+
+	tests := []struct {
+		name      string
+		data      interface{}
+		wantPaths []string
+	}{
+		{
+			name: "valid data",
+			data: map[string]interface{}{
+				"customer": map[string]interface{}{"name": "John Doe", "email": "john@example.com"},
+				"order": map[string]interface{}{
+					"id":     "ORD-12345",
+					"date":   "2024-03-15",
+					"status": "Completed",
+					"items": []interface{}{
+						map[string]interface{}{"name": "Product A", "quantity": 2, "price": 29.99},
+					},
+				},
+			},
+		},
+		{
+			name:      "missing required properties",
+			data:      map[string]interface{}{"customer": map[string]interface{}{"name": "John Doe"}},
+			wantPaths: []string{"", "/customer"},
+		},
+		{
+			name: "every violation is reported",
+			data: map[string]interface{}{
+				"customer": map[string]interface{}{"name": "", "email": "not-an-email"},
+				"order": map[string]interface{}{
+					"id":     "12345",
+					"date":   "15/03/2024",
+					"status": "Lost",
+					"items": []interface{}{
+						map[string]interface{}{"name": "Product A", "quantity": 0, "price": 0},
+						map[string]interface{}{"name": 42, "quantity": 1.5},
+					},
+				},
+			},
+			wantPaths: []string{
+				"/customer/email",
+				"/customer/name",
+				"/order/date",
+				"/order/id",
+				"/order/items/0/price",
+				"/order/items/0/quantity",
+				"/order/items/1/name",
+				"/order/items/1/quantity",
+				"/order/status",
+			},
+		},
+		{
+			name:      "wrong root type",
+			data:      []interface{}{"customer"},
+			wantPaths: []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := template.ValidateData(tt.data)
+			if len(tt.wantPaths) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateData() error = %v, want nil", err)
+				}
+				return
+			}
+
+			if !stderrors.Is(err, &errors.PDFError{Code: errors.ErrInvalidData}) {
+				t.Fatalf("ValidateData() error = %v, want ErrInvalidData", err)
+			}
+
+			var violations ValidationErrors
+			if !stderrors.As(err, &violations) {
+				t.Fatalf("ValidateData() error = %v, want ValidationErrors cause", err)
+			}
+			if len(violations) != len(tt.wantPaths) {
+				t.Fatalf("ValidateData() returned %d violations (%v), want %d", len(violations), violations, len(tt.wantPaths))
+			}
+			for i, violation := range violations {
+				if violation.Path != tt.wantPaths[i] {
+					t.Errorf("violation %d path = %q, want %q", i, violation.Path, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTemplate_ValidateDataWithoutSchema(t *testing.T) {
+	template := &Template{Name: "free-form"}
+	if err := template.ValidateData(map[string]interface{}{"anything": true}); err != nil {
+		t.Errorf("ValidateData() error = %v, want nil", err)
+	}
+}
+
+func TestTemplate_ValidateDataWithGoSchema(t *testing.T) {
+	template := &Template{Name: "built-in-code", Schema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"customer"},
+		"properties": map[string]interface{}{
+			"status":   map[string]interface{}{"enum": []string{"Pending", "Completed"}},
+			"priority": map[string]interface{}{"enum": []int{1, 2, 3}},
+		},
+	}}
+
+	err := template.ValidateData(map[string]interface{}{"status": "Lost", "priority": 4})
+	var violations ValidationErrors
+	if !stderrors.As(err, &violations) {
+		t.Fatalf("ValidateData() error = %v, want ValidationErrors cause", err)
+	}
+	wantPaths := []string{"", "/priority", "/status"}
+	if len(violations) != len(wantPaths) {
+		t.Fatalf("ValidateData() returned %d violations (%v), want %d", len(violations), violations, len(wantPaths))
+	}
+	for i, violation := range violations {
+		if violation.Path != wantPaths[i] {
+			t.Errorf("violation %d path = %q, want %q", i, violation.Path, wantPaths[i])
+		}
+	}
+
+	if err := template.ValidateData(map[string]interface{}{"customer": "Acme", "status": "Pending", "priority": 2}); err != nil {
+		t.Errorf("ValidateData() error = %v, want nil", err)
+	}
+}